@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"flag"
+	"fmt"
+	"net/mail"
+	"os"
+	"strings"
+
+	"richisntreal-backend/cmd/config"
+	"richisntreal-backend/internal/core/services"
+	"richisntreal-backend/internal/infrastructure/mysql"
+)
+
+// createAdmin creates an administrator account. The password is read from
+// stdin with -password-stdin, so it stays out of the shell history;
+// otherwise a random one is generated and printed once.
+func createAdmin(cfg config.Cfg, args []string) error {
+	fs := flag.NewFlagSet("create-admin", flag.ContinueOnError)
+	email := fs.String("email", "", "email address to log in with (required)")
+	username := fs.String("username", "", "display name; defaults to the part of the email before @")
+	passwordStdin := fs.Bool("password-stdin", false, "read the password from the first line of stdin")
+	if err := fs.Parse(args); err != nil {
+		return ignoreHelp(err)
+	}
+
+	// 1) Check the input before touching the database
+	addr, err := mail.ParseAddress(*email)
+	if err != nil || addr.Address != *email {
+		return services.ErrInvalidEmail
+	}
+	if *username == "" {
+		*username = (*email)[:strings.LastIndex(*email, "@")]
+	}
+	var password string
+	generated := !*passwordStdin
+	if *passwordStdin {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return fmt.Errorf("reading password: %w", err)
+		}
+		password = strings.TrimRight(line, "\r\n")
+	} else {
+		b := make([]byte, 18)
+		if _, err := rand.Read(b); err != nil {
+			return err
+		}
+		password = base64.RawURLEncoding.EncodeToString(b)
+	}
+
+	// 2) Connect; the schema has to be in place already
+	mysqlClient, err := mysql.NewMySQL(cfg.MySQL)
+	if err != nil {
+		return fmt.Errorf("failed to connect to MySQL: %w", err)
+	}
+	defer mysqlClient.DB.Close()
+	// only account creation is used, which needs neither sessions nor 2FA
+	userSvc := services.NewUserService(mysql.NewUserRepository(mysqlClient.DB), nil, nil, false)
+
+	// 3) Create
+	u, err := userSvc.CreateAdmin(context.Background(), *username, *email, password)
+	if errors.Is(err, services.ErrUserExists) {
+		return fmt.Errorf("%s already has an account", *email)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Printf("admin %s created with id %d\n", u.Email, u.ID)
+	if generated {
+		fmt.Printf("password: %s\n", password)
+	}
+	return nil
+}
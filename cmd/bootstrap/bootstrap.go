@@ -1,103 +1,291 @@
 package bootstrap
 
 import (
-	"errors"
+	"context"
 	"fmt"
 	"github.com/go-chi/cors"
-	"log"
+	"log/slog"
+	"net/http"
+	"os"
+	"richisntreal-backend/internal/api/apierror"
 	"richisntreal-backend/internal/api/auth"
+	"richisntreal-backend/internal/api/middleware"
 	"richisntreal-backend/internal/api/routes"
 
 	"github.com/go-chi/chi/v5"
-	"github.com/golang-migrate/migrate/v4"
-	_ "github.com/golang-migrate/migrate/v4/database/mysql"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
+	stripe "github.com/stripe/stripe-go/v74"
 
 	"richisntreal-backend/cmd/config"
 	"richisntreal-backend/internal/api/handlers"
 	"richisntreal-backend/internal/core/services"
+	httpclient "richisntreal-backend/internal/infrastructure/http"
+	"richisntreal-backend/internal/infrastructure/mail"
+	"richisntreal-backend/internal/infrastructure/metrics"
 	mysql "richisntreal-backend/internal/infrastructure/mysql"
+	"richisntreal-backend/internal/infrastructure/oidc"
+	"richisntreal-backend/internal/infrastructure/pdf"
+	"richisntreal-backend/internal/infrastructure/ratelimit"
 )
 
-func NewRouter() *chi.Mux {
-	// 1) Load config
-	if err := config.Load(); err != nil {
-		log.Fatalf("failed to load config: %v", err)
+// NewRouter wires the application against cfg and returns its router,
+// plus a function that releases what it holds, such as the database
+// connections, to call once the server has stopped. The router reports
+// not ready as soon as shutdown is done.
+func NewRouter(shutdown context.Context, cfg config.Cfg) (*chi.Mux, func() error, error) {
+	// 1) Run migrations, when asked to; readiness reports a schema behind
+	// this build either way
+	schemaVersion, err := mysql.LatestMigration()
+	if err != nil {
+		return nil, nil, err
+	}
+	if cfg.MySQL.AutoMigrate {
+		if err := runMigrations(cfg.MySQL); err != nil {
+			return nil, nil, err
+		}
 	}
-	cfg := config.Get()
-
-	// 2) Run migrations
-	runMigrations(cfg.MySQL)
 
-	// 3) Init MySQL client
+	// 2) Init MySQL client
 	mysqlClient, err := mysql.NewMySQL(cfg.MySQL)
 	if err != nil {
-		log.Fatalf("failed to connect to MySQL: %v", err)
+		return nil, nil, fmt.Errorf("failed to connect to MySQL: %w", err)
+	}
+	r, err := newRouter(shutdown, cfg, mysqlClient, schemaVersion)
+	if err != nil {
+		mysqlClient.DB.Close()
+		return nil, nil, err
+	}
+	return r, mysqlClient.DB.Close, nil
+}
+
+func newRouter(shutdown context.Context, cfg config.Cfg, mysqlClient *mysql.MySQL, schemaVersion uint) (*chi.Mux, error) {
+
+	// instrumentation is cheap enough to collect even when nobody scrapes it
+	appMetrics := metrics.New(mysqlClient.DB.DB)
+
+	// 3) Load JWT keys
+	keys, err := loadKeySet(cfg.JWT)
+	if err != nil {
+		return nil, err
 	}
 
 	// 4) Wire services & handlers
+	sessionRepo := mysql.NewSessionRepository(mysqlClient.DB)
+	sessionSvc := services.NewSessionService(sessionRepo, keys, cfg.JWT.AccessTTL, cfg.JWT.RefreshTTL)
+	sessionHandler := handlers.NewSessionHandler(sessionSvc)
+
 	userRepo := mysql.NewUserRepository(mysqlClient.DB)
-	userSvc := services.NewUserService(userRepo, cfg.JWT.Secret)
-	userHandler := handlers.NewUserHandler(userSvc)
+	userTokenRepo := mysql.NewUserTokenRepository(mysqlClient.DB)
+
+	twoFactorRepo := mysql.NewTwoFactorRepository(mysqlClient.DB)
+	twoFactorSvc := services.NewTwoFactorService(twoFactorRepo, userRepo, userTokenRepo, cfg.App.Name)
+	twoFactorHandler := handlers.NewTwoFactorHandler(twoFactorSvc)
+
+	userSvc := services.NewUserService(userRepo, sessionSvc, twoFactorSvc, cfg.Auth.RequireVerifiedEmail)
+
+	mailer, err := newMailer(cfg.Mail)
+	if err != nil {
+		return nil, err
+	}
+	accountSvc := services.NewAccountService(userRepo, userTokenRepo, mailer, sessionSvc, cfg.App.PublicURL)
+	accountHandler := handlers.NewAccountHandler(accountSvc)
+
+	ipLimiter := ratelimit.NewMemoryLimiter(cfg.RateLimit.IPRequests, cfg.RateLimit.IPPeriod)
+	accountLimiter := ratelimit.NewMemoryLimiter(cfg.RateLimit.AccountRequests, cfg.RateLimit.AccountPeriod)
+	loginAttemptRepo := mysql.NewLoginAttemptRepository(mysqlClient.DB)
+	loginGuard := services.NewLoginGuard(loginAttemptRepo, cfg.Auth.LockoutThreshold, cfg.Auth.LockoutWindow, cfg.Auth.LockoutDuration)
+	userHandler := handlers.NewUserHandler(userSvc, accountSvc, loginGuard, accountLimiter)
+
+	privacyRepo := mysql.NewPrivacyRepository(mysqlClient.DB)
+	privacySvc := services.NewPrivacyService(privacyRepo, userRepo, sessionSvc)
+	privacyHandler := handlers.NewPrivacyHandler(privacySvc)
+
+	identityRepo := mysql.NewIdentityRepository(mysqlClient.DB)
+	providers, err := loadIdentityProviders(cfg.OIDC)
+	if err != nil {
+		return nil, err
+	}
+	oidcSvc := services.NewOIDCService(providers, identityRepo, userRepo, userSvc)
+	oidcHandler := handlers.NewOIDCHandler(oidcSvc)
 
 	cartRepo := mysql.NewCartRepository(mysqlClient.DB)
-	cartService := services.NewCartService(cartRepo)
+	cartService := services.NewCartService(cartRepo, appMetrics)
 	cartHandler := handlers.NewCartHandler(cartService)
 
 	prodRepo := mysql.NewProductRepository(mysqlClient.DB)
 	prodService := services.NewProductService(prodRepo)
 	prodHandler := handlers.NewProductHandler(prodService)
 
+	payRepo := mysql.NewPaymentRepository(mysqlClient.DB)
+	// send Stripe calls through the shared client so they show up in traces
+	stripe.SetBackend(stripe.APIBackend, stripe.GetBackendWithConfig(stripe.APIBackend, &stripe.BackendConfig{
+		HTTPClient: httpclient.NewHTTPClient(),
+	}))
+	paySvc := services.NewPaymentService(payRepo, cfg.Stripe.SecretKey, appMetrics)
+
+	invoiceRepo := mysql.NewInvoiceRepository(mysqlClient.DB)
+
 	orderRepo := mysql.NewOrderRepository(mysqlClient.DB)
-	orderService := services.NewOrderService(orderRepo, cartRepo)
-	orderHandler := handlers.NewOrderHandler(orderService)
+	orderService := services.NewOrderService(orderRepo, cartRepo, prodRepo, invoiceRepo, appMetrics)
+	orderHandler := handlers.NewOrderHandler(orderService, paySvc)
 
-	payRepo := mysql.NewPaymentRepository(mysqlClient.DB)
-	paySvc := services.NewPaymentService(payRepo, cfg.Stripe.SecretKey)
 	payHandler := handlers.NewPaymentHandler(paySvc, orderService)
+	adminOrderHandler := handlers.NewAdminOrderHandler(orderService, paySvc)
+
+	returnRepo := mysql.NewReturnRepository(mysqlClient.DB)
+	returnService := services.NewReturnService(returnRepo, orderRepo, prodRepo, paySvc)
+	returnHandler := handlers.NewReturnHandler(returnService, orderService)
+
+	invoiceService := services.NewInvoiceService(invoiceRepo, orderRepo, prodRepo, payRepo, pdf.NewInvoiceRenderer(cfg.App.Name))
+	invoiceHandler := handlers.NewInvoiceHandler(invoiceService, orderService)
 
-	jwtAuth := auth.NewJWTAuthenticator(cfg.JWT.Secret)
+	jwksHandler := handlers.NewJWKSHandler(keys)
+
+	adminAuditRepo := mysql.NewAdminAuditRepository(mysqlClient.DB)
+	adminUserSvc := services.NewAdminUserService(userRepo, adminAuditRepo, sessionSvc, cfg.Auth.ImpersonationTTL)
+	adminUserHandler := handlers.NewAdminUserHandler(adminUserSvc, orderService, cartService)
+
+	// disabled accounts are turned away, and everything done while
+	// impersonating a user is audit-logged
+	jwtAuth := auth.WithImpersonationAudit(
+		auth.WithAccountCheck(auth.NewJWTAuthenticator(keys, sessionSvc), userSvc),
+		adminUserSvc,
+	)
+
+	apiKeyRepo := mysql.NewAPIKeyRepository(mysqlClient.DB)
+	apiKeySvc := services.NewAPIKeyService(apiKeyRepo)
+	apiKeyHandler := handlers.NewAPIKeyHandler(apiKeySvc)
+
+	// integration endpoints also take API keys; everything else stays session-only
+	apiAuth := auth.NewCompositeAuthenticator(jwtAuth, auth.WithAccountCheck(auth.NewAPIKeyAuthenticator(apiKeySvc), userSvc))
+
+	healthHandler := handlers.NewHealthHandler(shutdown, mysqlClient, mysql.NewMigrationCheck(mysqlClient.DB, schemaVersion))
+
+	docsHandler, err := handlers.NewDocsHandler(cfg.App.ImageTag)
+	if err != nil {
+		return nil, fmt.Errorf("building the OpenAPI document: %w", err)
+	}
 
 	// 5) Mount routes
 	r := chi.NewRouter()
 
+	// the server span wraps everything, so its duration is what the client saw;
+	// request IDs come next so every log line below, access log included, carries one
+	r.Use(middleware.Tracing)
+	r.Use(middleware.RequestID, middleware.AccessLog, middleware.Metrics(appMetrics))
+	r.Use(middleware.Timeout(cfg.MySQL.RequestTimeout))
 	r.Use(cors.Handler(cors.Options{
 		// <-- in dev you’ll want to allow your front‑end origin
 		AllowedOrigins:   []string{"http://localhost:3000"},
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type"},
-		ExposedHeaders:   []string{"Link"},
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-API-Key", middleware.RequestIDHeader, "traceparent", "tracestate"},
+		ExposedHeaders:   []string{"Link", middleware.RequestIDHeader},
 		AllowCredentials: true, // if you ever use cookies or credentialed requests
 		MaxAge:           300,  // how long browser can cache the preflight response
 	}))
 
-	routes.RegisterUserRoutes(r, userHandler, jwtAuth)
-	routes.RegisterProductRoutes(r, prodHandler, jwtAuth)
+	// unknown routes answer with the same problem documents as everything else
+	r.NotFound(func(w http.ResponseWriter, r *http.Request) {
+		apierror.Write(w, r, apierror.ErrNotFound)
+	})
+	r.MethodNotAllowed(func(w http.ResponseWriter, r *http.Request) {
+		apierror.Write(w, r, apierror.ErrMethodNotAllowed)
+	})
+
+	routes.RegisterHealthRoutes(r, healthHandler)
+	routes.RegisterDocsRoutes(r, docsHandler)
+	if cfg.Metrics.Enabled {
+		routes.RegisterMetricsRoutes(r, appMetrics.Handler())
+	}
+	routes.RegisterUserRoutes(r, userHandler, jwtAuth, ipLimiter)
+	routes.RegisterSessionRoutes(r, sessionHandler, jwtAuth)
+	routes.RegisterAccountRoutes(r, accountHandler, jwtAuth, ipLimiter)
+	routes.RegisterTwoFactorRoutes(r, twoFactorHandler, jwtAuth)
+	routes.RegisterAPIKeyRoutes(r, apiKeyHandler, jwtAuth)
+	routes.RegisterOIDCRoutes(r, oidcHandler, ipLimiter)
+	routes.RegisterJWKSRoutes(r, jwksHandler)
+	routes.RegisterProductRoutes(r, prodHandler, apiAuth)
 	routes.RegisterCartRoutes(r, cartHandler, jwtAuth)
-	routes.RegisterOrderRoutes(r, orderHandler, jwtAuth)
+	routes.RegisterOrderRoutes(r, orderHandler, apiAuth)
 	routes.RegisterPaymentRoutes(r, payHandler, jwtAuth)
-	return r
+	routes.RegisterReturnRoutes(r, returnHandler, apiAuth, userSvc)
+	routes.RegisterInvoiceRoutes(r, invoiceHandler, apiAuth, userSvc)
+	routes.RegisterAdminOrderRoutes(r, adminOrderHandler, apiAuth, userSvc)
+	routes.RegisterPrivacyRoutes(r, privacyHandler, jwtAuth, userSvc)
+	routes.RegisterAdminUserRoutes(r, adminUserHandler, jwtAuth, userSvc)
+	return r, nil
 }
 
-// runMigrations applies all “.up.sql” scripts in migrations/ against your DB.
-func runMigrations(mysqlCfg config.MySQL) {
-	// source://directory and database://dsn
-	sourceURL := "file://internal/infrastructure/mysql/migrations"
-	dbURL := fmt.Sprintf(
-		"mysql://%s:%s@tcp(%s:%s)/%s?multiStatements=true",
-		mysqlCfg.Username,
-		mysqlCfg.Password,
-		mysqlCfg.Host,
-		mysqlCfg.Port,
-		mysqlCfg.Database,
-	)
+// loadKeySet reads the JWT signing and verification keys, falling back to a
+// throwaway key when none are configured.
+func loadKeySet(jwtCfg config.JWT) (*auth.KeySet, error) {
+	if jwtCfg.SigningKeyFile == "" {
+		slog.Warn("jwt: no signing key configured, using an ephemeral key; tokens won't survive a restart")
+		keys, err := auth.NewEphemeralKeySet()
+		if err != nil {
+			return nil, fmt.Errorf("jwt: failed to generate ephemeral key: %w", err)
+		}
+		return keys, nil
+	}
+	keys, err := auth.LoadKeySet(jwtCfg.SigningKeyID, jwtCfg.SigningKeyFile, jwtCfg.VerificationKeysDir)
+	if err != nil {
+		return nil, fmt.Errorf("jwt: failed to load keys: %w", err)
+	}
+	return keys, nil
+}
+
+// loadIdentityProviders reads the OIDC provider list, if one is configured.
+func loadIdentityProviders(oidcCfg config.OIDC) ([]services.IdentityProvider, error) {
+	if oidcCfg.ProvidersFile == "" {
+		return nil, nil
+	}
+	f, err := os.Open(oidcCfg.ProvidersFile)
+	if err != nil {
+		return nil, fmt.Errorf("oidc: failed to open providers file: %w", err)
+	}
+	defer f.Close()
+	cfgs, err := oidc.LoadConfigs(f)
+	if err != nil {
+		return nil, fmt.Errorf("oidc: %w", err)
+	}
+	providers := make([]services.IdentityProvider, 0, len(cfgs))
+	for _, c := range cfgs {
+		p, err := oidc.NewProvider(c)
+		if err != nil {
+			return nil, fmt.Errorf("oidc: provider %q: %w", c.Name, err)
+		}
+		providers = append(providers, p)
+	}
+	return providers, nil
+}
 
-	m, err := migrate.New(sourceURL, dbURL)
+// newMailer picks the mail transport named by the config.
+func newMailer(mailCfg config.Mail) (services.Mailer, error) {
+	switch mailCfg.Driver {
+	case "smtp":
+		return mail.NewSMTPMailer(mailCfg.SMTPHost, mailCfg.SMTPPort, mailCfg.SMTPUsername, mailCfg.SMTPPassword, mailCfg.From), nil
+	case "memory":
+		return mail.NewMemoryMailer(), nil
+	case "log", "":
+		return mail.NewLogMailer(), nil
+	default:
+		return nil, fmt.Errorf("mail: unknown driver %q", mailCfg.Driver)
+	}
+}
+
+// runMigrations applies all pending migrations embedded in the binary.
+func runMigrations(mysqlCfg config.MySQL) error {
+	m, err := mysql.NewMigrator(mysqlCfg)
 	if err != nil {
-		log.Fatalf("migrations: failed to initialize: %v", err)
+		return err
+	}
+	defer m.Close()
+	if err := m.Up(); err != nil {
+		return err
 	}
-	if err := m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
-		log.Fatalf("migrations: failed to run up: %v", err)
+	st, err := m.Status()
+	if err != nil {
+		return err
 	}
-	log.Println("migrations: applied all available migrations")
+	slog.Info("migrations: applied all available migrations", "version", st.Version)
+	return nil
 }
@@ -0,0 +1,47 @@
+package bootstrap
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/jmoiron/sqlx"
+
+	"richisntreal-backend/cmd/config"
+	"richisntreal-backend/internal/api/handlers"
+	mysql "richisntreal-backend/internal/infrastructure/mysql"
+)
+
+// TestEveryRouteIsDocumented builds the router the server runs and fails
+// for any route the OpenAPI document leaves out.
+func TestEveryRouteIsDocumented(t *testing.T) {
+	// wiring never queries the database, so it need not exist
+	db, err := sql.Open("mysql", "user:pass@tcp(127.0.0.1:1)/none")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	cfg := config.Cfg{
+		App:     config.AppConfig{Name: "richisntreal", ImageTag: "test"},
+		Mail:    config.Mail{Driver: "log"},
+		Metrics: config.Metrics{Enabled: true},
+	}
+	r, err := newRouter(context.Background(), cfg, &mysql.MySQL{DB: sqlx.NewDb(db, "mysql")}, 1)
+	if err != nil {
+		t.Fatalf("newRouter: %v", err)
+	}
+
+	docs, err := handlers.NewDocsHandler(cfg.App.ImageTag)
+	if err != nil {
+		t.Fatal(err)
+	}
+	missing, err := docs.Document().Undocumented(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, route := range missing {
+		t.Errorf("%s is missing from the OpenAPI document", route)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -11,21 +12,88 @@ import (
 var config Cfg
 
 type Cfg struct {
-	App    AppConfig `mapstructure:"app"`
-	MySQL  MySQL     `mapstructure:"mysql"`
-	Stripe Stripe    `mapstructure:"stripe"`
-	JWT    JWT       `mapstructure:"jwt"`
+	App       AppConfig `mapstructure:"app"`
+	Server    Server    `mapstructure:"server"`
+	MySQL     MySQL     `mapstructure:"mysql"`
+	Stripe    Stripe    `mapstructure:"stripe"`
+	JWT       JWT       `mapstructure:"jwt"`
+	Mail      Mail      `mapstructure:"mail"`
+	Auth      Auth      `mapstructure:"auth"`
+	RateLimit RateLimit `mapstructure:"rate_limit"`
+	OIDC      OIDC      `mapstructure:"oidc"`
+	Log       Log       `mapstructure:"log"`
+	Metrics   Metrics   `mapstructure:"metrics"`
+	Tracing   Tracing   `mapstructure:"tracing"`
 }
 
 type AppConfig struct {
-	Image    string `mapstructure:"image"`
-	ImageTag string `mapstructure:"image_tag"`
-	Name     string `mapstructure:"name"`
-	Port     string `mapstructure:"port"`
+	Image     string `mapstructure:"image"`
+	ImageTag  string `mapstructure:"image_tag"`
+	Name      string `mapstructure:"name"`
+	Port      string `mapstructure:"port"`
+	PublicURL string `mapstructure:"public_url"`
+}
+
+// Server timeouts; see net/http.Server for what each one covers.
+type Server struct {
+	ReadTimeout     time.Duration `mapstructure:"read_timeout"`
+	WriteTimeout    time.Duration `mapstructure:"write_timeout"`
+	IdleTimeout     time.Duration `mapstructure:"idle_timeout"`
+	ShutdownDelay   time.Duration `mapstructure:"shutdown_delay"`   // keep serving, but not ready, before draining
+	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout"` // how long in-flight requests get to finish
 }
 
 type JWT struct {
-	Secret string `mapstructure:"secret"`
+	SigningKeyID        string        `mapstructure:"signing_key_id"`
+	SigningKeyFile      string        `mapstructure:"signing_key_file"`
+	VerificationKeysDir string        `mapstructure:"verification_keys_dir"`
+	AccessTTL           time.Duration `mapstructure:"access_ttl"`
+	RefreshTTL          time.Duration `mapstructure:"refresh_ttl"`
+}
+
+type Mail struct {
+	Driver       string `mapstructure:"driver"` // smtp, log or memory
+	From         string `mapstructure:"from"`
+	SMTPHost     string `mapstructure:"smtp_host"`
+	SMTPPort     string `mapstructure:"smtp_port"`
+	SMTPUsername string `mapstructure:"smtp_username"`
+	SMTPPassword string `mapstructure:"smtp_password"`
+}
+
+type Auth struct {
+	RequireVerifiedEmail bool          `mapstructure:"require_verified_email"`
+	LockoutThreshold     int           `mapstructure:"lockout_threshold"` // 0 disables lockout
+	LockoutWindow        time.Duration `mapstructure:"lockout_window"`
+	LockoutDuration      time.Duration `mapstructure:"lockout_duration"`
+	ImpersonationTTL     time.Duration `mapstructure:"impersonation_ttl"` // lifetime of support impersonation tokens
+}
+
+// RateLimit budgets are token buckets: bursts up to the request count,
+// refilled at that count per period.
+type RateLimit struct {
+	IPRequests      int           `mapstructure:"ip_requests"`
+	IPPeriod        time.Duration `mapstructure:"ip_period"`
+	AccountRequests int           `mapstructure:"account_requests"`
+	AccountPeriod   time.Duration `mapstructure:"account_period"`
+}
+
+type OIDC struct {
+	ProvidersFile string `mapstructure:"providers_file"` // JSON list of providers; empty disables social login
+}
+
+type Log struct {
+	Level  string `mapstructure:"level"`  // debug, info, warn or error
+	Format string `mapstructure:"format"` // json or text
+}
+
+type Metrics struct {
+	Enabled bool `mapstructure:"enabled"` // serve Prometheus metrics on /metrics
+}
+
+type Tracing struct {
+	Exporter     string  `mapstructure:"exporter"`      // none, stdout or otlp
+	OTLPEndpoint string  `mapstructure:"otlp_endpoint"` // OTLP/HTTP URL; empty uses the OTEL_EXPORTER_OTLP_* env vars
+	SampleRatio  float64 `mapstructure:"sample_ratio"`  // share of new traces recorded, 0 to 1
 }
 
 type Stripe struct {
@@ -34,11 +102,13 @@ type Stripe struct {
 }
 
 type MySQL struct {
-	Host     string `mapstructure:"host"`
-	Port     string `mapstructure:"port"`
-	Username string `mapstructure:"username"`
-	Password string `mapstructure:"password"`
-	Database string `mapstructure:"database"`
+	Host           string        `mapstructure:"host"`
+	Port           string        `mapstructure:"port"`
+	Username       string        `mapstructure:"username"`
+	Password       string        `mapstructure:"password"`
+	Database       string        `mapstructure:"database"`
+	RequestTimeout time.Duration `mapstructure:"request_timeout"` // deadline for a request's queries; 0 disables
+	AutoMigrate    bool          `mapstructure:"auto_migrate"`    // apply pending migrations when serving starts
 }
 
 func Load() error {
@@ -49,7 +119,39 @@ func Load() error {
 	v.SetDefault("app.image_tag", "latest")
 	v.SetDefault("app.name", "richisntreal")
 	v.SetDefault("app.port", "8080")
-	v.SetDefault("jwt.secret", "changeme")
+	v.SetDefault("app.public_url", "http://localhost:3000")
+	v.SetDefault("server.read_timeout", "15s")
+	v.SetDefault("server.write_timeout", "30s")
+	v.SetDefault("server.idle_timeout", "60s")
+	v.SetDefault("server.shutdown_delay", "0s")
+	v.SetDefault("server.shutdown_timeout", "30s")
+	v.SetDefault("jwt.signing_key_id", "")
+	v.SetDefault("jwt.signing_key_file", "")
+	v.SetDefault("jwt.verification_keys_dir", "")
+	v.SetDefault("jwt.access_ttl", "15m")
+	v.SetDefault("jwt.refresh_ttl", "720h")
+	v.SetDefault("mail.driver", "log")
+	v.SetDefault("mail.from", "no-reply@localhost")
+	v.SetDefault("mail.smtp_host", "localhost")
+	v.SetDefault("mail.smtp_port", "25")
+	v.SetDefault("mail.smtp_username", "")
+	v.SetDefault("mail.smtp_password", "")
+	v.SetDefault("auth.require_verified_email", false)
+	v.SetDefault("auth.lockout_threshold", 10)
+	v.SetDefault("auth.lockout_window", "15m")
+	v.SetDefault("auth.lockout_duration", "15m")
+	v.SetDefault("auth.impersonation_ttl", "30m")
+	v.SetDefault("rate_limit.ip_requests", 20)
+	v.SetDefault("rate_limit.ip_period", "1m")
+	v.SetDefault("rate_limit.account_requests", 5)
+	v.SetDefault("rate_limit.account_period", "1m")
+	v.SetDefault("oidc.providers_file", "")
+	v.SetDefault("log.level", "info")
+	v.SetDefault("log.format", "json")
+	v.SetDefault("metrics.enabled", true)
+	v.SetDefault("tracing.exporter", "none")
+	v.SetDefault("tracing.otlp_endpoint", "")
+	v.SetDefault("tracing.sample_ratio", 1.0)
 	v.SetDefault("stripe.secret_key", "")
 	v.SetDefault("stripe.public_key", "")
 	v.SetDefault("mysql.host", "localhost")
@@ -57,6 +159,8 @@ func Load() error {
 	v.SetDefault("mysql.username", "root")
 	v.SetDefault("mysql.password", "")
 	v.SetDefault("mysql.database", "richisntreal")
+	v.SetDefault("mysql.request_timeout", "10s")
+	v.SetDefault("mysql.auto_migrate", false)
 
 	// Env‑vars
 	v.SetEnvPrefix("RICHISNTREAL")
@@ -1,14 +1,32 @@
 package main
 
 import (
+	"fmt"
 	"log"
-	"net/http"
+	"log/slog"
+	"os"
 
 	"github.com/joho/godotenv" // ← new
-	"richisntreal-backend/cmd/bootstrap"
 	"richisntreal-backend/cmd/config"
+	"richisntreal-backend/internal/infrastructure/logging"
 )
 
+const usage = `Usage: richisntreal [command] [arguments]
+
+Commands:
+  serve            run the HTTP server (the default)
+  migrate up       apply all pending migrations
+  migrate down     revert the most recent migration
+  migrate to N     migrate up or down to version N
+  migrate status   show the applied version and what is pending
+  migrate force N  mark version N as applied after repairing a failed migration
+  seed             load a demo catalog and demo customers
+  create-admin     create an administrator account
+  retry-refunds    retry refunds that failed when orders were cancelled
+
+Run "richisntreal <command> -h" for a command's flags.
+`
+
 func main() {
 	// 1) Load .env if present (no harm if it’s missing)
 	if err := godotenv.Load(); err != nil {
@@ -19,10 +37,40 @@ func main() {
 	if err := config.Load(); err != nil {
 		log.Fatalf("failed to load config: %v", err)
 	}
+	cfg := config.Get()
+
+	// 3) Structured logging for everything that follows
+	logger, err := logging.New(os.Stdout, cfg.Log.Format, cfg.Log.Level)
+	if err != nil {
+		log.Fatalf("failed to set up logging: %v", err)
+	}
+	slog.SetDefault(logger)
 
-	// 3) Bootstrap and start
-	router := bootstrap.NewRouter()
-	port := config.Get().App.Port
-	log.Printf("Listening on port %s", port)
-	log.Fatal(http.ListenAndServe(":"+port, router))
+	// 4) Run the command; without one, serve as the binary always has
+	cmd, args := "serve", os.Args[1:]
+	if len(args) > 0 {
+		cmd, args = args[0], args[1:]
+	}
+	switch cmd {
+	case "serve":
+		err = serve(cfg, args)
+	case "migrate":
+		err = migrateCommand(cfg, args)
+	case "seed":
+		err = seed(cfg, args)
+	case "create-admin":
+		err = createAdmin(cfg, args)
+	case "retry-refunds":
+		err = retryRefunds(cfg, args)
+	case "help", "-h", "-help", "--help":
+		fmt.Print(usage)
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", cmd, usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		slog.Error(cmd+" failed", "error", err)
+		os.Exit(1)
+	}
 }
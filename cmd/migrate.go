@@ -0,0 +1,71 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"richisntreal-backend/cmd/config"
+	"richisntreal-backend/internal/infrastructure/mysql"
+)
+
+const migrateUsage = "usage: richisntreal migrate up|down|status|to N|force N"
+
+// migrateCommand moves the schema between the migrations built into the
+// binary.
+func migrateCommand(cfg config.Cfg, args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+	var target uint64
+	switch args[0] {
+	case "up", "down", "status":
+		if len(args) != 1 {
+			return errors.New(migrateUsage)
+		}
+	case "to", "force":
+		if len(args) != 2 {
+			return errors.New(migrateUsage)
+		}
+		n, err := strconv.ParseUint(args[1], 10, 32)
+		if err != nil {
+			return fmt.Errorf("migrate %s: %q is not a version", args[0], args[1])
+		}
+		target = n
+	default:
+		return errors.New(migrateUsage)
+	}
+
+	m, err := mysql.NewMigrator(cfg.MySQL)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	switch args[0] {
+	case "up":
+		err = m.Up()
+	case "down":
+		err = m.Down()
+	case "to":
+		err = m.To(uint(target))
+	case "force":
+		err = m.Force(uint(target))
+	}
+	if err != nil {
+		return err
+	}
+
+	st, err := m.Status()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("version: %d\nlatest:  %d\n", st.Version, st.Latest)
+	if len(st.Pending) > 0 {
+		fmt.Printf("pending: %v\n", st.Pending)
+	}
+	if st.Dirty {
+		fmt.Printf("dirty:   migration %d failed half-way; repair the schema by hand, then run \"migrate force N\"\n", st.Version)
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"richisntreal-backend/cmd/config"
+	"richisntreal-backend/internal/core/services"
+	"richisntreal-backend/internal/infrastructure/mysql"
+)
+
+// retryRefunds retries the refunds that failed when orders were cancelled.
+// It is safe to run on a schedule; settled orders drop out of the queue.
+func retryRefunds(cfg config.Cfg, args []string) error {
+	fs := flag.NewFlagSet("retry-refunds", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return ignoreHelp(err)
+	}
+
+	mysqlClient, err := mysql.NewMySQL(cfg.MySQL)
+	if err != nil {
+		return fmt.Errorf("failed to connect to MySQL: %w", err)
+	}
+	defer mysqlClient.DB.Close()
+	// only refunds are sent, which charge metrics don't count
+	paymentSvc := services.NewPaymentService(mysql.NewPaymentRepository(mysqlClient.DB), cfg.Stripe.SecretKey, nil)
+
+	settled, err := paymentSvc.RetryCancellationRefunds(context.Background())
+	fmt.Printf("settled %d cancelled orders\n", settled)
+	return err
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+
+	"richisntreal-backend/cmd/config"
+	"richisntreal-backend/internal/core/services"
+	"richisntreal-backend/internal/infrastructure/mysql"
+)
+
+// demoProducts is the catalog seed loads; SKUs identify what is loaded.
+var demoProducts = []struct {
+	name, description, sku string
+	price                  float64
+	stock                  *int
+}{
+	{"Classic Tee", "Heavyweight cotton t-shirt.", "DEMO-TEE", 25, stock(120)},
+	{"Hoodie", "Brushed fleece pullover hoodie.", "DEMO-HOODIE", 59, stock(40)},
+	{"Cap", "Six-panel cap with an embroidered logo.", "DEMO-CAP", 19.5, stock(75)},
+	{"Tote Bag", "Canvas tote with a reinforced strap.", "DEMO-TOTE", 15, stock(3)},
+	{"Sticker Pack", "Five vinyl stickers.", "DEMO-STICKERS", 4.99, nil},
+	{"Gift Card", "Redeemable against anything in the shop.", "DEMO-GIFT", 50, nil},
+}
+
+// demoCustomers are the accounts seed creates, already verified.
+var demoCustomers = []struct {
+	username, email, firstName, lastName, country string
+}{
+	{"alice", "alice@example.com", "Alice", "Jansen", "NL"},
+	{"bob", "bob@example.com", "Bob", "Smith", "GB"},
+}
+
+// seed loads a demo catalog and demo customers. What already exists is
+// left alone, so it can run again.
+func seed(cfg config.Cfg, args []string) error {
+	fs := flag.NewFlagSet("seed", flag.ContinueOnError)
+	password := fs.String("password", "demo-password", "password for the demo customers")
+	if err := fs.Parse(args); err != nil {
+		return ignoreHelp(err)
+	}
+	if len(*password) < services.MinPasswordLength {
+		return services.ErrWeakPassword
+	}
+	ctx := context.Background()
+
+	// 1) Connect; the schema has to be in place already
+	mysqlClient, err := mysql.NewMySQL(cfg.MySQL)
+	if err != nil {
+		return fmt.Errorf("failed to connect to MySQL: %w", err)
+	}
+	defer mysqlClient.DB.Close()
+	prodRepo := mysql.NewProductRepository(mysqlClient.DB)
+	userRepo := mysql.NewUserRepository(mysqlClient.DB)
+	prodSvc := services.NewProductService(prodRepo)
+	// only account creation is used, which needs neither sessions nor 2FA
+	userSvc := services.NewUserService(userRepo, nil, nil, false)
+
+	// 2) Catalog
+	existing, err := prodRepo.FindAll(ctx)
+	if err != nil {
+		return err
+	}
+	skus := make(map[string]bool, len(existing))
+	for _, p := range existing {
+		skus[p.SKU] = true
+	}
+	for _, p := range demoProducts {
+		if skus[p.sku] {
+			fmt.Printf("product %s exists, skipped\n", p.sku)
+			continue
+		}
+		created, err := prodSvc.CreateProduct(ctx, p.name, p.description, p.sku, p.price, p.stock)
+		if err != nil {
+			return fmt.Errorf("product %s: %w", p.sku, err)
+		}
+		fmt.Printf("product %s created with id %d\n", p.sku, created.ID)
+	}
+
+	// 3) Customers
+	for _, c := range demoCustomers {
+		u, err := userSvc.CreateUser(ctx, c.username, c.email, *password, c.firstName, c.lastName, c.country, nil)
+		if errors.Is(err, services.ErrUserExists) {
+			fmt.Printf("customer %s exists, skipped\n", c.email)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("customer %s: %w", c.email, err)
+		}
+		if err := userRepo.MarkEmailVerified(ctx, u.ID); err != nil {
+			return fmt.Errorf("customer %s: %w", c.email, err)
+		}
+		fmt.Printf("customer %s created with id %d\n", c.email, u.ID)
+	}
+	return nil
+}
+
+func stock(n int) *int {
+	return &n
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"richisntreal-backend/cmd/bootstrap"
+	"richisntreal-backend/cmd/config"
+	"richisntreal-backend/internal/infrastructure/tracing"
+)
+
+// serve runs the HTTP server.
+func serve(cfg config.Cfg, args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	migrate := fs.Bool("migrate", cfg.MySQL.AutoMigrate, "apply pending migrations before serving")
+	if err := fs.Parse(args); err != nil {
+		return ignoreHelp(err)
+	}
+	cfg.MySQL.AutoMigrate = *migrate
+
+	// Tracing, before anything opens connections that should be traced;
+	// flush buffered spans on every way out, they may explain why we went down
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing, cfg.App.Name)
+	if err != nil {
+		return fmt.Errorf("failed to set up tracing: %w", err)
+	}
+	defer shutdownTracing(context.Background())
+
+	if err := run(cfg); err != nil {
+		return fmt.Errorf("server stopped: %w", err)
+	}
+	return nil
+}
+
+// run serves until SIGINT or SIGTERM, then drains in-flight requests.
+func run(cfg config.Cfg) error {
+	shutdown, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// 1) Bootstrap
+	router, cleanup, err := bootstrap.NewRouter(shutdown, cfg)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	srv := &http.Server{
+		Addr:              ":" + cfg.App.Port,
+		Handler:           router,
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		ReadHeaderTimeout: cfg.Server.ReadTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+	}
+
+	// 2) Start
+	serveErr := make(chan error, 1)
+	go func() {
+		slog.Info("listening", "port", cfg.App.Port)
+		serveErr <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-shutdown.Done():
+	}
+
+	// 3) Drain: /readyz already fails; give the load balancer the delay to
+	// notice, then stop accepting and let in-flight requests finish. A second
+	// signal kills the process right away.
+	stop()
+	slog.Info("shutting down", "delay", cfg.Server.ShutdownDelay, "timeout", cfg.Server.ShutdownTimeout)
+	time.Sleep(cfg.Server.ShutdownDelay)
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		return err
+	}
+	if err := <-serveErr; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	slog.Info("shutdown complete")
+	return nil
+}
+
+// ignoreHelp treats asking for a command's flags as success.
+func ignoreHelp(err error) error {
+	if errors.Is(err, flag.ErrHelp) {
+		return nil
+	}
+	return err
+}
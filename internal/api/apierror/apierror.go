@@ -0,0 +1,110 @@
+package apierror
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"richisntreal-backend/internal/infrastructure/logging"
+)
+
+// ContentType is the media type of RFC 7807 problem documents.
+const ContentType = "application/problem+json"
+
+// requestIDHeader mirrors middleware.RequestIDHeader, which has already set
+// it on the response by the time an error is written.
+const requestIDHeader = "X-Request-ID"
+
+// Error is an error as the client sees it: an HTTP status, a stable
+// machine-readable code, a message safe to show and optional details.
+type Error struct {
+	Status  int
+	Code    string
+	Message string
+	Details map[string]any
+}
+
+// New constructs an Error.
+func New(status int, code, message string) *Error {
+	return &Error{Status: status, Code: code, Message: message}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// With returns a copy of e with key added to its details.
+func (e *Error) With(key string, value any) *Error {
+	c := *e
+	c.Details = make(map[string]any, len(e.Details)+1)
+	for k, v := range e.Details {
+		c.Details[k] = v
+	}
+	c.Details[key] = value
+	return &c
+}
+
+// Errors the handlers and middleware share.
+var (
+	ErrUnauthorized     = New(http.StatusUnauthorized, "unauthorized", "authentication required")
+	ErrForbidden        = New(http.StatusForbidden, "forbidden", "you may not access this resource")
+	ErrInvalidPayload   = New(http.StatusBadRequest, "invalid_payload", "invalid request payload")
+	ErrNotFound         = New(http.StatusNotFound, "not_found", "no such resource")
+	ErrMethodNotAllowed = New(http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed on this resource")
+	ErrTooManyRequests  = New(http.StatusTooManyRequests, "rate_limited", "too many requests")
+	ErrInternal         = New(http.StatusInternalServerError, "internal", "internal server error")
+)
+
+// InvalidParam reports a path or query parameter that can't be parsed.
+func InvalidParam(name string) *Error {
+	return New(http.StatusBadRequest, "invalid_parameter", "invalid "+name).With("parameter", name)
+}
+
+// Invalid reports a request that parsed but doesn't make sense, with a
+// message written for the client.
+func Invalid(message string) *Error {
+	return New(http.StatusBadRequest, "invalid_request", message)
+}
+
+// Problem is the RFC 7807 body, extended with our code, details and the
+// request ID to quote when reporting a problem.
+type Problem struct {
+	Type      string         `json:"type"`
+	Title     string         `json:"title"`
+	Status    int            `json:"status"`
+	Detail    string         `json:"detail"`
+	Instance  string         `json:"instance,omitempty"`
+	Code      string         `json:"code"`
+	RequestID string         `json:"request_id,omitempty"`
+	Details   map[string]any `json:"details,omitempty"`
+}
+
+// Write answers with err as a problem document. Errors that are neither an
+// *Error nor a known domain error become a bare 500: their text may hold
+// SQL or gateway messages, so it is logged and never sent.
+func Write(w http.ResponseWriter, r *http.Request, err error) {
+	var e *Error
+	if !errors.As(err, &e) {
+		e = fromDomain(err)
+	}
+	if e == nil {
+		logging.FromContext(r.Context()).Error("unhandled error", "error", err)
+		e = ErrInternal
+	} else if e.Status >= http.StatusInternalServerError && err != error(e) {
+		logging.FromContext(r.Context()).Error(e.Message, "error", err)
+	}
+
+	w.Header().Set("Content-Type", ContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(e.Status)
+	json.NewEncoder(w).Encode(Problem{
+		Type:      "about:blank",
+		Title:     http.StatusText(e.Status),
+		Status:    e.Status,
+		Detail:    e.Message,
+		Instance:  r.URL.Path,
+		Code:      e.Code,
+		RequestID: w.Header().Get(requestIDHeader),
+		Details:   e.Details,
+	})
+}
@@ -0,0 +1,93 @@
+package apierror
+
+import (
+	"errors"
+	"net/http"
+
+	"richisntreal-backend/internal/api/auth"
+	"richisntreal-backend/internal/core/services"
+)
+
+// domainErrors is the one place service and auth errors get a status and
+// code. The message is the sentinel's own text, or a friendlier one, and
+// never that of the wrapping error, which may quote a gateway or driver.
+var domainErrors = []struct {
+	err error
+	api *Error
+}{
+	// users and accounts
+	{services.ErrUserNotFound, New(http.StatusNotFound, "user_not_found", "user not found")},
+	{services.ErrUserExists, New(http.StatusConflict, "email_taken", "email already in use")},
+	{services.ErrInvalidCredentials, New(http.StatusUnauthorized, "invalid_credentials", services.ErrInvalidCredentials.Error())},
+	{services.ErrEmailNotVerified, New(http.StatusForbidden, "email_not_verified", services.ErrEmailNotVerified.Error())},
+	{services.ErrAccountDisabled, New(http.StatusForbidden, "account_disabled", services.ErrAccountDisabled.Error())},
+	{auth.ErrAccountDisabled, New(http.StatusForbidden, "account_disabled", auth.ErrAccountDisabled.Error())},
+	{services.ErrWrongPassword, New(http.StatusForbidden, "wrong_password", services.ErrWrongPassword.Error())},
+	{services.ErrWeakPassword, New(http.StatusBadRequest, "weak_password", services.ErrWeakPassword.Error())},
+	{services.ErrInvalidEmail, New(http.StatusBadRequest, "invalid_email", services.ErrInvalidEmail.Error())},
+	{services.ErrInvalidUserToken, New(http.StatusBadRequest, "invalid_token", services.ErrInvalidUserToken.Error())},
+	{services.ErrEmailAlreadyVerified, New(http.StatusConflict, "email_already_verified", services.ErrEmailAlreadyVerified.Error())},
+
+	// sessions and tokens
+	{services.ErrInvalidRefreshToken, New(http.StatusUnauthorized, "invalid_refresh_token", services.ErrInvalidRefreshToken.Error())},
+	{services.ErrRefreshTokenReused, New(http.StatusUnauthorized, "refresh_token_reused", services.ErrRefreshTokenReused.Error())},
+	{auth.ErrInvalidToken, New(http.StatusUnauthorized, "invalid_token", auth.ErrInvalidToken.Error())},
+	{auth.ErrSessionRevoked, New(http.StatusUnauthorized, "session_revoked", auth.ErrSessionRevoked.Error())},
+	{services.ErrInvalidAPIKey, New(http.StatusUnauthorized, "invalid_api_key", services.ErrInvalidAPIKey.Error())},
+	{services.ErrAPIKeyNotFound, New(http.StatusNotFound, "api_key_not_found", services.ErrAPIKeyNotFound.Error())},
+	{services.ErrInvalidScope, New(http.StatusBadRequest, "invalid_scope", services.ErrInvalidScope.Error())},
+
+	// two-factor
+	{services.ErrInvalidTwoFactorCode, New(http.StatusBadRequest, "invalid_two_factor_code", services.ErrInvalidTwoFactorCode.Error())},
+	{services.ErrInvalidChallenge, New(http.StatusUnauthorized, "invalid_challenge", services.ErrInvalidChallenge.Error())},
+	{services.ErrTwoFactorAlreadyEnabled, New(http.StatusConflict, "two_factor_enabled", services.ErrTwoFactorAlreadyEnabled.Error())},
+	{services.ErrTwoFactorNotEnabled, New(http.StatusConflict, "two_factor_not_enabled", services.ErrTwoFactorNotEnabled.Error())},
+	{services.ErrTwoFactorNotEnrolling, New(http.StatusConflict, "two_factor_not_enrolling", services.ErrTwoFactorNotEnrolling.Error())},
+
+	// social login
+	{services.ErrUnknownProvider, New(http.StatusNotFound, "unknown_provider", services.ErrUnknownProvider.Error())},
+	{services.ErrInvalidOIDCState, New(http.StatusBadRequest, "invalid_login_state", services.ErrInvalidOIDCState.Error())},
+	{services.ErrIdentityRejected, New(http.StatusUnauthorized, "identity_rejected", services.ErrIdentityRejected.Error())},
+	{services.ErrIdentityEmailUnverified, New(http.StatusUnauthorized, "identity_email_unverified", services.ErrIdentityEmailUnverified.Error())},
+	{services.ErrLocalEmailUnverified, New(http.StatusConflict, "local_email_unverified", services.ErrLocalEmailUnverified.Error())},
+
+	// support
+	{services.ErrCannotTargetSelf, New(http.StatusConflict, "cannot_target_self", services.ErrCannotTargetSelf.Error())},
+	{services.ErrCannotImpersonateAdmin, New(http.StatusConflict, "cannot_impersonate_admin", services.ErrCannotImpersonateAdmin.Error())},
+
+	// catalogue and cart
+	{services.ErrProductNotFound, New(http.StatusNotFound, "product_not_found", services.ErrProductNotFound.Error())},
+	{services.ErrCartItemNotFound, New(http.StatusNotFound, "cart_item_not_found", services.ErrCartItemNotFound.Error())},
+	{services.ErrCartEmpty, New(http.StatusConflict, "cart_empty", services.ErrCartEmpty.Error())},
+	{services.ErrInsufficientStock, New(http.StatusConflict, "insufficient_stock", services.ErrInsufficientStock.Error())},
+
+	// orders, payments and invoices
+	{services.ErrOrderNotFound, New(http.StatusNotFound, "order_not_found", services.ErrOrderNotFound.Error())},
+	{services.ErrInvalidOrderTransition, New(http.StatusConflict, "invalid_order_transition", services.ErrInvalidOrderTransition.Error())},
+	{services.ErrOrderNotCancellable, New(http.StatusConflict, "order_not_cancellable", services.ErrOrderNotCancellable.Error())},
+	{services.ErrOrderNotPayable, New(http.StatusConflict, "order_not_payable", services.ErrOrderNotPayable.Error())},
+	{services.ErrPaymentFailed, New(http.StatusPaymentRequired, "payment_failed", services.ErrPaymentFailed.Error())},
+	{services.ErrPaymentOutcomeUnknown, New(http.StatusBadGateway, "payment_outcome_unknown", "the payment provider did not answer; the payment is being checked")},
+	{services.ErrRefundFailed, New(http.StatusBadGateway, "refund_failed", "could not refund payment")},
+	{services.ErrRefundOutcomeUnknown, New(http.StatusBadGateway, "refund_outcome_unknown", "the payment provider did not answer; the refund is being checked")},
+	{services.ErrNothingToRefund, New(http.StatusConflict, "nothing_to_refund", services.ErrNothingToRefund.Error())},
+	{services.ErrRefundExceedsPayment, New(http.StatusConflict, "refund_exceeds_payment", services.ErrRefundExceedsPayment.Error())},
+	{services.ErrInvoiceNotFound, New(http.StatusNotFound, "invoice_not_found", services.ErrInvoiceNotFound.Error())},
+
+	// returns
+	{services.ErrReturnNotFound, New(http.StatusNotFound, "return_not_found", services.ErrReturnNotFound.Error())},
+	{services.ErrOrderNotReturnable, New(http.StatusConflict, "order_not_returnable", services.ErrOrderNotReturnable.Error())},
+	{services.ErrInvalidReturnItems, New(http.StatusBadRequest, "invalid_return_items", services.ErrInvalidReturnItems.Error())},
+	{services.ErrInvalidReturnTransition, New(http.StatusConflict, "invalid_return_transition", services.ErrInvalidReturnTransition.Error())},
+	{services.ErrInvalidResolution, New(http.StatusBadRequest, "invalid_resolution", services.ErrInvalidResolution.Error())},
+}
+
+// fromDomain finds the API error for a service or auth error, or nil.
+func fromDomain(err error) *Error {
+	for _, d := range domainErrors {
+		if errors.Is(err, d.err) {
+			return d.api
+		}
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// APIKeyHeader carries an API key.
+const APIKeyHeader = "X-API-Key"
+
+var ErrNoAPIKey = errors.New("no api key")
+
+// APIKeyResolver checks an API key and says whom it acts for.
+type APIKeyResolver interface {
+	ResolveAPIKey(ctx context.Context, raw string) (userID, keyID int64, scopes []string, err error)
+}
+
+// APIKeyAuthenticator authenticates integrations by the X-API-Key header.
+type APIKeyAuthenticator struct {
+	keys APIKeyResolver
+}
+
+// NewAPIKeyAuthenticator constructs one.
+func NewAPIKeyAuthenticator(keys APIKeyResolver) *APIKeyAuthenticator {
+	return &APIKeyAuthenticator{keys: keys}
+}
+
+func (a *APIKeyAuthenticator) Authenticate(r *http.Request) (*Principal, error) {
+	raw := r.Header.Get(APIKeyHeader)
+	if raw == "" {
+		return nil, ErrNoAPIKey
+	}
+	userID, keyID, scopes, err := a.keys.ResolveAPIKey(r.Context(), raw)
+	if err != nil {
+		return nil, err
+	}
+	if scopes == nil {
+		scopes = []string{}
+	}
+	return &Principal{UserID: userID, APIKeyID: keyID, Scopes: scopes}, nil
+}
+
+// CompositeAuthenticator tries several authenticators in order. One that
+// finds no credentials of its kind passes on to the next; one that finds
+// bad credentials fails the request.
+type CompositeAuthenticator struct {
+	authenticators []Authenticator
+}
+
+// NewCompositeAuthenticator constructs one.
+func NewCompositeAuthenticator(authenticators ...Authenticator) *CompositeAuthenticator {
+	return &CompositeAuthenticator{authenticators: authenticators}
+}
+
+func (c *CompositeAuthenticator) Authenticate(r *http.Request) (*Principal, error) {
+	for _, a := range c.authenticators {
+		p, err := a.Authenticate(r)
+		if errors.Is(err, ErrNoToken) || errors.Is(err, ErrNoAPIKey) {
+			continue
+		}
+		return p, err
+	}
+	return nil, ErrNoToken
+}
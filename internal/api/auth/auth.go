@@ -4,8 +4,33 @@ import (
 	"net/http"
 )
 
-// Authenticator knows how to extract & validate a user ID from an HTTP request.
+// Principal is the authenticated caller behind a request: a user logged
+// in through a session, or an API key acting for its owner.
+type Principal struct {
+	UserID    int64
+	SessionID int64    // set for session (JWT) logins
+	APIKeyID  int64    // set for API keys
+	Scopes    []string // what an API key may do; nil for sessions, which may do anything
+
+	// ImpersonatorID is the admin acting as UserID, for impersonation sessions.
+	ImpersonatorID int64
+}
+
+// HasScope reports whether the caller may act within scope.
+func (p *Principal) HasScope(scope string) bool {
+	if p.Scopes == nil {
+		return true
+	}
+	for _, s := range p.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Authenticator knows how to extract & validate the caller from an HTTP request.
 type Authenticator interface {
-	// Authenticate returns the userID or an error if unauthenticated.
-	Authenticate(r *http.Request) (int64, error)
+	// Authenticate returns the caller or an error if unauthenticated.
+	Authenticate(r *http.Request) (*Principal, error)
 }
@@ -0,0 +1,75 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+var ErrAccountDisabled = errors.New("account disabled")
+
+// AccountChecker tells whether a user may still use the API.
+type AccountChecker interface {
+	IsAccountEnabled(ctx context.Context, userID int64) (bool, error)
+}
+
+// ImpersonationAuditor records requests an admin makes as another user.
+type ImpersonationAuditor interface {
+	RecordImpersonatedRequest(ctx context.Context, adminID, userID int64, method, path string) error
+}
+
+// WithAccountCheck rejects callers whose account was disabled or deleted
+// after their credentials were issued. For an impersonation token both the
+// user and the admin acting as them must still be enabled.
+func WithAccountCheck(a Authenticator, accounts AccountChecker) Authenticator {
+	return &accountCheckingAuthenticator{next: a, accounts: accounts}
+}
+
+type accountCheckingAuthenticator struct {
+	next     Authenticator
+	accounts AccountChecker
+}
+
+func (a *accountCheckingAuthenticator) Authenticate(r *http.Request) (*Principal, error) {
+	p, err := a.next.Authenticate(r)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range []int64{p.UserID, p.ImpersonatorID} {
+		if id == 0 {
+			continue
+		}
+		ok, err := a.accounts.IsAccountEnabled(r.Context(), id)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, ErrAccountDisabled
+		}
+	}
+	return p, nil
+}
+
+// WithImpersonationAudit records every request made with an impersonation
+// token. A request that can't be recorded is refused.
+func WithImpersonationAudit(a Authenticator, auditor ImpersonationAuditor) Authenticator {
+	return &auditingAuthenticator{next: a, auditor: auditor}
+}
+
+type auditingAuthenticator struct {
+	next    Authenticator
+	auditor ImpersonationAuditor
+}
+
+func (a *auditingAuthenticator) Authenticate(r *http.Request) (*Principal, error) {
+	p, err := a.next.Authenticate(r)
+	if err != nil {
+		return nil, err
+	}
+	if p.ImpersonatorID != 0 {
+		if err := a.auditor.RecordImpersonatedRequest(r.Context(), p.ImpersonatorID, p.UserID, r.Method, r.URL.Path); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
@@ -1,6 +1,8 @@
 package auth
 
 import (
+	"context"
+	"crypto"
 	"errors"
 	"fmt"
 	"net/http"
@@ -11,41 +13,81 @@ import (
 
 var ErrNoToken = errors.New("no bearer token")
 var ErrInvalidToken = errors.New("invalid token")
+var ErrSessionRevoked = errors.New("session revoked")
 
-// JWTAuthenticator uses HMAC‑signed JWTs in the Authorization header.
+// SessionChecker tells whether a login session is still valid.
+type SessionChecker interface {
+	IsSessionActive(ctx context.Context, sessionID int64) (bool, error)
+}
+
+// KeyResolver finds the public key a token claims to be signed with.
+type KeyResolver interface {
+	VerificationKey(kid string) (jwt.SigningMethod, crypto.PublicKey, bool)
+}
+
+// JWTAuthenticator uses RS256/EdDSA‑signed JWTs in the Authorization header.
 type JWTAuthenticator struct {
-	secret []byte
+	keys     KeyResolver
+	sessions SessionChecker
 }
 
 // NewJWTAuthenticator constructs one.
-func NewJWTAuthenticator(secret string) *JWTAuthenticator {
-	return &JWTAuthenticator{secret: []byte(secret)}
+func NewJWTAuthenticator(keys KeyResolver, sessions SessionChecker) *JWTAuthenticator {
+	return &JWTAuthenticator{keys: keys, sessions: sessions}
 }
 
-func (j *JWTAuthenticator) Authenticate(r *http.Request) (int64, error) {
+func (j *JWTAuthenticator) Authenticate(r *http.Request) (*Principal, error) {
 	hdr := r.Header.Get("Authorization")
 	if !strings.HasPrefix(hdr, "Bearer ") {
-		return 0, ErrNoToken
+		return nil, ErrNoToken
 	}
 	tokenStr := strings.TrimPrefix(hdr, "Bearer ")
 
 	token, err := jwt.Parse(tokenStr, func(t *jwt.Token) (interface{}, error) {
-		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+		kid, _ := t.Header["kid"].(string)
+		method, key, ok := j.keys.VerificationKey(kid)
+		if !ok {
+			return nil, fmt.Errorf("unknown key id %q", kid)
+		}
+		// the key, not the token, decides the algorithm
+		if t.Method.Alg() != method.Alg() {
 			return nil, fmt.Errorf("unexpected signing method %v", t.Header["alg"])
 		}
-		return j.secret, nil
+		return key, nil
 	})
 	if err != nil || !token.Valid {
-		return 0, ErrInvalidToken
+		return nil, ErrInvalidToken
 	}
 
 	claims, ok := token.Claims.(jwt.MapClaims)
 	if !ok {
-		return 0, ErrInvalidToken
+		return nil, ErrInvalidToken
 	}
 	sub, ok := claims["sub"].(float64)
 	if !ok {
-		return 0, ErrInvalidToken
+		return nil, ErrInvalidToken
+	}
+	// tokens minted before sessions existed carry no sid and can't be revoked
+	sid, ok := claims["sid"].(float64)
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+
+	active, err := j.sessions.IsSessionActive(r.Context(), int64(sid))
+	if err != nil {
+		return nil, err
+	}
+	if !active {
+		return nil, ErrSessionRevoked
+	}
+	p := &Principal{UserID: int64(sub), SessionID: int64(sid)}
+	// impersonation tokens name the acting admin, RFC 8693 style
+	if act, ok := claims["act"].(map[string]interface{}); ok {
+		admin, ok := act["sub"].(float64)
+		if !ok {
+			return nil, ErrInvalidToken
+		}
+		p.ImpersonatorID = int64(admin)
 	}
-	return int64(sub), nil
+	return p, nil
 }
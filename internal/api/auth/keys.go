@@ -0,0 +1,227 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const minRSABits = 2048
+
+type signingKey struct {
+	kid    string
+	method jwt.SigningMethod
+	key    crypto.Signer
+}
+
+type verificationKey struct {
+	method jwt.SigningMethod
+	key    crypto.PublicKey
+}
+
+// KeySet holds the private key new tokens are signed with and every public
+// key tokens are still accepted from, indexed by kid. Rotating means adding
+// the next key to the verification set everywhere first, then switching
+// the signing key, then retiring the old public key once its tokens expire.
+type KeySet struct {
+	signing *signingKey
+	verify  map[string]verificationKey
+}
+
+// LoadKeySet reads the signing key from a PEM file (PKCS#8 or PKCS#1; RSA
+// or Ed25519) and trusts every "<kid>.pem" public or private key found in
+// verificationKeysDir. The signing key is always trusted under signingKeyID.
+func LoadKeySet(signingKeyID, signingKeyFile, verificationKeysDir string) (*KeySet, error) {
+	if signingKeyID == "" {
+		return nil, errors.New("jwt: signing key id is required")
+	}
+	raw, err := os.ReadFile(signingKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("jwt: reading signing key: %w", err)
+	}
+	priv, err := parsePrivateKey(raw)
+	if err != nil {
+		return nil, fmt.Errorf("jwt: %s: %w", signingKeyFile, err)
+	}
+	ks, err := newKeySet(signingKeyID, priv)
+	if err != nil {
+		return nil, err
+	}
+
+	if verificationKeysDir == "" {
+		return ks, nil
+	}
+	files, err := filepath.Glob(filepath.Join(verificationKeysDir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range files {
+		kid := strings.TrimSuffix(filepath.Base(f), ".pem")
+		if kid == signingKeyID {
+			continue
+		}
+		raw, err := os.ReadFile(f)
+		if err != nil {
+			return nil, fmt.Errorf("jwt: reading verification key: %w", err)
+		}
+		pub, err := parsePublicKey(raw)
+		if err != nil {
+			return nil, fmt.Errorf("jwt: %s: %w", f, err)
+		}
+		vk, err := newVerificationKey(pub)
+		if err != nil {
+			return nil, fmt.Errorf("jwt: %s: %w", f, err)
+		}
+		ks.verify[kid] = vk
+	}
+	return ks, nil
+}
+
+// NewEphemeralKeySet generates a throwaway Ed25519 key. Tokens it signs die
+// with the process and can't be verified by other instances, so it is only
+// meant for local development.
+func NewEphemeralKeySet() (*KeySet, error) {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return nil, err
+	}
+	return newKeySet("ephemeral-"+hex.EncodeToString(suffix), priv)
+}
+
+func newKeySet(kid string, priv crypto.Signer) (*KeySet, error) {
+	vk, err := newVerificationKey(priv.Public())
+	if err != nil {
+		return nil, err
+	}
+	return &KeySet{
+		signing: &signingKey{kid: kid, method: vk.method, key: priv},
+		verify:  map[string]verificationKey{kid: vk},
+	}, nil
+}
+
+func newVerificationKey(pub crypto.PublicKey) (verificationKey, error) {
+	switch k := pub.(type) {
+	case *rsa.PublicKey:
+		if k.N.BitLen() < minRSABits {
+			return verificationKey{}, fmt.Errorf("RSA key must be at least %d bits", minRSABits)
+		}
+		return verificationKey{method: jwt.SigningMethodRS256, key: k}, nil
+	case ed25519.PublicKey:
+		return verificationKey{method: jwt.SigningMethodEdDSA, key: k}, nil
+	default:
+		return verificationKey{}, fmt.Errorf("unsupported key type %T", pub)
+	}
+}
+
+// Sign signs claims with the current signing key and stamps its kid.
+func (ks *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(ks.signing.method, claims)
+	token.Header["kid"] = ks.signing.kid
+	return token.SignedString(ks.signing.key)
+}
+
+// VerificationKey returns the trusted public key published under kid.
+func (ks *KeySet) VerificationKey(kid string) (jwt.SigningMethod, crypto.PublicKey, bool) {
+	vk, ok := ks.verify[kid]
+	return vk.method, vk.key, ok
+}
+
+// JWK is a public key in JSON Web Key form (RFC 7517).
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKS is the document served at /.well-known/jwks.json.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS publishes every trusted public key, sorted by kid.
+func (ks *KeySet) JWKS() JWKS {
+	kids := make([]string, 0, len(ks.verify))
+	for kid := range ks.verify {
+		kids = append(kids, kid)
+	}
+	sort.Strings(kids)
+
+	set := JWKS{Keys: make([]JWK, 0, len(kids))}
+	b64 := base64.RawURLEncoding
+	for _, kid := range kids {
+		vk := ks.verify[kid]
+		jwk := JWK{Kid: kid, Use: "sig", Alg: vk.method.Alg()}
+		switch k := vk.key.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = b64.EncodeToString(k.N.Bytes())
+			jwk.E = b64.EncodeToString(big.NewInt(int64(k.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = b64.EncodeToString(k)
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
+
+func parsePrivateKey(raw []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(raw)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+	if k, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		if signer, ok := k.(crypto.Signer); ok {
+			return signer, nil
+		}
+		return nil, fmt.Errorf("unsupported private key type %T", k)
+	}
+	if k, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return k, nil
+	}
+	return nil, errors.New("not a PKCS#8 or PKCS#1 private key")
+}
+
+// parsePublicKey accepts a public key or, for convenience, a private key
+// whose public half is then used.
+func parsePublicKey(raw []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(raw)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+	if k, err := x509.ParsePKIXPublicKey(block.Bytes); err == nil {
+		return k, nil
+	}
+	if k, err := x509.ParsePKCS1PublicKey(block.Bytes); err == nil {
+		return k, nil
+	}
+	priv, err := parsePrivateKey(raw)
+	if err != nil {
+		return nil, errors.New("not a PKIX or PKCS#1 public key")
+	}
+	return priv.Public(), nil
+}
@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"net/http"
+
+	"richisntreal-backend/internal/api/apierror"
+	"richisntreal-backend/internal/api/middleware"
+	"richisntreal-backend/internal/api/validate"
+	"richisntreal-backend/internal/core/services"
+)
+
+// AccountHandler wires the password reset and email verification endpoints.
+type AccountHandler struct {
+	accountService *services.AccountService
+}
+
+// NewAccountHandler constructs a new AccountHandler.
+func NewAccountHandler(accountService *services.AccountService) *AccountHandler {
+	return &AccountHandler{accountService: accountService}
+}
+
+type forgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+func (req forgotPasswordRequest) Validate() error {
+	var v validate.Errors
+	v.Email("email", req.Email)
+	return v.Err()
+}
+
+type resetPasswordRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+func (req resetPasswordRequest) Validate() error {
+	var v validate.Errors
+	v.Required("token", req.Token)
+	checkNewPassword(&v, "password", req.Password)
+	return v.Err()
+}
+
+type verifyEmailRequest struct {
+	Token string `json:"token"`
+}
+
+func (req verifyEmailRequest) Validate() error {
+	var v validate.Errors
+	v.Required("token", req.Token)
+	return v.Err()
+}
+
+// ForgotPassword mails a reset link. It always answers 202 so callers
+// can't tell whether the address has an account.
+func (h *AccountHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	var req forgotPasswordRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	if err := h.accountService.RequestPasswordReset(r.Context(), req.Email); err != nil {
+		serverError(w, r, "internal server error", err)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// ResetPassword sets a new password using a mailed reset token.
+func (h *AccountHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	var req resetPasswordRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	if err := h.accountService.ResetPassword(r.Context(), req.Token, req.Password); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// VerifyEmail confirms an email address using a mailed verification token.
+func (h *AccountHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	var req verifyEmailRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	if err := h.accountService.VerifyEmail(r.Context(), req.Token); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ConfirmEmailChange switches the account to the new address using the
+// token mailed to it.
+func (h *AccountHandler) ConfirmEmailChange(w http.ResponseWriter, r *http.Request) {
+	var req verifyEmailRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	if err := h.accountService.ConfirmEmailChange(r.Context(), req.Token); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ResendVerification mails the caller a fresh verification link.
+func (h *AccountHandler) ResendVerification(w http.ResponseWriter, r *http.Request) {
+	caller := middleware.FromContext(r.Context())
+	if caller == 0 {
+		apierror.Write(w, r, apierror.ErrUnauthorized)
+		return
+	}
+	if err := h.accountService.SendEmailVerification(r.Context(), caller); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
@@ -0,0 +1,338 @@
+package handlers
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"richisntreal-backend/internal/api/apierror"
+	"richisntreal-backend/internal/api/middleware"
+	"richisntreal-backend/internal/api/validate"
+	"richisntreal-backend/internal/core/domain/models"
+	"richisntreal-backend/internal/core/services"
+	"richisntreal-backend/internal/infrastructure/logging"
+)
+
+const (
+	defaultPerPage = 50
+	maxPerPage     = 200
+)
+
+// AdminOrderHandler wires the back-office order endpoints.
+type AdminOrderHandler struct {
+	orderService   *services.OrderService
+	paymentService *services.PaymentService
+}
+
+// NewAdminOrderHandler constructs a new AdminOrderHandler.
+func NewAdminOrderHandler(orderService *services.OrderService, paymentService *services.PaymentService) *AdminOrderHandler {
+	return &AdminOrderHandler{orderService: orderService, paymentService: paymentService}
+}
+
+type adminOrderListResponse struct {
+	Orders  []*models.Order `json:"orders"`
+	Page    int             `json:"page"`
+	PerPage int             `json:"per_page"`
+	Total   int             `json:"total"`
+}
+
+type adminOrderResponse struct {
+	*models.Order
+	Notes []*models.OrderNote `json:"notes"`
+}
+
+type addNoteRequest struct {
+	Body string `json:"body"`
+}
+
+func (req addNoteRequest) Validate() error {
+	var v validate.Errors
+	v.Required("body", req.Body)
+	v.MaxLength("body", req.Body, 2000)
+	return v.Err()
+}
+
+type changeStatusRequest struct {
+	Status string `json:"status"`
+	Reason string `json:"reason"` // required when cancelling
+}
+
+func (req changeStatusRequest) Validate() error {
+	var v validate.Errors
+	v.Required("status", req.Status)
+	if req.Status == models.OrderStatusCancelled {
+		v.Required("reason", req.Reason)
+	}
+	v.MaxLength("reason", req.Reason, 255)
+	return v.Err()
+}
+
+// ListOrders searches all orders. Query parameters: status, user_id,
+// from/to (RFC3339 or YYYY-MM-DD; a bare "to" date is inclusive),
+// min_total, max_total, page and per_page.
+func (h *AdminOrderHandler) ListOrders(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseOrderFilter(r.URL.Query())
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	page, perPage, err := parsePagination(r.URL.Query())
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	filter.Limit = perPage
+	filter.Offset = (page - 1) * perPage
+
+	orders, total, err := h.orderService.SearchOrders(r.Context(), filter)
+	if err != nil {
+		serverError(w, r, "could not fetch orders", err)
+		return
+	}
+	if orders == nil {
+		orders = []*models.Order{}
+	}
+	err = json.NewEncoder(w).Encode(adminOrderListResponse{
+		Orders:  orders,
+		Page:    page,
+		PerPage: perPage,
+		Total:   total,
+	})
+	if err != nil {
+		return
+	}
+}
+
+// ExportOrders streams every order matching the ListOrders filters as CSV,
+// fetching them a page at a time. Orders placed after the export started
+// are left out so they can't shift the pages. Each page gets the request
+// timeout afresh, so a long export isn't cut off part way.
+func (h *AdminOrderHandler) ExportOrders(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseOrderFilter(r.URL.Query())
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	if started := time.Now().Truncate(time.Second); filter.To == nil || filter.To.After(started) {
+		filter.To = &started
+	}
+	filter.Limit = maxPerPage
+	filter.SkipCount = true
+
+	// fetch the first page before writing, so a failure can still be reported
+	orders, _, err := h.orderService.SearchOrders(r.Context(), filter)
+	if err != nil {
+		serverError(w, r, "could not fetch orders", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", `attachment; filename="orders.csv"`)
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"id", "reference", "user_id", "status", "total", "items", "created_at", "cancelled_at", "cancellation_reason"})
+	for {
+		for _, o := range orders {
+			var qty int
+			for _, it := range o.Items {
+				qty += it.Quantity
+			}
+			cancelledAt, reason := "", ""
+			if o.CancelledAt != nil {
+				cancelledAt = o.CancelledAt.Format(time.RFC3339)
+			}
+			if o.CancellationReason != nil {
+				reason = *o.CancellationReason
+			}
+			_ = cw.Write([]string{
+				strconv.FormatInt(o.ID, 10),
+				csvText(o.Reference),
+				strconv.FormatInt(o.UserID, 10),
+				csvText(o.Status),
+				strconv.FormatFloat(o.Total, 'f', 2, 64),
+				strconv.Itoa(qty),
+				o.CreatedAt.Format(time.RFC3339),
+				cancelledAt,
+				csvText(reason),
+			})
+		}
+		cw.Flush()
+		if len(orders) < filter.Limit {
+			return
+		}
+
+		filter.Offset += filter.Limit
+		if orders, err = h.exportPage(r.Context(), filter); err != nil {
+			// the status line is gone; cut the file short so it isn't taken as complete
+			logging.FromContext(r.Context()).Error("order export failed part way", "offset", filter.Offset, "error", err)
+			panic(http.ErrAbortHandler)
+		}
+	}
+}
+
+func (h *AdminOrderHandler) exportPage(ctx context.Context, filter models.OrderFilter) ([]*models.Order, error) {
+	ctx, cancel := middleware.RenewTimeout(ctx)
+	defer cancel()
+	orders, _, err := h.orderService.SearchOrders(ctx, filter)
+	return orders, err
+}
+
+// csvText keeps free text from being read as a formula when the export is
+// opened in a spreadsheet.
+func csvText(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+// GetOrder fetches any order together with its internal notes.
+func (h *AdminOrderHandler) GetOrder(w http.ResponseWriter, r *http.Request) {
+	orderID, err := strconv.ParseInt(chi.URLParam(r, "orderID"), 10, 64)
+	if err != nil {
+		apierror.Write(w, r, apierror.InvalidParam("orderID"))
+		return
+	}
+	ord, err := h.orderService.GetOrderByID(r.Context(), orderID)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	notes, err := h.orderService.GetNotes(r.Context(), orderID)
+	if err != nil {
+		serverError(w, r, "could not fetch notes", err)
+		return
+	}
+	err = json.NewEncoder(w).Encode(adminOrderResponse{Order: ord, Notes: notes})
+	if err != nil {
+		return
+	}
+}
+
+// AddNote attaches an internal note written by the calling staff member.
+func (h *AdminOrderHandler) AddNote(w http.ResponseWriter, r *http.Request) {
+	orderID, err := strconv.ParseInt(chi.URLParam(r, "orderID"), 10, 64)
+	if err != nil {
+		apierror.Write(w, r, apierror.InvalidParam("orderID"))
+		return
+	}
+	var req addNoteRequest
+	if err = decodeJSON(w, r, &req); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	note, err := h.orderService.AddNote(r.Context(), orderID, middleware.FromContext(r.Context()), req.Body)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+	err = json.NewEncoder(w).Encode(note)
+	if err != nil {
+		return
+	}
+}
+
+// ChangeStatus moves an order to a new status. Cancelling also voids or
+// refunds the payment and releases stock, exactly like a customer cancellation.
+func (h *AdminOrderHandler) ChangeStatus(w http.ResponseWriter, r *http.Request) {
+	orderID, err := strconv.ParseInt(chi.URLParam(r, "orderID"), 10, 64)
+	if err != nil {
+		apierror.Write(w, r, apierror.InvalidParam("orderID"))
+		return
+	}
+	var req changeStatusRequest
+	if err = decodeJSON(w, r, &req); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	var ord *models.Order
+	if req.Status == models.OrderStatusCancelled {
+		ord, err = cancelOrder(r.Context(), h.orderService, h.paymentService, orderID, req.Reason)
+	} else {
+		ord, err = h.orderService.ChangeStatus(r.Context(), orderID, req.Status)
+	}
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	err = json.NewEncoder(w).Encode(ord)
+	if err != nil {
+		return
+	}
+}
+
+// parsePagination reads the page and per_page query parameters.
+func parsePagination(q url.Values) (page, perPage int, err error) {
+	page, perPage = 1, defaultPerPage
+	if v := q.Get("page"); v != "" {
+		if page, err = strconv.Atoi(v); err != nil || page < 1 {
+			return 0, 0, apierror.InvalidParam("page")
+		}
+	}
+	if v := q.Get("per_page"); v != "" {
+		if perPage, err = strconv.Atoi(v); err != nil || perPage < 1 || perPage > maxPerPage {
+			return 0, 0, apierror.InvalidParam("per_page").With("max", maxPerPage)
+		}
+	}
+	return page, perPage, nil
+}
+
+func parseOrderFilter(q url.Values) (models.OrderFilter, error) {
+	var f models.OrderFilter
+	f.Status = q.Get("status")
+	if v := q.Get("user_id"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return f, apierror.InvalidParam("user_id")
+		}
+		f.UserID = id
+	}
+	if v := q.Get("from"); v != "" {
+		t, _, err := parseDateParam(v)
+		if err != nil {
+			return f, apierror.InvalidParam("from").With("format", "RFC3339 or YYYY-MM-DD")
+		}
+		f.From = &t
+	}
+	if v := q.Get("to"); v != "" {
+		t, dateOnly, err := parseDateParam(v)
+		if err != nil {
+			return f, apierror.InvalidParam("to").With("format", "RFC3339 or YYYY-MM-DD")
+		}
+		if dateOnly {
+			t = t.AddDate(0, 0, 1)
+		}
+		f.To = &t
+	}
+	if v := q.Get("min_total"); v != "" {
+		minTotal, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return f, apierror.InvalidParam("min_total")
+		}
+		f.MinTotal = &minTotal
+	}
+	if v := q.Get("max_total"); v != "" {
+		maxTotal, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return f, apierror.InvalidParam("max_total")
+		}
+		f.MaxTotal = &maxTotal
+	}
+	return f, nil
+}
+
+func parseDateParam(v string) (time.Time, bool, error) {
+	if t, err := time.Parse("2006-01-02", v); err == nil {
+		return t, true, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	return t, false, err
+}
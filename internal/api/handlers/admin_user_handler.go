@@ -0,0 +1,299 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"richisntreal-backend/internal/api/apierror"
+	"richisntreal-backend/internal/api/middleware"
+	"richisntreal-backend/internal/api/validate"
+	"richisntreal-backend/internal/core/domain/models"
+	"richisntreal-backend/internal/core/services"
+)
+
+// AdminUserHandler wires the support endpoints for looking after customers.
+type AdminUserHandler struct {
+	adminUserService *services.AdminUserService
+	orderService     *services.OrderService
+	cartService      *services.CartService
+}
+
+// NewAdminUserHandler constructs a new AdminUserHandler.
+func NewAdminUserHandler(
+	adminUserService *services.AdminUserService,
+	orderService *services.OrderService,
+	cartService *services.CartService,
+) *AdminUserHandler {
+	return &AdminUserHandler{
+		adminUserService: adminUserService,
+		orderService:     orderService,
+		cartService:      cartService,
+	}
+}
+
+type adminUserListResponse struct {
+	Users   []*models.User `json:"users"`
+	Page    int            `json:"page"`
+	PerPage int            `json:"per_page"`
+	Total   int            `json:"total"`
+}
+
+type auditLogResponse struct {
+	Entries []*models.AuditEntry `json:"entries"`
+	Page    int                  `json:"page"`
+	PerPage int                  `json:"per_page"`
+	Total   int                  `json:"total"`
+}
+
+type impersonateRequest struct {
+	Reason string `json:"reason"`
+}
+
+func (req impersonateRequest) Validate() error {
+	var v validate.Errors
+	v.MaxLength("reason", req.Reason, 255)
+	return v.Err()
+}
+
+type impersonationResponse struct {
+	AccessToken string `json:"token"`
+	ExpiresIn   int64  `json:"expires_in"`
+	UserID      int64  `json:"user_id"`
+}
+
+// ListUsers searches users. Query parameters: q (email, username or name),
+// role, disabled (true/false), page and per_page.
+func (h *AdminUserHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	filter := models.UserFilter{Query: q.Get("q"), Role: q.Get("role")}
+	if v := q.Get("disabled"); v != "" {
+		disabled, err := strconv.ParseBool(v)
+		if err != nil {
+			apierror.Write(w, r, apierror.InvalidParam("disabled"))
+			return
+		}
+		filter.Disabled = &disabled
+	}
+	page, perPage, err := parsePagination(q)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	filter.Limit = perPage
+	filter.Offset = (page - 1) * perPage
+
+	users, total, err := h.adminUserService.SearchUsers(r.Context(), filter)
+	if err != nil {
+		serverError(w, r, "could not fetch users", err)
+		return
+	}
+	if users == nil {
+		users = []*models.User{}
+	}
+	err = json.NewEncoder(w).Encode(adminUserListResponse{
+		Users:   users,
+		Page:    page,
+		PerPage: perPage,
+		Total:   total,
+	})
+	if err != nil {
+		return
+	}
+}
+
+// GetUser returns any user's profile.
+func (h *AdminUserHandler) GetUser(w http.ResponseWriter, r *http.Request) {
+	userID, ok := adminTargetUserID(w, r)
+	if !ok {
+		return
+	}
+	user, err := h.adminUserService.GetUser(r.Context(), userID)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	err = json.NewEncoder(w).Encode(user)
+	if err != nil {
+		return
+	}
+}
+
+// ListUserOrders returns a page of a user's orders, newest first. It takes
+// the same filters and paging as ListOrders, less user_id.
+func (h *AdminUserHandler) ListUserOrders(w http.ResponseWriter, r *http.Request) {
+	userID, ok := adminTargetUserID(w, r)
+	if !ok {
+		return
+	}
+	filter, err := parseOrderFilter(r.URL.Query())
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	page, perPage, err := parsePagination(r.URL.Query())
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	filter.UserID = userID
+	filter.Limit = perPage
+	filter.Offset = (page - 1) * perPage
+
+	if _, err := h.adminUserService.GetUser(r.Context(), userID); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	orders, total, err := h.orderService.SearchOrders(r.Context(), filter)
+	if err != nil {
+		serverError(w, r, "could not fetch orders", err)
+		return
+	}
+	if orders == nil {
+		orders = []*models.Order{}
+	}
+	err = json.NewEncoder(w).Encode(adminOrderListResponse{
+		Orders:  orders,
+		Page:    page,
+		PerPage: perPage,
+		Total:   total,
+	})
+	if err != nil {
+		return
+	}
+}
+
+// GetUserCart returns a user's cart as it stands.
+func (h *AdminUserHandler) GetUserCart(w http.ResponseWriter, r *http.Request) {
+	userID, ok := adminTargetUserID(w, r)
+	if !ok {
+		return
+	}
+	if _, err := h.adminUserService.GetUser(r.Context(), userID); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	cart, err := h.cartService.FindCart(r.Context(), userID)
+	if err != nil {
+		serverError(w, r, "could not fetch cart", err)
+		return
+	}
+	err = json.NewEncoder(w).Encode(cart)
+	if err != nil {
+		return
+	}
+}
+
+// DisableUser blocks a user from logging in and ends their sessions.
+func (h *AdminUserHandler) DisableUser(w http.ResponseWriter, r *http.Request) {
+	userID, ok := adminTargetUserID(w, r)
+	if !ok {
+		return
+	}
+	user, err := h.adminUserService.DisableUser(r.Context(), middleware.FromContext(r.Context()), userID)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	err = json.NewEncoder(w).Encode(user)
+	if err != nil {
+		return
+	}
+}
+
+// EnableUser lets a disabled user log in again.
+func (h *AdminUserHandler) EnableUser(w http.ResponseWriter, r *http.Request) {
+	userID, ok := adminTargetUserID(w, r)
+	if !ok {
+		return
+	}
+	user, err := h.adminUserService.EnableUser(r.Context(), middleware.FromContext(r.Context()), userID)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	err = json.NewEncoder(w).Encode(user)
+	if err != nil {
+		return
+	}
+}
+
+// Impersonate issues a short-lived token for acting as the user. The
+// optional reason ends up in the audit log.
+func (h *AdminUserHandler) Impersonate(w http.ResponseWriter, r *http.Request) {
+	userID, ok := adminTargetUserID(w, r)
+	if !ok {
+		return
+	}
+	var req impersonateRequest
+	if err := decodeOptionalJSON(w, r, &req); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	tokens, err := h.adminUserService.Impersonate(r.Context(), middleware.FromContext(r.Context()), userID, req.Reason)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+	err = json.NewEncoder(w).Encode(impersonationResponse{
+		AccessToken: tokens.AccessToken,
+		ExpiresIn:   tokens.ExpiresIn,
+		UserID:      userID,
+	})
+	if err != nil {
+		return
+	}
+}
+
+// AuditLog pages through the admin audit log. Query parameters: admin_id,
+// user_id, page and per_page.
+func (h *AdminUserHandler) AuditLog(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	var filter models.AuditFilter
+	for param, dst := range map[string]*int64{"admin_id": &filter.AdminID, "user_id": &filter.UserID} {
+		if v := q.Get(param); v != "" {
+			id, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				apierror.Write(w, r, apierror.InvalidParam(param))
+				return
+			}
+			*dst = id
+		}
+	}
+	page, perPage, err := parsePagination(q)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	filter.Limit = perPage
+	filter.Offset = (page - 1) * perPage
+
+	entries, total, err := h.adminUserService.AuditLog(r.Context(), filter)
+	if err != nil {
+		serverError(w, r, "could not fetch audit log", err)
+		return
+	}
+	if entries == nil {
+		entries = []*models.AuditEntry{}
+	}
+	err = json.NewEncoder(w).Encode(auditLogResponse{
+		Entries: entries,
+		Page:    page,
+		PerPage: perPage,
+		Total:   total,
+	})
+	if err != nil {
+		return
+	}
+}
+
+func adminTargetUserID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		apierror.Write(w, r, apierror.InvalidParam("id"))
+		return 0, false
+	}
+	return id, true
+}
@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"richisntreal-backend/internal/api/apierror"
+	"richisntreal-backend/internal/api/middleware"
+	"richisntreal-backend/internal/api/validate"
+	"richisntreal-backend/internal/core/domain/models"
+	"richisntreal-backend/internal/core/services"
+)
+
+// APIKeyHandler wires the endpoints users manage their API keys with.
+type APIKeyHandler struct {
+	apiKeyService *services.APIKeyService
+}
+
+// NewAPIKeyHandler constructs a new APIKeyHandler.
+func NewAPIKeyHandler(apiKeyService *services.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{apiKeyService: apiKeyService}
+}
+
+type createAPIKeyRequest struct {
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+func (req createAPIKeyRequest) Validate() error {
+	var v validate.Errors
+	v.Required("name", req.Name)
+	v.MaxLength("name", req.Name, 100)
+	v.Check(len(req.Scopes) > 0, "scopes", "is required")
+	v.Check(req.ExpiresAt == nil || req.ExpiresAt.After(time.Now()), "expires_at", "must be in the future")
+	return v.Err()
+}
+
+type createAPIKeyResponse struct {
+	*models.APIKey
+	Key string `json:"key"` // shown once
+}
+
+// Create issues a key for the caller. The response is the only time the
+// key itself is shown.
+func (h *APIKeyHandler) Create(w http.ResponseWriter, r *http.Request) {
+	caller := middleware.FromContext(r.Context())
+	if caller == 0 {
+		apierror.Write(w, r, apierror.ErrUnauthorized)
+		return
+	}
+	var req createAPIKeyRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	if req.Name == "" {
+		apierror.Write(w, r, apierror.Invalid("name is required"))
+		return
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		apierror.Write(w, r, apierror.Invalid("expires_at must be in the future"))
+		return
+	}
+
+	key, raw, err := h.apiKeyService.Create(r.Context(), caller, req.Name, req.Scopes, req.ExpiresAt)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+	err = json.NewEncoder(w).Encode(createAPIKeyResponse{APIKey: key, Key: raw})
+	if err != nil {
+		return
+	}
+}
+
+// List returns the caller's keys, without the keys themselves.
+func (h *APIKeyHandler) List(w http.ResponseWriter, r *http.Request) {
+	caller := middleware.FromContext(r.Context())
+	if caller == 0 {
+		apierror.Write(w, r, apierror.ErrUnauthorized)
+		return
+	}
+	keys, err := h.apiKeyService.List(r.Context(), caller)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	if keys == nil {
+		keys = []*models.APIKey{}
+	}
+	err = json.NewEncoder(w).Encode(keys)
+	if err != nil {
+		return
+	}
+}
+
+// Revoke disables one of the caller's keys.
+func (h *APIKeyHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	caller := middleware.FromContext(r.Context())
+	if caller == 0 {
+		apierror.Write(w, r, apierror.ErrUnauthorized)
+		return
+	}
+	keyID, err := strconv.ParseInt(chi.URLParam(r, "keyID"), 10, 64)
+	if err != nil {
+		apierror.Write(w, r, apierror.InvalidParam("keyID"))
+		return
+	}
+	if err := h.apiKeyService.Revoke(r.Context(), caller, keyID); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
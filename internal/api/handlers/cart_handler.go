@@ -6,7 +6,9 @@ import (
 	"strconv"
 
 	"github.com/go-chi/chi/v5"
+	"richisntreal-backend/internal/api/apierror"
 	"richisntreal-backend/internal/api/middleware"
+	"richisntreal-backend/internal/api/validate"
 	"richisntreal-backend/internal/core/services"
 )
 
@@ -22,23 +24,23 @@ func (h *CartHandler) GetCart(w http.ResponseWriter, r *http.Request) {
 	// 1) Authenticate & authorize
 	caller := middleware.FromContext(r.Context())
 	if caller == 0 {
-		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		apierror.Write(w, r, apierror.ErrUnauthorized)
 		return
 	}
 	userID, err := strconv.ParseInt(chi.URLParam(r, "userID"), 10, 64)
 	if err != nil {
-		http.Error(w, "invalid user id", http.StatusBadRequest)
+		apierror.Write(w, r, apierror.InvalidParam("userID"))
 		return
 	}
 	if caller != userID {
-		http.Error(w, "forbidden", http.StatusForbidden)
+		apierror.Write(w, r, apierror.ErrForbidden)
 		return
 	}
 
 	// 2) Fetch cart
-	cart, err := h.cartService.GetCart(userID)
+	cart, err := h.cartService.GetCart(r.Context(), userID)
 	if err != nil {
-		http.Error(w, "could not fetch cart", http.StatusInternalServerError)
+		serverError(w, r, "could not fetch cart", err)
 		return
 	}
 
@@ -55,30 +57,38 @@ type addItemReq struct {
 	UnitPrice float64 `json:"unit_price"`
 }
 
+func (req addItemReq) Validate() error {
+	var v validate.Errors
+	v.Positive("product_id", req.ProductID)
+	v.Positive("quantity", int64(req.Quantity))
+	v.NotNegative("unit_price", req.UnitPrice)
+	return v.Err()
+}
+
 func (h *CartHandler) AddItem(w http.ResponseWriter, r *http.Request) {
 	caller := middleware.FromContext(r.Context())
 	if caller == 0 {
-		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		apierror.Write(w, r, apierror.ErrUnauthorized)
 		return
 	}
 	userID, err := strconv.ParseInt(chi.URLParam(r, "userID"), 10, 64)
 	if err != nil {
-		http.Error(w, "invalid user id", http.StatusBadRequest)
+		apierror.Write(w, r, apierror.InvalidParam("userID"))
 		return
 	}
 	if caller != userID {
-		http.Error(w, "forbidden", http.StatusForbidden)
+		apierror.Write(w, r, apierror.ErrForbidden)
 		return
 	}
 
 	var req addItemReq
-	if err = json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "invalid payload", http.StatusBadRequest)
+	if err = decodeJSON(w, r, &req); err != nil {
+		apierror.Write(w, r, err)
 		return
 	}
-	item, err := h.cartService.AddItem(userID, req.ProductID, req.Quantity, req.UnitPrice)
+	item, err := h.cartService.AddItem(r.Context(), userID, req.ProductID, req.Quantity, req.UnitPrice)
 	if err != nil {
-		http.Error(w, "could not add item", http.StatusInternalServerError)
+		serverError(w, r, "could not add item", err)
 		return
 	}
 	w.WriteHeader(http.StatusCreated)
@@ -92,35 +102,42 @@ type updateItemReq struct {
 	Quantity int `json:"quantity"`
 }
 
+// Validate rejects a zero quantity too; removing an item has its own endpoint.
+func (req updateItemReq) Validate() error {
+	var v validate.Errors
+	v.Positive("quantity", int64(req.Quantity))
+	return v.Err()
+}
+
 func (h *CartHandler) UpdateItem(w http.ResponseWriter, r *http.Request) {
 	caller := middleware.FromContext(r.Context())
 	if caller == 0 {
-		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		apierror.Write(w, r, apierror.ErrUnauthorized)
 		return
 	}
 	userID, err := strconv.ParseInt(chi.URLParam(r, "userID"), 10, 64)
 	if err != nil {
-		http.Error(w, "invalid user id", http.StatusBadRequest)
+		apierror.Write(w, r, apierror.InvalidParam("userID"))
 		return
 	}
 	if caller != userID {
-		http.Error(w, "forbidden", http.StatusForbidden)
+		apierror.Write(w, r, apierror.ErrForbidden)
 		return
 	}
 
 	itemID, err := strconv.ParseInt(chi.URLParam(r, "itemID"), 10, 64)
 	if err != nil {
-		http.Error(w, "invalid item id", http.StatusBadRequest)
+		apierror.Write(w, r, apierror.InvalidParam("itemID"))
 		return
 	}
 	var req updateItemReq
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "invalid payload", http.StatusBadRequest)
+	if err := decodeJSON(w, r, &req); err != nil {
+		apierror.Write(w, r, err)
 		return
 	}
-	item, err := h.cartService.UpdateItem(itemID, req.Quantity)
+	item, err := h.cartService.UpdateItem(r.Context(), itemID, req.Quantity)
 	if err != nil {
-		http.Error(w, "could not update item", http.StatusInternalServerError)
+		apierror.Write(w, r, err)
 		return
 	}
 	err = json.NewEncoder(w).Encode(item)
@@ -132,26 +149,26 @@ func (h *CartHandler) UpdateItem(w http.ResponseWriter, r *http.Request) {
 func (h *CartHandler) RemoveItem(w http.ResponseWriter, r *http.Request) {
 	caller := middleware.FromContext(r.Context())
 	if caller == 0 {
-		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		apierror.Write(w, r, apierror.ErrUnauthorized)
 		return
 	}
 	userID, err := strconv.ParseInt(chi.URLParam(r, "userID"), 10, 64)
 	if err != nil {
-		http.Error(w, "invalid user id", http.StatusBadRequest)
+		apierror.Write(w, r, apierror.InvalidParam("userID"))
 		return
 	}
 	if caller != userID {
-		http.Error(w, "forbidden", http.StatusForbidden)
+		apierror.Write(w, r, apierror.ErrForbidden)
 		return
 	}
 
 	itemID, err := strconv.ParseInt(chi.URLParam(r, "itemID"), 10, 64)
 	if err != nil {
-		http.Error(w, "invalid item id", http.StatusBadRequest)
+		apierror.Write(w, r, apierror.InvalidParam("itemID"))
 		return
 	}
-	if err = h.cartService.RemoveItem(itemID); err != nil {
-		http.Error(w, "could not remove item", http.StatusInternalServerError)
+	if err = h.cartService.RemoveItem(r.Context(), itemID); err != nil {
+		apierror.Write(w, r, err)
 		return
 	}
 	w.WriteHeader(http.StatusNoContent)
@@ -160,21 +177,21 @@ func (h *CartHandler) RemoveItem(w http.ResponseWriter, r *http.Request) {
 func (h *CartHandler) ClearCart(w http.ResponseWriter, r *http.Request) {
 	caller := middleware.FromContext(r.Context())
 	if caller == 0 {
-		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		apierror.Write(w, r, apierror.ErrUnauthorized)
 		return
 	}
 	userID, err := strconv.ParseInt(chi.URLParam(r, "userID"), 10, 64)
 	if err != nil {
-		http.Error(w, "invalid user id", http.StatusBadRequest)
+		apierror.Write(w, r, apierror.InvalidParam("userID"))
 		return
 	}
 	if caller != userID {
-		http.Error(w, "forbidden", http.StatusForbidden)
+		apierror.Write(w, r, apierror.ErrForbidden)
 		return
 	}
 
-	if err = h.cartService.ClearCart(userID); err != nil {
-		http.Error(w, "could not clear cart", http.StatusInternalServerError)
+	if err = h.cartService.ClearCart(r.Context(), userID); err != nil {
+		apierror.Write(w, r, err)
 		return
 	}
 	w.WriteHeader(http.StatusNoContent)
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"richisntreal-backend/internal/api/apierror"
+	"richisntreal-backend/internal/api/validate"
+	"richisntreal-backend/internal/core/services"
+)
+
+// maxBodyBytes caps JSON request bodies; the largest legitimate payload, an
+// order with a full address, is a few hundred bytes.
+const maxBodyBytes = 1 << 20
+
+var errPayloadTooLarge = apierror.New(http.StatusRequestEntityTooLarge, "payload_too_large", "request body is too large")
+
+// decodeJSON reads r's body into dst and validates it. Unknown fields,
+// trailing data and bodies over maxBodyBytes are rejected. The returned
+// error is ready for apierror.Write.
+func decodeJSON(w http.ResponseWriter, r *http.Request, dst any) error {
+	return decode(w, r, dst, false)
+}
+
+// decodeOptionalJSON is decodeJSON for endpoints whose body may be left
+// out; an empty body leaves dst at its zero value, which is still validated.
+func decodeOptionalJSON(w http.ResponseWriter, r *http.Request, dst any) error {
+	return decode(w, r, dst, true)
+}
+
+func decode(w http.ResponseWriter, r *http.Request, dst any, optional bool) error {
+	// 1) Parse strictly
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+	dec.DisallowUnknownFields()
+	err := dec.Decode(dst)
+	if errors.Is(err, io.EOF) && optional {
+		err = nil
+	} else if err == nil {
+		// anything after the first value means the client sent something else
+		if _, terr := dec.Token(); !errors.Is(terr, io.EOF) {
+			err = errors.New("trailing data after JSON value")
+		}
+	}
+	if err != nil {
+		return decodeError(err)
+	}
+
+	// 2) Check the fields
+	if v, ok := dst.(validate.Validator); ok {
+		return v.Validate()
+	}
+	return nil
+}
+
+// decodeError turns a JSON decoding error into what the client is told.
+func decodeError(err error) error {
+	var maxErr *http.MaxBytesError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &maxErr):
+		return errPayloadTooLarge.With("limit", maxErr.Limit)
+	case errors.As(err, &typeErr):
+		return apierror.ErrInvalidPayload.With("field", typeErr.Field).With("expected", typeErr.Type.String())
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		// encoding/json has no typed error for this one
+		field := strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`)
+		return apierror.ErrInvalidPayload.With("field", field).With("reason", "unknown field")
+	default:
+		return apierror.ErrInvalidPayload
+	}
+}
+
+// maxPasswordBytes is as much of a password as bcrypt looks at.
+const maxPasswordBytes = 72
+
+// checkNewPassword applies the password rules to a password being set.
+func checkNewPassword(v *validate.Errors, field, password string) {
+	v.Required(field, password)
+	v.MinLength(field, password, services.MinPasswordLength)
+	v.Check(len(password) <= maxPasswordBytes, field, "is too long")
+}
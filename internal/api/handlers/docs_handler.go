@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"embed"
+	"encoding/json"
+	"io/fs"
+	"net/http"
+
+	"richisntreal-backend/internal/api/apierror"
+	"richisntreal-backend/internal/api/openapi"
+)
+
+// swaggerUI holds the docs UI assets, served from this binary rather than
+// a CDN so the page runs no third-party code. They are not checked in:
+// "make swagger-ui" fetches the pinned release, and the Docker build does
+// the same.
+//
+//go:embed all:swaggerui
+var swaggerUI embed.FS
+
+// swaggerUIAssets are the files the docs page loads, by content type.
+var swaggerUIAssets = map[string]string{
+	"swagger-ui.css":       "text/css; charset=utf-8",
+	"swagger-ui-bundle.js": "text/javascript; charset=utf-8",
+}
+
+const docsPage = `<!doctype html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>API docs</title>
+  <link rel="stylesheet" href="/docs/swagger-ui.css">
+</head>
+<body>
+  <div id="docs"></div>
+  <script src="/docs/swagger-ui-bundle.js"></script>
+  <script>
+    window.ui = SwaggerUIBundle({ url: "/openapi.json", dom_id: "#docs" });
+  </script>
+</body>
+</html>
+`
+
+// docsPageWithoutUI stands in when the binary was built without the assets.
+const docsPageWithoutUI = `<!doctype html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>API docs</title>
+</head>
+<body>
+  <p>This build does not include the docs UI; run <code>make swagger-ui</code> and rebuild.
+  The API is described in <a href="/openapi.json">/openapi.json</a>.</p>
+</body>
+</html>
+`
+
+// DocsHandler serves the OpenAPI document and a browsable UI for it.
+type DocsHandler struct {
+	doc    *openapi.Document
+	spec   []byte
+	assets map[string][]byte // nil when the UI wasn't bundled
+}
+
+// NewDocsHandler constructs a new DocsHandler, building the document once.
+func NewDocsHandler(version string) (*DocsHandler, error) {
+	doc := apiDocument(version)
+	spec, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	return &DocsHandler{doc: doc, spec: spec, assets: loadSwaggerUI()}, nil
+}
+
+// loadSwaggerUI reads the bundled assets, or returns nil if any is missing.
+func loadSwaggerUI() map[string][]byte {
+	assets := make(map[string][]byte, len(swaggerUIAssets))
+	for name := range swaggerUIAssets {
+		b, err := fs.ReadFile(swaggerUI, "swaggerui/"+name)
+		if err != nil {
+			return nil
+		}
+		assets[name] = b
+	}
+	return assets
+}
+
+// Document is the OpenAPI document being served.
+func (h *DocsHandler) Document() *openapi.Document {
+	return h.doc
+}
+
+// OpenAPI serves the OpenAPI document.
+func (h *DocsHandler) OpenAPI(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	w.Write(h.spec)
+}
+
+// UI serves the docs page, which renders /openapi.json.
+func (h *DocsHandler) UI(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if h.assets == nil {
+		w.Write([]byte(docsPageWithoutUI))
+		return
+	}
+	w.Write([]byte(docsPage))
+}
+
+// Asset serves one of the docs UI's bundled files, named by the last path
+// segment.
+func (h *DocsHandler) Asset(name string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		b, ok := h.assets[name]
+		if !ok {
+			apierror.Write(w, r, apierror.ErrNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", swaggerUIAssets[name])
+		w.Header().Set("Cache-Control", "public, max-age=86400")
+		w.Write(b)
+	}
+}
@@ -0,0 +1,15 @@
+package handlers
+
+import (
+	"net/http"
+
+	"richisntreal-backend/internal/api/apierror"
+	"richisntreal-backend/internal/infrastructure/logging"
+)
+
+// serverError answers 500 and logs msg with the cause, which the client
+// never sees, against the request's logger.
+func serverError(w http.ResponseWriter, r *http.Request, msg string, err error) {
+	logging.FromContext(r.Context()).Error(msg, "error", err)
+	apierror.Write(w, r, apierror.ErrInternal)
+}
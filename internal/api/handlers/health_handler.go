@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"richisntreal-backend/internal/infrastructure/logging"
+)
+
+// readinessCheckTimeout bounds each dependency check, so a hung database
+// fails the probe instead of hanging it.
+const readinessCheckTimeout = 2 * time.Second
+
+// HealthCheck is a dependency the service can't serve traffic without.
+type HealthCheck interface {
+	Name() string
+	Check(ctx context.Context) error
+}
+
+// HealthHandler answers the orchestrator's liveness and readiness probes.
+type HealthHandler struct {
+	shutdown context.Context
+	checks   []HealthCheck
+}
+
+// NewHealthHandler constructs a new HealthHandler. Once shutdown is done
+// the instance reports not ready, so load balancers stop sending traffic
+// while in-flight requests drain.
+func NewHealthHandler(shutdown context.Context, checks ...HealthCheck) *HealthHandler {
+	return &HealthHandler{shutdown: shutdown, checks: checks}
+}
+
+// readinessResponse names the failing checks but not why they failed: the
+// probe is unauthenticated, and the reason is in the log.
+type readinessResponse struct {
+	Status  string   `json:"status"`
+	Failing []string `json:"failing,omitempty"`
+}
+
+// Live reports that the process is up and serving; it checks nothing else,
+// so a database outage doesn't get every instance restarted.
+func (h *HealthHandler) Live(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
+	w.Write([]byte("ok\n"))
+}
+
+// Ready runs every check and answers 503 if any of them fails.
+func (h *HealthHandler) Ready(w http.ResponseWriter, r *http.Request) {
+	resp := readinessResponse{Status: "ok"}
+	status := http.StatusOK
+
+	if h.shutdown.Err() != nil {
+		resp.Status = "shutting down"
+		status = http.StatusServiceUnavailable
+	} else {
+		for _, c := range h.checks {
+			ctx, cancel := context.WithTimeout(r.Context(), readinessCheckTimeout)
+			err := c.Check(ctx)
+			cancel()
+			if err != nil {
+				logging.FromContext(r.Context()).Warn("readiness check failed", "check", c.Name(), "error", err)
+				resp.Failing = append(resp.Failing, c.Name())
+				resp.Status = "unavailable"
+				status = http.StatusServiceUnavailable
+			}
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"richisntreal-backend/internal/api/apierror"
+	"richisntreal-backend/internal/api/middleware"
+	"richisntreal-backend/internal/core/services"
+)
+
+// InvoiceHandler serves invoices for paid orders.
+type InvoiceHandler struct {
+	invoiceService *services.InvoiceService
+	orderService   *services.OrderService
+}
+
+// NewInvoiceHandler constructs a new InvoiceHandler.
+func NewInvoiceHandler(invoiceService *services.InvoiceService, orderService *services.OrderService) *InvoiceHandler {
+	return &InvoiceHandler{invoiceService: invoiceService, orderService: orderService}
+}
+
+// GetInvoicePDF renders the invoice of an order; only its owner or an admin may fetch it.
+func (h *InvoiceHandler) GetInvoicePDF(w http.ResponseWriter, r *http.Request) {
+	caller := middleware.FromContext(r.Context())
+	if caller == 0 {
+		apierror.Write(w, r, apierror.ErrUnauthorized)
+		return
+	}
+
+	orderID, err := strconv.ParseInt(chi.URLParam(r, "orderID"), 10, 64)
+	if err != nil {
+		apierror.Write(w, r, apierror.InvalidParam("orderID"))
+		return
+	}
+
+	// 1) enforce ownership unless staff
+	ord, err := h.orderService.GetOrderByID(r.Context(), orderID)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	if ord.UserID != caller && !middleware.IsAdmin(r.Context()) {
+		apierror.Write(w, r, apierror.ErrForbidden)
+		return
+	}
+
+	// 2) render
+	pdf, inv, err := h.invoiceService.RenderInvoicePDF(r.Context(), orderID)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", `inline; filename="`+inv.Reference()+`.pdf"`)
+	_, err = w.Write(pdf)
+	if err != nil {
+		return
+	}
+}
@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"richisntreal-backend/internal/api/auth"
+)
+
+// JWKSHandler publishes the public keys our access tokens can be verified with.
+type JWKSHandler struct {
+	keys *auth.KeySet
+}
+
+// NewJWKSHandler constructs a new JWKSHandler.
+func NewJWKSHandler(keys *auth.KeySet) *JWKSHandler {
+	return &JWKSHandler{keys: keys}
+}
+
+// GetJWKS serves the key set so other services can verify our tokens without sharing a secret.
+func (h *JWKSHandler) GetJWKS(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	err := json.NewEncoder(w).Encode(h.keys.JWKS())
+	if err != nil {
+		return
+	}
+}
@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"richisntreal-backend/internal/api/apierror"
+	"richisntreal-backend/internal/core/services"
+)
+
+// OIDCHandler wires the social login endpoints.
+type OIDCHandler struct {
+	oidcService *services.OIDCService
+}
+
+// NewOIDCHandler constructs a new OIDCHandler.
+func NewOIDCHandler(oidcService *services.OIDCService) *OIDCHandler {
+	return &OIDCHandler{oidcService: oidcService}
+}
+
+type oidcProvidersResponse struct {
+	Providers []string `json:"providers"`
+}
+
+// ListProviders lists the providers users can sign in with.
+func (h *OIDCHandler) ListProviders(w http.ResponseWriter, r *http.Request) {
+	err := json.NewEncoder(w).Encode(oidcProvidersResponse{Providers: h.oidcService.Providers(r.Context())})
+	if err != nil {
+		return
+	}
+}
+
+// Authorize redirects the browser to the provider's login page.
+func (h *OIDCHandler) Authorize(w http.ResponseWriter, r *http.Request) {
+	authURL, err := h.oidcService.BeginLogin(r.Context(), chi.URLParam(r, "provider"))
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+// Callback completes the login with the code and state the provider sent
+// back, and answers like POST /login.
+func (h *OIDCHandler) Callback(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if e := q.Get("error"); e != "" {
+		apierror.Write(w, r, apierror.New(http.StatusBadRequest, "provider_error", "the identity provider refused the login").With("provider_error", e))
+		return
+	}
+	if q.Get("code") == "" || q.Get("state") == "" {
+		apierror.Write(w, r, apierror.Invalid("code and state are required"))
+		return
+	}
+
+	result, err := h.oidcService.CompleteLogin(r.Context(), chi.URLParam(r, "provider"), q.Get("state"), q.Get("code"))
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	writeLoginResult(w, result)
+}
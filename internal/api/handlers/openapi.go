@@ -0,0 +1,422 @@
+package handlers
+
+import (
+	"net/http"
+
+	"richisntreal-backend/internal/api/auth"
+	"richisntreal-backend/internal/api/openapi"
+	"richisntreal-backend/internal/core/domain/models"
+)
+
+// Security schemes, as named in the document.
+const (
+	bearerAuth = "bearer"
+	apiKeyAuth = "apiKey"
+)
+
+// apiDocument describes every route in internal/api/routes. Paths are the
+// chi patterns exactly as registered, trailing slashes included; bootstrap
+// refuses to start when a registered route is missing here.
+func apiDocument(version string) *openapi.Document {
+	b := openapi.NewBuilder(openapi.Info{
+		Title:   "richisntreal API",
+		Version: version,
+		Description: "Errors are RFC 7807 problem documents; quote request_id when reporting one. " +
+			"Payloads with unknown fields are rejected.",
+	})
+	b.SecurityScheme(bearerAuth, openapi.SecurityScheme{
+		Type:         "http",
+		Scheme:       "bearer",
+		BearerFormat: "JWT",
+		Description:  "Access token from POST /login or POST /token/refresh.",
+	})
+	b.SecurityScheme(apiKeyAuth, openapi.SecurityScheme{
+		Type:        "apiKey",
+		In:          "header",
+		Name:        auth.APIKeyHeader,
+		Description: "Key from POST /api-keys, limited to the scopes it was issued with.",
+	})
+
+	// 1) Operations
+	b.Tag("ops", "Probes, metrics and these docs.")
+	b.Op(http.MethodGet, "/healthz", "ops", "Liveness probe").
+		Content(http.StatusOK, "The process is up.", "text/plain", &openapi.Schema{Type: "string"})
+	b.Op(http.MethodGet, "/readyz", "ops", "Readiness probe").
+		Describe("Checks the database and schema; answers 503 while any check fails or the instance is draining.").
+		Returns(http.StatusOK, "Ready to serve traffic.", readinessResponse{}).
+		Returns(http.StatusServiceUnavailable, "Not ready.", readinessResponse{})
+	b.Op(http.MethodGet, "/metrics", "ops", "Prometheus metrics").
+		Content(http.StatusOK, "Metrics in the Prometheus text format.", "text/plain", &openapi.Schema{Type: "string"})
+	b.Op(http.MethodGet, "/openapi.json", "ops", "This document").
+		Content(http.StatusOK, "The OpenAPI document.", "application/json", &openapi.Schema{Type: "object"})
+	b.Op(http.MethodGet, "/docs", "ops", "Interactive API docs").
+		Content(http.StatusOK, "An HTML page rendering this document.", "text/html", &openapi.Schema{Type: "string"})
+	b.Op(http.MethodGet, "/docs/swagger-ui.css", "ops", "Docs UI stylesheet").
+		Content(http.StatusOK, "The bundled Swagger UI stylesheet.", "text/css", &openapi.Schema{Type: "string"}).
+		Errors(http.StatusNotFound)
+	b.Op(http.MethodGet, "/docs/swagger-ui-bundle.js", "ops", "Docs UI script").
+		Content(http.StatusOK, "The bundled Swagger UI script.", "text/javascript", &openapi.Schema{Type: "string"}).
+		Errors(http.StatusNotFound)
+	b.Op(http.MethodGet, "/.well-known/jwks.json", "ops", "Token verification keys").
+		Returns(http.StatusOK, "Every public key access tokens may be signed with.", auth.JWKS{})
+
+	// 2) Accounts and sessions
+	b.Tag("users", "Registration, login and the caller's own account.")
+	b.Op(http.MethodPost, "/users", "users", "Register an account").
+		Body(createUserRequest{}).
+		Returns(http.StatusCreated, "The account; a verification mail is on its way.", createUserResponse{}).
+		Errors(http.StatusBadRequest, http.StatusConflict, http.StatusUnprocessableEntity, http.StatusTooManyRequests)
+	b.Op(http.MethodPost, "/login", "users", "Log in with email and password").
+		Body(loginRequest{}).
+		Returns(http.StatusOK, "Tokens, or a challenge when two-factor authentication is on.", loginResponse{}, twoFactorChallengeResponse{}).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusUnprocessableEntity, http.StatusTooManyRequests)
+	b.Op(http.MethodPost, "/login/2fa", "users", "Complete a two-factor login challenge").
+		Body(twoFactorLoginRequest{}).
+		Returns(http.StatusOK, "Tokens for the new session.", loginResponse{}).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusUnprocessableEntity, http.StatusTooManyRequests)
+	b.Op(http.MethodGet, "/users/{id}", "users", "Get your account").
+		Auth(bearerAuth).
+		Returns(http.StatusOK, "The account.", userDetailResponse{}).
+		Errors(http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound)
+	b.Op(http.MethodPatch, "/users/{id}", "users", "Update your profile").
+		Describe("Only fields present in the body change.").
+		Auth(bearerAuth).
+		Body(updateUserRequest{}).
+		Returns(http.StatusOK, "The updated account.", userDetailResponse{}).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusUnprocessableEntity)
+	b.Op(http.MethodDelete, "/users/{id}", "users", "Delete your account").
+		Describe("Personal data is anonymised; orders are kept for accounting.").
+		Auth(bearerAuth).
+		Body(deleteUserRequest{}).
+		NoContent(http.StatusNoContent, "The account is gone.").
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusUnprocessableEntity)
+	b.Op(http.MethodPost, "/users/{id}/password", "users", "Change your password").
+		Describe("Every other session is logged out.").
+		Auth(bearerAuth).
+		Body(changePasswordRequest{}).
+		NoContent(http.StatusNoContent, "The password is changed.").
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusUnprocessableEntity)
+	b.Op(http.MethodPost, "/users/{id}/email", "users", "Change your email address").
+		Describe("Takes effect once the link mailed to the new address is followed.").
+		Auth(bearerAuth).
+		Body(changeEmailRequest{}).
+		NoContent(http.StatusAccepted, "A confirmation link was mailed.").
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusConflict, http.StatusUnprocessableEntity)
+	b.Op(http.MethodGet, "/users/{id}/export", "users", "Export your data").
+		Auth(bearerAuth).
+		Query("format", "string", `"zip" for a zip archive; JSON otherwise.`).
+		Returns(http.StatusOK, "Everything stored about you.", models.DataExport{}).
+		Errors(http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound)
+
+	b.Tag("sessions", "Token refresh and logout.")
+	b.Op(http.MethodPost, "/token/refresh", "sessions", "Exchange a refresh token").
+		Describe("Refresh tokens are single use; reusing one revokes the session.").
+		Body(refreshRequest{}).
+		Returns(http.StatusOK, "A new token pair.", models.TokenPair{}).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusUnprocessableEntity)
+	b.Op(http.MethodPost, "/logout", "sessions", "Log out this session").
+		Auth(bearerAuth).
+		NoContent(http.StatusNoContent, "The session is revoked.").
+		Errors(http.StatusUnauthorized)
+	b.Op(http.MethodPost, "/logout/all", "sessions", "Log out every session").
+		Auth(bearerAuth).
+		NoContent(http.StatusNoContent, "Every session is revoked.").
+		Errors(http.StatusUnauthorized, http.StatusForbidden)
+
+	b.Tag("account", "Password reset and email verification.")
+	b.Op(http.MethodPost, "/password/forgot", "account", "Request a password reset").
+		Describe("Always answers 202, so callers can't tell whether the address has an account.").
+		Body(forgotPasswordRequest{}).
+		NoContent(http.StatusAccepted, "A reset link was mailed if the account exists.").
+		Errors(http.StatusBadRequest, http.StatusUnprocessableEntity, http.StatusTooManyRequests)
+	b.Op(http.MethodPost, "/password/reset", "account", "Reset a password").
+		Body(resetPasswordRequest{}).
+		NoContent(http.StatusNoContent, "The password is changed.").
+		Errors(http.StatusBadRequest, http.StatusUnprocessableEntity, http.StatusTooManyRequests)
+	b.Op(http.MethodPost, "/email/verify", "account", "Verify an email address").
+		Body(verifyEmailRequest{}).
+		NoContent(http.StatusNoContent, "The address is verified.").
+		Errors(http.StatusBadRequest, http.StatusConflict, http.StatusUnprocessableEntity, http.StatusTooManyRequests)
+	b.Op(http.MethodPost, "/email/change/confirm", "account", "Confirm an email change").
+		Body(verifyEmailRequest{}).
+		NoContent(http.StatusNoContent, "The account uses the new address.").
+		Errors(http.StatusBadRequest, http.StatusConflict, http.StatusUnprocessableEntity, http.StatusTooManyRequests)
+	b.Op(http.MethodPost, "/email/verify/resend", "account", "Resend the verification mail").
+		Auth(bearerAuth).
+		NoContent(http.StatusAccepted, "A fresh link was mailed.").
+		Errors(http.StatusUnauthorized, http.StatusConflict)
+
+	b.Tag("2fa", "Two-factor authentication for the caller.")
+	b.Op(http.MethodGet, "/2fa/", "2fa", "Two-factor status").
+		Auth(bearerAuth).
+		Returns(http.StatusOK, "Whether two-factor authentication is on.", twoFactorStatusResponse{}).
+		Errors(http.StatusUnauthorized, http.StatusForbidden)
+	b.Op(http.MethodPost, "/2fa/enroll", "2fa", "Start enrolment").
+		Auth(bearerAuth).
+		Returns(http.StatusOK, "The secret and otpauth URI to scan.", models.TOTPEnrollment{}).
+		Errors(http.StatusUnauthorized, http.StatusForbidden, http.StatusConflict)
+	b.Op(http.MethodPost, "/2fa/confirm", "2fa", "Finish enrolment").
+		Auth(bearerAuth).
+		Body(twoFactorCodeRequest{}).
+		Returns(http.StatusOK, "Recovery codes, shown once.", recoveryCodesResponse{}).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusConflict, http.StatusUnprocessableEntity)
+	b.Op(http.MethodPost, "/2fa/recovery-codes", "2fa", "Replace the recovery codes").
+		Auth(bearerAuth).
+		Body(twoFactorCodeRequest{}).
+		Returns(http.StatusOK, "New recovery codes, shown once.", recoveryCodesResponse{}).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusConflict, http.StatusUnprocessableEntity)
+	b.Op(http.MethodPost, "/2fa/disable", "2fa", "Turn two-factor authentication off").
+		Auth(bearerAuth).
+		Body(twoFactorCodeRequest{}).
+		NoContent(http.StatusNoContent, "Two-factor authentication is off.").
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusConflict, http.StatusUnprocessableEntity)
+
+	b.Tag("api-keys", "Keys for server-to-server access.")
+	b.Op(http.MethodPost, "/api-keys/", "api-keys", "Issue an API key").
+		Auth(bearerAuth).
+		Body(createAPIKeyRequest{}).
+		Returns(http.StatusCreated, "The key; this is the only time it is shown.", createAPIKeyResponse{}).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusUnprocessableEntity)
+	b.Op(http.MethodGet, "/api-keys/", "api-keys", "List your API keys").
+		Auth(bearerAuth).
+		Returns(http.StatusOK, "Your keys, without their secrets.", []*models.APIKey{}).
+		Errors(http.StatusUnauthorized, http.StatusForbidden)
+	b.Op(http.MethodDelete, "/api-keys/{keyID}", "api-keys", "Revoke an API key").
+		Auth(bearerAuth).
+		NoContent(http.StatusNoContent, "The key no longer works.").
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound)
+
+	b.Tag("oidc", "Login through external identity providers.")
+	b.Op(http.MethodGet, "/auth/oidc/providers", "oidc", "List identity providers").
+		Returns(http.StatusOK, "The configured providers.", oidcProvidersResponse{})
+	b.Op(http.MethodGet, "/auth/oidc/{provider}/authorize", "oidc", "Start a provider login").
+		NoContent(http.StatusFound, "Redirect to the provider's login page.").
+		Errors(http.StatusNotFound, http.StatusTooManyRequests)
+	b.Op(http.MethodGet, "/auth/oidc/{provider}/callback", "oidc", "Finish a provider login").
+		Describe("The provider redirects here; answers like POST /login.").
+		Query("code", "string", "Authorization code from the provider.").
+		Query("state", "string", "State from the authorize redirect.").
+		Query("error", "string", "Set by the provider when it refused the login.").
+		Returns(http.StatusOK, "Tokens, or a challenge when two-factor authentication is on.", loginResponse{}, twoFactorChallengeResponse{}).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound, http.StatusTooManyRequests)
+
+	// 3) Shopping
+	b.Tag("products", "The catalog.")
+	b.Op(http.MethodGet, "/products", "products", "List products").
+		Returns(http.StatusOK, "Every product.", []*models.Product{})
+	b.Op(http.MethodGet, "/products/{id}", "products", "Get a product").
+		Returns(http.StatusOK, "The product.", models.Product{}).
+		Errors(http.StatusBadRequest, http.StatusNotFound)
+	b.Op(http.MethodPost, "/products", "products", "Create a product").
+		Auth(bearerAuth, apiKeyAuth).
+		Body(productRequest{}).
+		Returns(http.StatusCreated, "The product.", models.Product{}).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusUnprocessableEntity)
+	b.Op(http.MethodPut, "/products/{id}", "products", "Replace a product").
+		Auth(bearerAuth, apiKeyAuth).
+		Body(productRequest{}).
+		Returns(http.StatusOK, "The product.", models.Product{}).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusUnprocessableEntity)
+	b.Op(http.MethodDelete, "/products/{id}", "products", "Delete a product").
+		Auth(bearerAuth, apiKeyAuth).
+		NoContent(http.StatusNoContent, "The product is gone.").
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound)
+
+	b.Tag("cart", "The caller's shopping cart.")
+	b.Op(http.MethodGet, "/users/{userID}/cart/", "cart", "Get your cart").
+		Auth(bearerAuth).
+		Returns(http.StatusOK, "The cart and its items.", models.Cart{}).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden)
+	b.Op(http.MethodDelete, "/users/{userID}/cart/", "cart", "Empty your cart").
+		Auth(bearerAuth).
+		NoContent(http.StatusNoContent, "The cart is empty.").
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden)
+	b.Op(http.MethodPost, "/users/{userID}/cart/items", "cart", "Add an item").
+		Auth(bearerAuth).
+		Body(addItemReq{}).
+		Returns(http.StatusCreated, "The cart item.", models.CartItem{}).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusUnprocessableEntity)
+	b.Op(http.MethodPut, "/users/{userID}/cart/items/{itemID}", "cart", "Change an item's quantity").
+		Auth(bearerAuth).
+		Body(updateItemReq{}).
+		Returns(http.StatusOK, "The cart item.", models.CartItem{}).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusUnprocessableEntity)
+	b.Op(http.MethodDelete, "/users/{userID}/cart/items/{itemID}", "cart", "Remove an item").
+		Auth(bearerAuth).
+		NoContent(http.StatusNoContent, "The item is gone.").
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound)
+
+	b.Tag("orders", "Placing, paying and cancelling orders.")
+	b.Op(http.MethodPost, "/users/{userID}/orders/", "orders", "Place an order from your cart").
+		Auth(bearerAuth, apiKeyAuth).
+		OptionalBody(createOrderRequest{}).
+		Returns(http.StatusCreated, "The order.", createOrderResponse{}).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusConflict, http.StatusUnprocessableEntity)
+	b.Op(http.MethodGet, "/users/{userID}/orders/", "orders", "List your orders").
+		Auth(bearerAuth, apiKeyAuth).
+		Returns(http.StatusOK, "Your orders.", []*models.Order{}).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden)
+	b.Op(http.MethodGet, "/orders/{orderID}", "orders", "Get an order").
+		Auth(bearerAuth, apiKeyAuth).
+		Returns(http.StatusOK, "The order.", models.Order{}).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound)
+	b.Op(http.MethodPost, "/orders/{orderID}/cancel", "orders", "Cancel an order").
+		Describe("Voids or refunds the payment and releases the reserved stock.").
+		Auth(bearerAuth, apiKeyAuth).
+		Body(cancelOrderRequest{}).
+		Returns(http.StatusOK, "The cancelled order.", models.Order{}).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusConflict, http.StatusUnprocessableEntity, http.StatusBadGateway)
+	b.Op(http.MethodPost, "/orders/lookup", "orders", "Look up an order as a guest").
+		Body(lookupOrderRequest{}).
+		Returns(http.StatusOK, "The order, without internal details.", lookupOrderResponse{}).
+		Errors(http.StatusBadRequest, http.StatusNotFound, http.StatusUnprocessableEntity)
+	b.Op(http.MethodPost, "/orders/{orderID}/pay/", "orders", "Pay for an order").
+		Describe("Only pending orders can be paid; one payment per order may be in progress at a time.").
+		Auth(bearerAuth).
+		Body(paymentRequest{}).
+		Returns(http.StatusCreated, "The successful transaction.", models.PaymentTransaction{}).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusPaymentRequired, http.StatusForbidden, http.StatusNotFound, http.StatusConflict, http.StatusUnprocessableEntity, http.StatusBadGateway)
+	b.Op(http.MethodGet, "/orders/{orderID}/invoice.pdf", "orders", "Download an order's invoice").
+		Auth(bearerAuth, apiKeyAuth).
+		Content(http.StatusOK, "The invoice.", "application/pdf", &openapi.Schema{Type: "string", Format: "binary"}).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound)
+
+	b.Tag("returns", "Returning delivered items.")
+	b.Op(http.MethodPost, "/orders/{orderID}/returns/", "returns", "Open a return").
+		Auth(bearerAuth, apiKeyAuth).
+		Body(createReturnRequest{}).
+		Returns(http.StatusCreated, "The return.", models.ReturnRequest{}).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusConflict, http.StatusUnprocessableEntity)
+	b.Op(http.MethodGet, "/orders/{orderID}/returns/", "returns", "List an order's returns").
+		Auth(bearerAuth, apiKeyAuth).
+		Returns(http.StatusOK, "The order's returns.", []*models.ReturnRequest{}).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound)
+
+	// 4) Back office
+	b.Tag("admin", "Staff tools; admins only.")
+	b.Op(http.MethodGet, "/admin/orders/", "admin", "Search orders").
+		Auth(bearerAuth, apiKeyAuth).
+		Query("status", "string", "Order status.").
+		Query("user_id", "integer", "Owner.").
+		Query("from", "string", "RFC3339 or YYYY-MM-DD.").
+		Query("to", "string", "RFC3339 or YYYY-MM-DD; a bare date is inclusive.").
+		Query("min_total", "number", "Smallest total.").
+		Query("max_total", "number", "Largest total.").
+		Query("page", "integer", "Page, from 1.").
+		Query("per_page", "integer", "Page size.").
+		Returns(http.StatusOK, "A page of orders.", adminOrderListResponse{}).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden)
+	b.Op(http.MethodGet, "/admin/orders/export.csv", "admin", "Export orders as CSV").
+		Auth(bearerAuth, apiKeyAuth).
+		Query("status", "string", "Order status.").
+		Query("user_id", "integer", "Owner.").
+		Query("from", "string", "RFC3339 or YYYY-MM-DD.").
+		Query("to", "string", "RFC3339 or YYYY-MM-DD; a bare date is inclusive.").
+		Query("min_total", "number", "Smallest total.").
+		Query("max_total", "number", "Largest total.").
+		Content(http.StatusOK, "Every matching order.", "text/csv", &openapi.Schema{Type: "string"}).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden)
+	b.Op(http.MethodGet, "/admin/orders/{orderID}", "admin", "Get an order with its notes").
+		Auth(bearerAuth, apiKeyAuth).
+		Returns(http.StatusOK, "The order and staff notes.", adminOrderResponse{}).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound)
+	b.Op(http.MethodPost, "/admin/orders/{orderID}/notes", "admin", "Add a staff note").
+		Auth(bearerAuth, apiKeyAuth).
+		Body(addNoteRequest{}).
+		Returns(http.StatusCreated, "The note.", models.OrderNote{}).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusUnprocessableEntity)
+	b.Op(http.MethodPost, "/admin/orders/{orderID}/status", "admin", "Change an order's status").
+		Describe("Cancelling also voids or refunds the payment and releases stock.").
+		Auth(bearerAuth, apiKeyAuth).
+		Body(changeStatusRequest{}).
+		Returns(http.StatusOK, "The order.", models.Order{}).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusConflict, http.StatusUnprocessableEntity, http.StatusBadGateway)
+
+	b.Op(http.MethodGet, "/admin/returns/", "admin", "List returns").
+		Auth(bearerAuth, apiKeyAuth).
+		Query("status", "string", "Return status.").
+		Returns(http.StatusOK, "The returns.", []*models.ReturnRequest{}).
+		Errors(http.StatusUnauthorized, http.StatusForbidden)
+	b.Op(http.MethodGet, "/admin/returns/{returnID}", "admin", "Get a return").
+		Auth(bearerAuth, apiKeyAuth).
+		Returns(http.StatusOK, "The return.", models.ReturnRequest{}).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound)
+	b.Op(http.MethodPost, "/admin/returns/{returnID}/approve", "admin", "Approve a return").
+		Auth(bearerAuth, apiKeyAuth).
+		Body(reviewReturnRequest{}).
+		Returns(http.StatusOK, "The return.", models.ReturnRequest{}).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusConflict, http.StatusUnprocessableEntity)
+	b.Op(http.MethodPost, "/admin/returns/{returnID}/reject", "admin", "Reject a return").
+		Auth(bearerAuth, apiKeyAuth).
+		Body(reviewReturnRequest{}).
+		Returns(http.StatusOK, "The return.", models.ReturnRequest{}).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusConflict, http.StatusUnprocessableEntity)
+	b.Op(http.MethodPost, "/admin/returns/{returnID}/receive", "admin", "Mark a return received").
+		Describe("Refunds or restocks, as decided on approval.").
+		Auth(bearerAuth, apiKeyAuth).
+		Returns(http.StatusOK, "The return.", models.ReturnRequest{}).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusConflict, http.StatusBadGateway)
+
+	b.Op(http.MethodGet, "/admin/users", "admin", "Search users").
+		Auth(bearerAuth).
+		Query("q", "string", "Matches email, username or name.").
+		Query("role", "string", "Role.").
+		Query("disabled", "boolean", "Disabled or not.").
+		Query("page", "integer", "Page, from 1.").
+		Query("per_page", "integer", "Page size.").
+		Returns(http.StatusOK, "A page of users.", adminUserListResponse{}).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden)
+	b.Op(http.MethodGet, "/admin/users/{id}", "admin", "Get a user").
+		Auth(bearerAuth).
+		Returns(http.StatusOK, "The user.", models.User{}).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound)
+	b.Op(http.MethodGet, "/admin/users/{id}/orders", "admin", "List a user's orders").
+		Auth(bearerAuth).
+		Query("status", "string", "Order status.").
+		Query("from", "string", "RFC3339 or YYYY-MM-DD.").
+		Query("to", "string", "RFC3339 or YYYY-MM-DD; a bare date is inclusive.").
+		Query("min_total", "number", "Smallest total.").
+		Query("max_total", "number", "Largest total.").
+		Query("page", "integer", "Page, from 1.").
+		Query("per_page", "integer", "Page size.").
+		Returns(http.StatusOK, "A page of the user's orders.", adminOrderListResponse{}).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound)
+	b.Op(http.MethodGet, "/admin/users/{id}/cart", "admin", "Get a user's cart").
+		Auth(bearerAuth).
+		Returns(http.StatusOK, "The user's cart.", models.Cart{}).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound)
+	b.Op(http.MethodPost, "/admin/users/{id}/disable", "admin", "Disable a user").
+		Describe("Logs the user out everywhere; their API keys stop working too.").
+		Auth(bearerAuth).
+		Returns(http.StatusOK, "The user.", models.User{}).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound)
+	b.Op(http.MethodPost, "/admin/users/{id}/enable", "admin", "Enable a user").
+		Auth(bearerAuth).
+		Returns(http.StatusOK, "The user.", models.User{}).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound)
+	b.Op(http.MethodPost, "/admin/users/{id}/impersonate", "admin", "Act as a user").
+		Describe("Issues a short-lived token; everything done with it is audited.").
+		Auth(bearerAuth).
+		OptionalBody(impersonateRequest{}).
+		Returns(http.StatusCreated, "The impersonation token.", impersonationResponse{}).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusUnprocessableEntity)
+	b.Op(http.MethodGet, "/admin/users/{id}/export", "admin", "Export a user's data").
+		Auth(bearerAuth).
+		Query("format", "string", `"zip" for a zip archive; JSON otherwise.`).
+		Returns(http.StatusOK, "Everything stored about the user.", models.DataExport{}).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound)
+	b.Op(http.MethodPost, "/admin/users/{id}/erase", "admin", "Erase a user's personal data").
+		Auth(bearerAuth).
+		NoContent(http.StatusNoContent, "The personal data is gone.").
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound)
+	b.Op(http.MethodGet, "/admin/audit-log", "admin", "Page through the audit log").
+		Auth(bearerAuth).
+		Query("admin_id", "integer", "Acting admin.").
+		Query("user_id", "integer", "Affected user.").
+		Query("page", "integer", "Page, from 1.").
+		Query("per_page", "integer", "Page size.").
+		Returns(http.StatusOK, "A page of audit entries.", auditLogResponse{}).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden)
+
+	return b.Document()
+}
@@ -1,27 +1,78 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/go-chi/chi/v5"
+	"richisntreal-backend/internal/api/apierror"
 	"richisntreal-backend/internal/api/middleware"
+	"richisntreal-backend/internal/api/validate"
+	"richisntreal-backend/internal/core/domain/models"
 	"richisntreal-backend/internal/core/services"
+	"richisntreal-backend/internal/infrastructure/logging"
 )
 
 type OrderHandler struct {
-	orderService *services.OrderService
+	orderService   *services.OrderService
+	paymentService *services.PaymentService
 }
 
-func NewOrderHandler(orderService *services.OrderService) *OrderHandler {
-	return &OrderHandler{orderService: orderService}
+func NewOrderHandler(orderService *services.OrderService, paymentService *services.PaymentService) *OrderHandler {
+	return &OrderHandler{orderService: orderService, paymentService: paymentService}
+}
+
+type addressRequest struct {
+	Name       string `json:"name"`
+	Line1      string `json:"line1"`
+	Line2      string `json:"line2"`
+	City       string `json:"city"`
+	PostalCode string `json:"postal_code"`
+	Country    string `json:"country"`
+}
+
+func (a addressRequest) Validate() error {
+	var v validate.Errors
+	a.check(&v, "")
+	return v.Err()
+}
+
+// check records the address's errors under prefix, e.g. "billing_address.city".
+func (a addressRequest) check(v *validate.Errors, prefix string) {
+	v.Required(prefix+"name", a.Name)
+	v.MaxLength(prefix+"name", a.Name, 255)
+	v.Required(prefix+"line1", a.Line1)
+	v.MaxLength(prefix+"line1", a.Line1, 255)
+	v.MaxLength(prefix+"line2", a.Line2, 255)
+	v.Required(prefix+"city", a.City)
+	v.MaxLength(prefix+"city", a.City, 100)
+	v.Required(prefix+"postal_code", a.PostalCode)
+	v.MaxLength(prefix+"postal_code", a.PostalCode, 20)
+	v.Required(prefix+"country", a.Country)
+	v.MaxLength(prefix+"country", a.Country, 100)
+}
+
+// createOrderRequest is optional; an empty body places the order without a billing address.
+type createOrderRequest struct {
+	BillingAddress *addressRequest `json:"billing_address"`
+}
+
+func (req createOrderRequest) Validate() error {
+	var v validate.Errors
+	if req.BillingAddress != nil {
+		req.BillingAddress.check(&v, "billing_address.")
+	}
+	return v.Err()
 }
 
 type createOrderResponse struct {
-	ID     int64   `json:"id"`
-	UserID int64   `json:"user_id"`
-	Total  float64 `json:"total"`
+	ID        int64   `json:"id"`
+	Reference string  `json:"reference"`
+	UserID    int64   `json:"user_id"`
+	Total     float64 `json:"total"`
 }
 
 // CreateOrder converts a cart into a new order, only for the logged‑in user.
@@ -29,34 +80,53 @@ func (h *OrderHandler) CreateOrder(w http.ResponseWriter, r *http.Request) {
 	// 0) who’s calling?
 	caller := middleware.FromContext(r.Context())
 	if caller == 0 {
-		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		apierror.Write(w, r, apierror.ErrUnauthorized)
 		return
 	}
 
 	// 1) which user’s cart?
 	userID, err := strconv.ParseInt(chi.URLParam(r, "userID"), 10, 64)
 	if err != nil {
-		http.Error(w, "invalid userID", http.StatusBadRequest)
+		apierror.Write(w, r, apierror.InvalidParam("userID"))
 		return
 	}
 	// 2) enforce ownership
 	if caller != userID {
-		http.Error(w, "forbidden", http.StatusForbidden)
+		apierror.Write(w, r, apierror.ErrForbidden)
 		return
 	}
 
-	// 3) create the order
-	ord, err := h.orderService.CreateOrder(userID)
+	// 3) read the optional billing address
+	var req createOrderRequest
+	if err = decodeOptionalJSON(w, r, &req); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	var billing *models.Address
+	if a := req.BillingAddress; a != nil {
+		billing = &models.Address{
+			Name:       a.Name,
+			Line1:      a.Line1,
+			Line2:      a.Line2,
+			City:       a.City,
+			PostalCode: a.PostalCode,
+			Country:    a.Country,
+		}
+	}
+
+	// 4) create the order
+	ord, err := h.orderService.CreateOrder(r.Context(), userID, billing)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		apierror.Write(w, r, err)
 		return
 	}
 
 	w.WriteHeader(http.StatusCreated)
 	err = json.NewEncoder(w).Encode(createOrderResponse{
-		ID:     ord.ID,
-		UserID: ord.UserID,
-		Total:  ord.Total,
+		ID:        ord.ID,
+		Reference: ord.Reference,
+		UserID:    ord.UserID,
+		Total:     ord.Total,
 	})
 	if err != nil {
 		return
@@ -67,23 +137,23 @@ func (h *OrderHandler) CreateOrder(w http.ResponseWriter, r *http.Request) {
 func (h *OrderHandler) ListOrders(w http.ResponseWriter, r *http.Request) {
 	caller := middleware.FromContext(r.Context())
 	if caller == 0 {
-		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		apierror.Write(w, r, apierror.ErrUnauthorized)
 		return
 	}
 
 	userID, err := strconv.ParseInt(chi.URLParam(r, "userID"), 10, 64)
 	if err != nil {
-		http.Error(w, "invalid userID", http.StatusBadRequest)
+		apierror.Write(w, r, apierror.InvalidParam("userID"))
 		return
 	}
 	if caller != userID {
-		http.Error(w, "forbidden", http.StatusForbidden)
+		apierror.Write(w, r, apierror.ErrForbidden)
 		return
 	}
 
-	orders, err := h.orderService.GetOrdersForUser(userID)
+	orders, err := h.orderService.GetOrdersForUser(r.Context(), userID)
 	if err != nil {
-		http.Error(w, "could not fetch orders", http.StatusInternalServerError)
+		serverError(w, r, "could not fetch orders", err)
 		return
 	}
 	err = json.NewEncoder(w).Encode(orders)
@@ -96,29 +166,84 @@ func (h *OrderHandler) ListOrders(w http.ResponseWriter, r *http.Request) {
 func (h *OrderHandler) GetOrder(w http.ResponseWriter, r *http.Request) {
 	caller := middleware.FromContext(r.Context())
 	if caller == 0 {
-		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		apierror.Write(w, r, apierror.ErrUnauthorized)
 		return
 	}
 
 	orderID, err := strconv.ParseInt(chi.URLParam(r, "orderID"), 10, 64)
 	if err != nil {
-		http.Error(w, "invalid orderID", http.StatusBadRequest)
+		apierror.Write(w, r, apierror.InvalidParam("orderID"))
 		return
 	}
 
-	ord, err := h.orderService.GetOrderByID(orderID)
+	ord, err := h.orderService.GetOrderByID(r.Context(), orderID)
 	if err != nil {
-		http.Error(w, "could not fetch order", http.StatusInternalServerError)
+		apierror.Write(w, r, err)
 		return
 	}
-	if ord == nil {
-		http.Error(w, "order not found", http.StatusNotFound)
+
+	// enforce ownership
+	if ord.UserID != caller {
+		apierror.Write(w, r, apierror.ErrForbidden)
 		return
 	}
 
-	// enforce ownership
+	err = json.NewEncoder(w).Encode(ord)
+	if err != nil {
+		return
+	}
+}
+
+type cancelOrderRequest struct {
+	Reason string `json:"reason"`
+}
+
+func (req cancelOrderRequest) Validate() error {
+	var v validate.Errors
+	v.MaxLength("reason", req.Reason, 255)
+	return v.Err()
+}
+
+// CancelOrder cancels an unfulfilled order owned by the logged‑in user,
+// voiding or refunding its payment and releasing the reserved stock.
+func (h *OrderHandler) CancelOrder(w http.ResponseWriter, r *http.Request) {
+	caller := middleware.FromContext(r.Context())
+	if caller == 0 {
+		apierror.Write(w, r, apierror.ErrUnauthorized)
+		return
+	}
+
+	orderID, err := strconv.ParseInt(chi.URLParam(r, "orderID"), 10, 64)
+	if err != nil {
+		apierror.Write(w, r, apierror.InvalidParam("orderID"))
+		return
+	}
+
+	var req cancelOrderRequest
+	if err = decodeJSON(w, r, &req); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	if req.Reason == "" {
+		apierror.Write(w, r, apierror.Invalid("reason is required"))
+		return
+	}
+
+	// 1) fetch & enforce ownership
+	ord, err := h.orderService.GetOrderByID(r.Context(), orderID)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
 	if ord.UserID != caller {
-		http.Error(w, "forbidden", http.StatusForbidden)
+		apierror.Write(w, r, apierror.ErrForbidden)
+		return
+	}
+
+	// 2) cancel, release stock & give the money back
+	ord, err = cancelOrder(r.Context(), h.orderService, h.paymentService, ord.ID, req.Reason)
+	if err != nil {
+		apierror.Write(w, r, err)
 		return
 	}
 
@@ -127,3 +252,89 @@ func (h *OrderHandler) GetOrder(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 }
+
+type lookupOrderRequest struct {
+	Reference string `json:"reference"`
+	Email     string `json:"email"`
+}
+
+func (req lookupOrderRequest) Validate() error {
+	var v validate.Errors
+	v.Required("reference", req.Reference)
+	v.MaxLength("reference", req.Reference, 20)
+	v.Email("email", req.Email)
+	return v.Err()
+}
+
+// lookupOrderResponse is the guest view of an order: no internal IDs, owner or address.
+type lookupOrderResponse struct {
+	Reference   string            `json:"reference"`
+	Status      string            `json:"status"`
+	Total       float64           `json:"total"`
+	CreatedAt   time.Time         `json:"created_at"`
+	CancelledAt *time.Time        `json:"cancelled_at,omitempty"`
+	Items       []lookupOrderItem `json:"items"`
+}
+
+type lookupOrderItem struct {
+	ProductID int64   `json:"product_id"`
+	Quantity  int     `json:"quantity"`
+	UnitPrice float64 `json:"unit_price"`
+}
+
+// LookupOrder lets a guest check an order by its reference plus the email it was placed with.
+func (h *OrderHandler) LookupOrder(w http.ResponseWriter, r *http.Request) {
+	var req lookupOrderRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	ord, err := h.orderService.LookupOrder(r.Context(), req.Reference, req.Email)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	resp := lookupOrderResponse{
+		Reference:   ord.Reference,
+		Status:      ord.Status,
+		Total:       ord.Total,
+		CreatedAt:   ord.CreatedAt,
+		CancelledAt: ord.CancelledAt,
+		Items:       make([]lookupOrderItem, 0, len(ord.Items)),
+	}
+	for _, it := range ord.Items {
+		resp.Items = append(resp.Items, lookupOrderItem{
+			ProductID: it.ProductID,
+			Quantity:  it.Quantity,
+			UnitPrice: it.UnitPrice,
+		})
+	}
+	err = json.NewEncoder(w).Encode(resp)
+	if err != nil {
+		return
+	}
+}
+
+// cancelOrder cancels an order, then voids or refunds its payment. The order
+// is cancelled first with a conditional update, so only the caller that
+// actually cancelled it sends a refund, and a refund never goes out for an
+// order that stays live. A refund the gateway refuses is recorded on the
+// payment and retried later; the order stays cancelled either way.
+func cancelOrder(
+	ctx context.Context,
+	orderService *services.OrderService,
+	paymentService *services.PaymentService,
+	orderID int64,
+	reason string,
+) (*models.Order, error) {
+	ord, err := orderService.CancelOrder(ctx, orderID, reason)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := paymentService.CancelPayment(ctx, orderID); err != nil {
+		logging.FromContext(ctx).Error("refund for cancelled order failed; queued for retry", "order_id", orderID, "error", err)
+	}
+	return ord, nil
+}
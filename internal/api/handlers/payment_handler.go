@@ -1,13 +1,18 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
+	"richisntreal-backend/internal/api/apierror"
 	"richisntreal-backend/internal/api/middleware"
+	"richisntreal-backend/internal/api/validate"
 	"strconv"
 
 	"github.com/go-chi/chi/v5"
+	"richisntreal-backend/internal/core/domain/models"
 	"richisntreal-backend/internal/core/services"
+	"richisntreal-backend/internal/infrastructure/logging"
 )
 
 // PaymentHandler wires payment endpoints.
@@ -27,56 +32,94 @@ type paymentRequest struct {
 	Token    string `json:"token"`    // card or payment method token
 }
 
+func (req paymentRequest) Validate() error {
+	var v validate.Errors
+	v.Required("provider", req.Provider)
+	v.MaxLength("provider", req.Provider, 50)
+	v.Required("token", req.Token)
+	v.MaxLength("token", req.Token, 255)
+	return v.Err()
+}
+
 func (h *PaymentHandler) ProcessPayment(w http.ResponseWriter, r *http.Request) {
 	// 0) who’s calling?
 	caller := middleware.FromContext(r.Context())
 	if caller == 0 {
-		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		apierror.Write(w, r, apierror.ErrUnauthorized)
 		return
 	}
 
 	// 1) parse orderID
 	oid, err := strconv.ParseInt(chi.URLParam(r, "orderID"), 10, 64)
 	if err != nil {
-		http.Error(w, "invalid order ID", http.StatusBadRequest)
+		apierror.Write(w, r, apierror.InvalidParam("orderID"))
 		return
 	}
 
 	// 2) fetch order to get amount & owner
-	ord, err := h.orderService.GetOrderByID(oid)
+	ord, err := h.orderService.GetOrderByID(r.Context(), oid)
 	if err != nil {
-		http.Error(w, "could not fetch order", http.StatusInternalServerError)
-		return
-	}
-	if ord == nil {
-		http.Error(w, "order not found", http.StatusNotFound)
+		apierror.Write(w, r, err)
 		return
 	}
 
 	// 3) enforce ownership
 	if ord.UserID != caller {
-		http.Error(w, "forbidden", http.StatusForbidden)
+		apierror.Write(w, r, apierror.ErrForbidden)
 		return
 	}
 
 	// 4) decode body
 	var req paymentRequest
-	if err = json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "invalid JSON payload", http.StatusBadRequest)
+	if err = decodeJSON(w, r, &req); err != nil {
+		apierror.Write(w, r, err)
 		return
 	}
 
-	// 5) process payment
-	tx, err := h.paymentService.ProcessPayment(ord.ID, ord.Total, "USD", req.Provider, req.Token)
+	// 5) claim the order, so it is charged once and only while pending
+	if ord, err = h.orderService.ClaimForPayment(r.Context(), ord.ID); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	// 6) process payment, handing the order back if nothing was charged
+	tx, err := h.paymentService.ProcessPayment(r.Context(), ord.ID, ord.Total, "USD", req.Provider, req.Token)
 	if err != nil {
-		http.Error(w, "payment failed: "+err.Error(), http.StatusBadRequest)
+		h.releaseUncharged(context.WithoutCancel(r.Context()), ord.ID)
+		apierror.Write(w, r, err)
 		return
 	}
 
-	// 6) return
+	// 7) a captured payment makes the order paid and issues its invoice; one
+	// still pending at the provider leaves the order paying
+	if tx.Status == models.PaymentStatusSucceeded {
+		if _, err = h.orderService.MarkPaid(context.WithoutCancel(r.Context()), ord.ID); err != nil {
+			serverError(w, r, "payment succeeded but the order could not be marked paid", err)
+			return
+		}
+	}
+
+	// 8) return
 	w.WriteHeader(http.StatusCreated)
 	err = json.NewEncoder(w).Encode(tx)
 	if err != nil {
 		return
 	}
 }
+
+// releaseUncharged hands a claimed order back to pending after a payment
+// attempt went wrong, unless its latest transaction shows the charge may
+// have gone through; those stay paying for staff to settle.
+func (h *PaymentHandler) releaseUncharged(ctx context.Context, orderID int64) {
+	tx, err := h.paymentService.GetPaymentByOrder(ctx, orderID)
+	if err == nil && tx != nil && tx.Status != models.PaymentStatusFailed {
+		logging.FromContext(ctx).Error("payment outcome unknown; order left paying", "order_id", orderID, "payment_id", tx.ID)
+		return
+	}
+	if err == nil {
+		err = h.orderService.ReleasePaymentClaim(ctx, orderID)
+	}
+	if err != nil {
+		logging.FromContext(ctx).Error("could not release order after failed payment", "order_id", orderID, "error", err)
+	}
+}
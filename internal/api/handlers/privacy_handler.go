@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"richisntreal-backend/internal/api/apierror"
+	"richisntreal-backend/internal/core/domain/models"
+	"richisntreal-backend/internal/core/services"
+)
+
+// PrivacyHandler wires the data export and erasure endpoints.
+type PrivacyHandler struct {
+	privacyService *services.PrivacyService
+}
+
+// NewPrivacyHandler constructs a new PrivacyHandler.
+func NewPrivacyHandler(privacyService *services.PrivacyService) *PrivacyHandler {
+	return &PrivacyHandler{privacyService: privacyService}
+}
+
+// ExportUser handles GET /users/{id}/export for the user themselves.
+// ?format=zip returns one JSON file per section instead of a single document.
+func (h *PrivacyHandler) ExportUser(w http.ResponseWriter, r *http.Request) {
+	id, ok := ownUserID(w, r)
+	if !ok {
+		return
+	}
+	h.export(w, r, id)
+}
+
+// AdminExportUser handles GET /admin/users/{id}/export, for staff answering
+// a request received by other means.
+func (h *PrivacyHandler) AdminExportUser(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		apierror.Write(w, r, apierror.InvalidParam("id"))
+		return
+	}
+	h.export(w, r, id)
+}
+
+// AdminEraseUser handles POST /admin/users/{id}/erase.
+func (h *PrivacyHandler) AdminEraseUser(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		apierror.Write(w, r, apierror.InvalidParam("id"))
+		return
+	}
+	if err := h.privacyService.EraseUser(r.Context(), id); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *PrivacyHandler) export(w http.ResponseWriter, r *http.Request, userID int64) {
+	data, err := h.privacyService.ExportUserData(r.Context(), userID)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	name := fmt.Sprintf("user-%d-export", userID)
+	if r.URL.Query().Get("format") == "zip" {
+		w.Header().Set("Content-Type", "application/zip")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.zip"`, name))
+		writeExportZip(w, data)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.json"`, name))
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	err = enc.Encode(data)
+	if err != nil {
+		return
+	}
+}
+
+func writeExportZip(w http.ResponseWriter, data *models.DataExport) {
+	zw := zip.NewWriter(w)
+	defer zw.Close()
+
+	write := func(file string, v interface{}) error {
+		f, err := zw.Create(file)
+		if err != nil {
+			return err
+		}
+		enc := json.NewEncoder(f)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+	err := write("export.json", struct {
+		UserID      int64    `json:"user_id"`
+		GeneratedAt string   `json:"generated_at"`
+		Files       []string `json:"files"`
+	}{
+		UserID:      data.UserID,
+		GeneratedAt: data.GeneratedAt.Format(time.RFC3339),
+		Files:       sectionFiles(data.Sections),
+	})
+	if err != nil {
+		return
+	}
+	for _, s := range data.Sections {
+		if err := write(s.Name+".json", s.Rows); err != nil {
+			return
+		}
+	}
+}
+
+func sectionFiles(sections []models.DataExportSection) []string {
+	files := make([]string, len(sections))
+	for i, s := range sections {
+		files[i] = s.Name + ".json"
+	}
+	return files
+}
@@ -2,11 +2,13 @@ package handlers
 
 import (
 	"encoding/json"
-	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
+	"richisntreal-backend/internal/api/apierror"
+	"richisntreal-backend/internal/api/validate"
 	"richisntreal-backend/internal/core/services"
 )
 
@@ -23,12 +25,23 @@ type productRequest struct {
 	Description string  `json:"description"`
 	SKU         string  `json:"sku"`
 	Price       float64 `json:"price"`
+	Stock       *int    `json:"stock"` // omit to leave stock untracked
 }
 
-func (h *ProductHandler) List(w http.ResponseWriter, _ *http.Request) {
-	prods, err := h.productService.ListProducts()
+func (req productRequest) Validate() error {
+	var v validate.Errors
+	v.Required("name", strings.TrimSpace(req.Name))
+	v.MaxLength("name", req.Name, 255)
+	v.MaxLength("sku", req.SKU, 100)
+	v.NotNegative("price", req.Price)
+	v.Check(req.Stock == nil || *req.Stock >= 0, "stock", "must not be negative")
+	return v.Err()
+}
+
+func (h *ProductHandler) List(w http.ResponseWriter, r *http.Request) {
+	prods, err := h.productService.ListProducts(r.Context())
 	if err != nil {
-		http.Error(w, "could not fetch products", http.StatusInternalServerError)
+		serverError(w, r, "could not fetch products", err)
 		return
 	}
 	err = json.NewEncoder(w).Encode(prods)
@@ -40,16 +53,16 @@ func (h *ProductHandler) List(w http.ResponseWriter, _ *http.Request) {
 func (h *ProductHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
 	if err != nil {
-		http.Error(w, "invalid product id", http.StatusBadRequest)
+		apierror.Write(w, r, apierror.InvalidParam("id"))
 		return
 	}
-	prod, err := h.productService.GetProductByID(id)
+	prod, err := h.productService.GetProductByID(r.Context(), id)
 	if err != nil {
-		http.Error(w, "could not fetch product", http.StatusInternalServerError)
+		serverError(w, r, "could not fetch product", err)
 		return
 	}
 	if prod == nil {
-		http.Error(w, "product not found", http.StatusNotFound)
+		apierror.Write(w, r, services.ErrProductNotFound)
 		return
 	}
 	err = json.NewEncoder(w).Encode(prod)
@@ -60,13 +73,13 @@ func (h *ProductHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 
 func (h *ProductHandler) Create(w http.ResponseWriter, r *http.Request) {
 	var req productRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "invalid request payload", http.StatusBadRequest)
+	if err := decodeJSON(w, r, &req); err != nil {
+		apierror.Write(w, r, err)
 		return
 	}
-	prod, err := h.productService.CreateProduct(req.Name, req.Description, req.SKU, req.Price)
+	prod, err := h.productService.CreateProduct(r.Context(), req.Name, req.Description, req.SKU, req.Price, req.Stock)
 	if err != nil {
-		http.Error(w, "could not create product", http.StatusInternalServerError)
+		serverError(w, r, "could not create product", err)
 		return
 	}
 	w.WriteHeader(http.StatusCreated)
@@ -79,21 +92,17 @@ func (h *ProductHandler) Create(w http.ResponseWriter, r *http.Request) {
 func (h *ProductHandler) Update(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
 	if err != nil {
-		http.Error(w, "invalid product id", http.StatusBadRequest)
+		apierror.Write(w, r, apierror.InvalidParam("id"))
 		return
 	}
 	var req productRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "invalid request payload", http.StatusBadRequest)
+	if err := decodeJSON(w, r, &req); err != nil {
+		apierror.Write(w, r, err)
 		return
 	}
-	prod, err := h.productService.UpdateProduct(id, req.Name, req.Description, req.SKU, req.Price)
+	prod, err := h.productService.UpdateProduct(r.Context(), id, req.Name, req.Description, req.SKU, req.Price, req.Stock)
 	if err != nil {
-		if errors.Is(err, services.ErrProductNotFound) {
-			http.Error(w, "product not found", http.StatusNotFound)
-		} else {
-			http.Error(w, "could not update product", http.StatusInternalServerError)
-		}
+		apierror.Write(w, r, err)
 		return
 	}
 	err = json.NewEncoder(w).Encode(prod)
@@ -105,11 +114,11 @@ func (h *ProductHandler) Update(w http.ResponseWriter, r *http.Request) {
 func (h *ProductHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
 	if err != nil {
-		http.Error(w, "invalid product id", http.StatusBadRequest)
+		apierror.Write(w, r, apierror.InvalidParam("id"))
 		return
 	}
-	if err = h.productService.DeleteProduct(id); err != nil {
-		http.Error(w, "could not delete product", http.StatusInternalServerError)
+	if err = h.productService.DeleteProduct(r.Context(), id); err != nil {
+		serverError(w, r, "could not delete product", err)
 		return
 	}
 	w.WriteHeader(http.StatusNoContent)
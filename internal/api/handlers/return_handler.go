@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"richisntreal-backend/internal/api/apierror"
+	"richisntreal-backend/internal/api/middleware"
+	"richisntreal-backend/internal/api/validate"
+	"richisntreal-backend/internal/core/domain/models"
+	"richisntreal-backend/internal/core/services"
+)
+
+// ReturnHandler wires return (RMA) endpoints for customers and admins.
+type ReturnHandler struct {
+	returnService *services.ReturnService
+	orderService  *services.OrderService
+}
+
+// NewReturnHandler constructs a new ReturnHandler.
+func NewReturnHandler(returnService *services.ReturnService, orderService *services.OrderService) *ReturnHandler {
+	return &ReturnHandler{returnService: returnService, orderService: orderService}
+}
+
+type returnItemRequest struct {
+	OrderItemID int64 `json:"order_item_id"`
+	Quantity    int   `json:"quantity"`
+}
+
+type createReturnRequest struct {
+	Reason string              `json:"reason"`
+	Items  []returnItemRequest `json:"items"`
+}
+
+func (req createReturnRequest) Validate() error {
+	var v validate.Errors
+	v.Required("reason", req.Reason)
+	v.Check(len(req.Items) > 0, "items", "is required")
+	for i, it := range req.Items {
+		prefix := fmt.Sprintf("items[%d].", i)
+		v.Positive(prefix+"order_item_id", it.OrderItemID)
+		v.Positive(prefix+"quantity", int64(it.Quantity))
+	}
+	return v.Err()
+}
+
+type reviewReturnRequest struct {
+	Resolution string `json:"resolution"` // "refund" or "restock"; approval only
+	Note       string `json:"note"`
+}
+
+// CreateReturn opens a return for lines of an order owned by the logged‑in user.
+func (h *ReturnHandler) CreateReturn(w http.ResponseWriter, r *http.Request) {
+	ord, ok := h.ownedOrder(w, r)
+	if !ok {
+		return
+	}
+
+	var req createReturnRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	items := make([]models.ReturnItem, 0, len(req.Items))
+	for _, it := range req.Items {
+		items = append(items, models.ReturnItem{OrderItemID: it.OrderItemID, Quantity: it.Quantity})
+	}
+
+	rr, err := h.returnService.RequestReturn(r.Context(), ord.ID, req.Reason, items)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	err = json.NewEncoder(w).Encode(rr)
+	if err != nil {
+		return
+	}
+}
+
+// ListOrderReturns lists the returns opened for an order owned by the logged‑in user.
+func (h *ReturnHandler) ListOrderReturns(w http.ResponseWriter, r *http.Request) {
+	ord, ok := h.ownedOrder(w, r)
+	if !ok {
+		return
+	}
+	returns, err := h.returnService.GetReturnsForOrder(r.Context(), ord.ID)
+	if err != nil {
+		serverError(w, r, "could not fetch returns", err)
+		return
+	}
+	err = json.NewEncoder(w).Encode(returns)
+	if err != nil {
+		return
+	}
+}
+
+// ListReturns lists all returns for staff, optionally filtered by ?status=.
+func (h *ReturnHandler) ListReturns(w http.ResponseWriter, r *http.Request) {
+	returns, err := h.returnService.ListReturns(r.Context(), r.URL.Query().Get("status"))
+	if err != nil {
+		serverError(w, r, "could not fetch returns", err)
+		return
+	}
+	err = json.NewEncoder(w).Encode(returns)
+	if err != nil {
+		return
+	}
+}
+
+// GetReturn fetches a single return for staff.
+func (h *ReturnHandler) GetReturn(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "returnID"), 10, 64)
+	if err != nil {
+		apierror.Write(w, r, apierror.InvalidParam("returnID"))
+		return
+	}
+	rr, err := h.returnService.GetReturn(r.Context(), id)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	err = json.NewEncoder(w).Encode(rr)
+	if err != nil {
+		return
+	}
+}
+
+// Approve accepts a return and chooses whether it will be refunded or restocked.
+func (h *ReturnHandler) Approve(w http.ResponseWriter, r *http.Request) {
+	h.review(w, r, func(id int64, req reviewReturnRequest) (*models.ReturnRequest, error) {
+		return h.returnService.Approve(r.Context(), id, req.Resolution, req.Note)
+	})
+}
+
+// Reject turns a return down.
+func (h *ReturnHandler) Reject(w http.ResponseWriter, r *http.Request) {
+	h.review(w, r, func(id int64, req reviewReturnRequest) (*models.ReturnRequest, error) {
+		return h.returnService.Reject(r.Context(), id, req.Note)
+	})
+}
+
+// MarkReceived records the goods as received and settles the return.
+func (h *ReturnHandler) MarkReceived(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "returnID"), 10, 64)
+	if err != nil {
+		apierror.Write(w, r, apierror.InvalidParam("returnID"))
+		return
+	}
+	rr, err := h.returnService.MarkReceived(r.Context(), id)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	err = json.NewEncoder(w).Encode(rr)
+	if err != nil {
+		return
+	}
+}
+
+func (h *ReturnHandler) review(
+	w http.ResponseWriter,
+	r *http.Request,
+	apply func(id int64, req reviewReturnRequest) (*models.ReturnRequest, error),
+) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "returnID"), 10, 64)
+	if err != nil {
+		apierror.Write(w, r, apierror.InvalidParam("returnID"))
+		return
+	}
+	var req reviewReturnRequest
+	if err = decodeJSON(w, r, &req); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	rr, err := apply(id, req)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	err = json.NewEncoder(w).Encode(rr)
+	if err != nil {
+		return
+	}
+}
+
+// ownedOrder loads {orderID} and makes sure the caller owns it, writing the
+// error response itself when not.
+func (h *ReturnHandler) ownedOrder(w http.ResponseWriter, r *http.Request) (*models.Order, bool) {
+	caller := middleware.FromContext(r.Context())
+	if caller == 0 {
+		apierror.Write(w, r, apierror.ErrUnauthorized)
+		return nil, false
+	}
+	orderID, err := strconv.ParseInt(chi.URLParam(r, "orderID"), 10, 64)
+	if err != nil {
+		apierror.Write(w, r, apierror.InvalidParam("orderID"))
+		return nil, false
+	}
+	ord, err := h.orderService.GetOrderByID(r.Context(), orderID)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return nil, false
+	}
+	if ord.UserID != caller {
+		apierror.Write(w, r, apierror.ErrForbidden)
+		return nil, false
+	}
+	return ord, true
+}
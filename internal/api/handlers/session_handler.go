@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"richisntreal-backend/internal/api/apierror"
+	"richisntreal-backend/internal/api/middleware"
+	"richisntreal-backend/internal/api/validate"
+	"richisntreal-backend/internal/core/services"
+)
+
+// SessionHandler wires token refresh and logout endpoints.
+type SessionHandler struct {
+	sessionService *services.SessionService
+}
+
+// NewSessionHandler constructs a new SessionHandler.
+func NewSessionHandler(sessionService *services.SessionService) *SessionHandler {
+	return &SessionHandler{sessionService: sessionService}
+}
+
+type refreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+func (req refreshRequest) Validate() error {
+	var v validate.Errors
+	v.Required("refresh_token", req.RefreshToken)
+	return v.Err()
+}
+
+// Refresh exchanges a refresh token for a new access/refresh token pair.
+func (h *SessionHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	var req refreshRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	tokens, err := h.sessionService.Refresh(r.Context(), req.RefreshToken)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(tokens)
+	if err != nil {
+		return
+	}
+}
+
+// Logout revokes the session the caller's access token belongs to.
+func (h *SessionHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	p := middleware.PrincipalFromContext(r.Context())
+	if p == nil {
+		apierror.Write(w, r, apierror.ErrUnauthorized)
+		return
+	}
+	if err := h.sessionService.Logout(r.Context(), p.SessionID); err != nil {
+		serverError(w, r, "internal server error", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// LogoutAll revokes every session of the caller, logging out all devices.
+func (h *SessionHandler) LogoutAll(w http.ResponseWriter, r *http.Request) {
+	caller := middleware.FromContext(r.Context())
+	if caller == 0 {
+		apierror.Write(w, r, apierror.ErrUnauthorized)
+		return
+	}
+	if err := h.sessionService.LogoutAll(r.Context(), caller); err != nil {
+		serverError(w, r, "internal server error", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
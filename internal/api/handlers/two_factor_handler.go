@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"richisntreal-backend/internal/api/apierror"
+	"richisntreal-backend/internal/api/middleware"
+	"richisntreal-backend/internal/api/validate"
+	"richisntreal-backend/internal/core/services"
+)
+
+// TwoFactorHandler wires the TOTP enrolment and management endpoints.
+type TwoFactorHandler struct {
+	twoFactorService *services.TwoFactorService
+}
+
+// NewTwoFactorHandler constructs a new TwoFactorHandler.
+func NewTwoFactorHandler(twoFactorService *services.TwoFactorService) *TwoFactorHandler {
+	return &TwoFactorHandler{twoFactorService: twoFactorService}
+}
+
+type twoFactorCodeRequest struct {
+	Code string `json:"code"`
+}
+
+func (req twoFactorCodeRequest) Validate() error {
+	var v validate.Errors
+	v.Required("code", req.Code)
+	return v.Err()
+}
+
+type twoFactorStatusResponse struct {
+	Enabled bool `json:"enabled"`
+}
+
+type recoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// Status reports whether the caller has 2FA enabled.
+func (h *TwoFactorHandler) Status(w http.ResponseWriter, r *http.Request) {
+	caller := middleware.FromContext(r.Context())
+	if caller == 0 {
+		apierror.Write(w, r, apierror.ErrUnauthorized)
+		return
+	}
+	enabled, err := h.twoFactorService.IsEnabled(r.Context(), caller)
+	if err != nil {
+		serverError(w, r, "internal server error", err)
+		return
+	}
+	err = json.NewEncoder(w).Encode(twoFactorStatusResponse{Enabled: enabled})
+	if err != nil {
+		return
+	}
+}
+
+// Enroll starts enrolment and returns the secret and otpauth URI to scan.
+func (h *TwoFactorHandler) Enroll(w http.ResponseWriter, r *http.Request) {
+	caller := middleware.FromContext(r.Context())
+	if caller == 0 {
+		apierror.Write(w, r, apierror.ErrUnauthorized)
+		return
+	}
+	enrollment, err := h.twoFactorService.BeginEnrollment(r.Context(), caller)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	err = json.NewEncoder(w).Encode(enrollment)
+	if err != nil {
+		return
+	}
+}
+
+// Confirm enables 2FA with a first code and returns the recovery codes.
+func (h *TwoFactorHandler) Confirm(w http.ResponseWriter, r *http.Request) {
+	caller := middleware.FromContext(r.Context())
+	if caller == 0 {
+		apierror.Write(w, r, apierror.ErrUnauthorized)
+		return
+	}
+	var req twoFactorCodeRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	codes, err := h.twoFactorService.ConfirmEnrollment(r.Context(), caller, req.Code)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	err = json.NewEncoder(w).Encode(recoveryCodesResponse{RecoveryCodes: codes})
+	if err != nil {
+		return
+	}
+}
+
+// RegenerateRecoveryCodes swaps the caller's recovery codes for new ones.
+func (h *TwoFactorHandler) RegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	caller := middleware.FromContext(r.Context())
+	if caller == 0 {
+		apierror.Write(w, r, apierror.ErrUnauthorized)
+		return
+	}
+	var req twoFactorCodeRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	codes, err := h.twoFactorService.RegenerateRecoveryCodes(r.Context(), caller, req.Code)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	err = json.NewEncoder(w).Encode(recoveryCodesResponse{RecoveryCodes: codes})
+	if err != nil {
+		return
+	}
+}
+
+// Disable turns 2FA off given a TOTP or recovery code.
+func (h *TwoFactorHandler) Disable(w http.ResponseWriter, r *http.Request) {
+	caller := middleware.FromContext(r.Context())
+	if caller == 0 {
+		apierror.Write(w, r, apierror.ErrUnauthorized)
+		return
+	}
+	var req twoFactorCodeRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	if err := h.twoFactorService.Disable(r.Context(), caller, req.Code); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -4,9 +4,13 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+	"richisntreal-backend/internal/api/apierror"
 	"richisntreal-backend/internal/api/middleware"
+	"richisntreal-backend/internal/api/validate"
+	"richisntreal-backend/internal/core/domain/models"
 	"richisntreal-backend/internal/core/services"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
@@ -14,12 +18,26 @@ import (
 
 // UserHandler wires HTTP requests to user-related services.
 type UserHandler struct {
-	userService *services.UserService
+	userService    *services.UserService
+	accountService *services.AccountService
+	loginGuard     *services.LoginGuard
+	accountLimiter middleware.RateLimiter
 }
 
-// NewUserHandler constructs a new UserHandler.
-func NewUserHandler(userService *services.UserService) *UserHandler {
-	return &UserHandler{userService: userService}
+// NewUserHandler constructs a new UserHandler. accountLimiter throttles
+// login attempts per account, on top of any per-IP limit on the route.
+func NewUserHandler(
+	userService *services.UserService,
+	accountService *services.AccountService,
+	loginGuard *services.LoginGuard,
+	accountLimiter middleware.RateLimiter,
+) *UserHandler {
+	return &UserHandler{
+		userService:    userService,
+		accountService: accountService,
+		loginGuard:     loginGuard,
+		accountLimiter: accountLimiter,
+	}
 }
 
 type createUserRequest struct {
@@ -32,6 +50,19 @@ type createUserRequest struct {
 	DateOfBirth string `json:"dateOfBirth"` // ISO 8601, e.g. "1990-05-01"
 }
 
+func (req createUserRequest) Validate() error {
+	var v validate.Errors
+	v.Required("username", strings.TrimSpace(req.Username))
+	v.MaxLength("username", req.Username, 255)
+	v.Email("email", req.Email)
+	v.MaxLength("email", req.Email, 255)
+	checkNewPassword(&v, "password", req.Password)
+	v.MaxLength("firstName", req.FirstName, 255)
+	v.MaxLength("lastName", req.LastName, 255)
+	v.MaxLength("country", req.Country, 100)
+	return v.Err()
+}
+
 type createUserResponse struct {
 	ID          int64     `json:"id"`
 	Username    string    `json:"username"`
@@ -46,8 +77,8 @@ type createUserResponse struct {
 // CreateUser handles user registration.
 func (h *UserHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
 	var req createUserRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "invalid request payload", http.StatusBadRequest)
+	if err := decodeJSON(w, r, &req); err != nil {
+		apierror.Write(w, r, err)
 		return
 	}
 
@@ -56,7 +87,7 @@ func (h *UserHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
 	if req.DateOfBirth != "" {
 		dob, err := time.Parse(time.RFC3339, req.DateOfBirth)
 		if err != nil {
-			http.Error(w, "invalid dateOfBirth format; use RFC3339", http.StatusBadRequest)
+			apierror.Write(w, r, apierror.InvalidParam("dateOfBirth").With("format", "RFC3339"))
 			return
 		}
 		dobPtr = &dob
@@ -64,6 +95,7 @@ func (h *UserHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
 
 	// call service (assumes signature changed accordingly)
 	user, err := h.userService.CreateUser(
+		r.Context(),
 		req.Username,
 		req.Email,
 		req.Password,
@@ -73,14 +105,13 @@ func (h *UserHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
 		dobPtr,
 	)
 	if err != nil {
-		if errors.Is(err, services.ErrUserExists) {
-			http.Error(w, err.Error(), http.StatusConflict)
-		} else {
-			http.Error(w, "internal server error", http.StatusInternalServerError)
-		}
+		apierror.Write(w, r, err)
 		return
 	}
 
+	// the account exists either way; the user can ask for another mail
+	_ = h.accountService.SendEmailVerification(r.Context(), user.ID)
+
 	// build response
 	resp := createUserResponse{
 		ID:        user.ID,
@@ -104,8 +135,18 @@ func (h *UserHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
 }
 
 type loginResponse struct {
-	Token string      `json:"token"`
-	User  userProfile `json:"user"`
+	Token        string      `json:"token"`
+	RefreshToken string      `json:"refresh_token"`
+	ExpiresIn    int64       `json:"expires_in"`
+	User         userProfile `json:"user"`
+}
+
+// twoFactorChallengeResponse is returned by /login instead of tokens when
+// the account has 2FA enabled.
+type twoFactorChallengeResponse struct {
+	TwoFactorRequired bool   `json:"two_factor_required"`
+	ChallengeToken    string `json:"challenge_token"`
+	ExpiresIn         int64  `json:"expires_in"`
 }
 
 type userProfile struct {
@@ -116,38 +157,182 @@ type userProfile struct {
 	LastName  string `json:"lastName"`
 }
 
+type loginRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// Validate only checks presence: the format and length rules may have
+// changed since the account was created.
+func (req loginRequest) Validate() error {
+	var v validate.Errors
+	v.Required("email", req.Email)
+	v.Required("password", req.Password)
+	return v.Err()
+}
+
 // Login handles user authentication.
 func (h *UserHandler) Login(w http.ResponseWriter, r *http.Request) {
-	var req struct {
-		Email    string `json:"email"`
-		Password string `json:"password"`
+	var req loginRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	ip := middleware.ClientIP(r)
+
+	// 2) Throttle guessing on this account, whatever IPs it comes from
+	if ok, wait := h.accountLimiter.Allow("login:account:" + strings.ToLower(req.Email)); !ok {
+		if err := h.loginGuard.RecordFailure(r.Context(), req.Email, ip, models.LoginFailureRateLimited); err != nil {
+			serverError(w, r, "internal server error", err)
+			return
+		}
+		middleware.TooManyRequests(w, r, wait)
+		return
 	}
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "invalid request payload", http.StatusBadRequest)
+	lockedFor, err := h.loginGuard.LockedFor(r.Context(), req.Email)
+	if err != nil {
+		serverError(w, r, "internal server error", err)
+		return
+	}
+	if lockedFor > 0 {
+		if err := h.loginGuard.RecordFailure(r.Context(), req.Email, ip, models.LoginFailureLocked); err != nil {
+			serverError(w, r, "internal server error", err)
+			return
+		}
+		middleware.TooManyRequests(w, r, lockedFor)
 		return
 	}
 
-	// 2a) Authenticate & get token
-	token, err := h.userService.Authenticate(req.Email, req.Password)
+	// 3) Authenticate & get tokens or a 2FA challenge
+	result, err := h.userService.Authenticate(r.Context(), req.Email, req.Password)
 	if err != nil {
+		reason := ""
 		if errors.Is(err, services.ErrInvalidCredentials) {
-			http.Error(w, err.Error(), http.StatusUnauthorized)
-		} else {
-			http.Error(w, "internal server error", http.StatusInternalServerError)
+			reason = models.LoginFailureInvalidCredentials
+		} else if errors.Is(err, services.ErrEmailNotVerified) {
+			reason = models.LoginFailureEmailNotVerified
+		} else if errors.Is(err, services.ErrAccountDisabled) {
+			reason = models.LoginFailureAccountDisabled
+		}
+		if reason == "" {
+			serverError(w, r, "internal server error", err)
+			return
+		}
+		if err := h.loginGuard.RecordFailure(r.Context(), req.Email, ip, reason); err != nil {
+			serverError(w, r, "internal server error", err)
+			return
+		}
+		apierror.Write(w, r, err)
+		return
+	}
+	// a challenge only proves the password; success waits for the second factor
+	if result.Tokens != nil {
+		if err := h.loginGuard.RecordSuccess(r.Context(), result.User.ID, req.Email, ip); err != nil {
+			serverError(w, r, "internal server error", err)
+			return
+		}
+	}
+
+	// 4) Marshal response
+	writeLoginResult(w, result)
+}
+
+type twoFactorLoginRequest struct {
+	ChallengeToken string `json:"challenge_token"`
+	Code           string `json:"code"` // TOTP or recovery code
+}
+
+func (req twoFactorLoginRequest) Validate() error {
+	var v validate.Errors
+	v.Required("challenge_token", req.ChallengeToken)
+	v.Required("code", req.Code)
+	return v.Err()
+}
+
+// LoginTwoFactor completes a login challenge with a second factor.
+func (h *UserHandler) LoginTwoFactor(w http.ResponseWriter, r *http.Request) {
+	var req twoFactorLoginRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	ip := middleware.ClientIP(r)
+
+	// 1) Find the account, so codes are throttled and locked out per
+	// account rather than per challenge, which anyone with the password
+	// can mint again
+	user, err := h.userService.ChallengedUser(r.Context(), req.ChallengeToken)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	if ok, wait := h.accountLimiter.Allow("login:account:" + strings.ToLower(user.Email)); !ok {
+		if err := h.loginGuard.RecordFailure(r.Context(), user.Email, ip, models.LoginFailureRateLimited); err != nil {
+			serverError(w, r, "internal server error", err)
+			return
 		}
+		middleware.TooManyRequests(w, r, wait)
+		return
+	}
+	lockedFor, err := h.loginGuard.LockedFor(r.Context(), user.Email)
+	if err != nil {
+		serverError(w, r, "internal server error", err)
+		return
+	}
+	if lockedFor > 0 {
+		if err := h.loginGuard.RecordFailure(r.Context(), user.Email, ip, models.LoginFailureLocked); err != nil {
+			serverError(w, r, "internal server error", err)
+			return
+		}
+		middleware.TooManyRequests(w, r, lockedFor)
 		return
 	}
 
-	// 2b) Fetch the user record (so we can include first/last name):
-	user, err := h.userService.GetByEmail(req.Email)
+	// 2) Check the code; wrong ones count towards the lockout
+	result, err := h.userService.CompleteTwoFactorLogin(r.Context(), req.ChallengeToken, req.Code)
 	if err != nil {
-		http.Error(w, "internal server error", http.StatusInternalServerError)
+		reason := ""
+		if errors.Is(err, services.ErrInvalidTwoFactorCode) {
+			reason = models.LoginFailureInvalidSecondFactor
+		} else if errors.Is(err, services.ErrAccountDisabled) {
+			reason = models.LoginFailureAccountDisabled
+		}
+		if reason != "" {
+			if err := h.loginGuard.RecordFailure(r.Context(), user.Email, ip, reason); err != nil {
+				serverError(w, r, "internal server error", err)
+				return
+			}
+		}
+		apierror.Write(w, r, err)
+		return
+	}
+	if err := h.loginGuard.RecordSuccess(r.Context(), result.User.ID, user.Email, ip); err != nil {
+		serverError(w, r, "internal server error", err)
 		return
 	}
+	writeLoginResult(w, result)
+}
 
-	// 3) Marshal combined response
-	resp := loginResponse{
-		Token: token,
+func writeLoginResult(w http.ResponseWriter, result *models.LoginResult) {
+	w.Header().Set("Content-Type", "application/json")
+	if result.Tokens == nil {
+		err := json.NewEncoder(w).Encode(twoFactorChallengeResponse{
+			TwoFactorRequired: true,
+			ChallengeToken:    result.ChallengeToken,
+			ExpiresIn:         result.ChallengeExpiresIn,
+		})
+		if err != nil {
+			return
+		}
+		return
+	}
+
+	user := result.User
+	err := json.NewEncoder(w).Encode(loginResponse{
+		Token:        result.Tokens.AccessToken,
+		RefreshToken: result.Tokens.RefreshToken,
+		ExpiresIn:    result.Tokens.ExpiresIn,
 		User: userProfile{
 			ID:        user.ID,
 			Username:  user.Username,
@@ -155,60 +340,241 @@ func (h *UserHandler) Login(w http.ResponseWriter, r *http.Request) {
 			FirstName: user.FirstName,
 			LastName:  user.LastName,
 		},
-	}
-	w.Header().Set("Content-Type", "application/json")
-	err = json.NewEncoder(w).Encode(resp)
+	})
 	if err != nil {
 		return
 	}
 }
 
+type userDetailResponse struct {
+	ID            int64     `json:"id"`
+	Username      string    `json:"username"`
+	Email         string    `json:"email"`
+	EmailVerified bool      `json:"emailVerified"`
+	FirstName     string    `json:"firstName"`
+	LastName      string    `json:"lastName"`
+	Country       string    `json:"country"`
+	DateOfBirth   string    `json:"dateOfBirth,omitempty"`
+	CreatedAt     time.Time `json:"createdAt"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+}
+
+func newUserDetailResponse(user *models.User) userDetailResponse {
+	resp := userDetailResponse{
+		ID:            user.ID,
+		Username:      user.Username,
+		Email:         user.Email,
+		EmailVerified: user.EmailVerifiedAt != nil,
+		FirstName:     user.FirstName,
+		LastName:      user.LastName,
+		Country:       user.Country,
+		CreatedAt:     user.CreatedAt,
+		UpdatedAt:     user.UpdatedAt,
+	}
+	if user.DateOfBirth != nil {
+		resp.DateOfBirth = user.DateOfBirth.Format(time.RFC3339)
+	}
+	return resp
+}
+
 // GetUser handles GET /users/{id}; only the logged‑in user may fetch their own record.
 func (h *UserHandler) GetUser(w http.ResponseWriter, r *http.Request) {
-	// 1) Extract the caller’s userID from the context (AuthMiddleware must have run).
-	caller := middleware.FromContext(r.Context())
-	if caller == 0 {
-		http.Error(w, "unauthorized", http.StatusUnauthorized)
+	// 1) Enforce ownership
+	id, ok := ownUserID(w, r)
+	if !ok {
 		return
 	}
 
-	// 2) Parse the URL param
-	idStr := chi.URLParam(r, "id")
-	id, err := strconv.ParseInt(idStr, 10, 64)
+	// 2) Fetch & return
+	user, err := h.userService.GetByID(r.Context(), id)
 	if err != nil {
-		http.Error(w, "invalid user id", http.StatusBadRequest)
+		apierror.Write(w, r, err)
 		return
 	}
 
-	// 3) Enforce ownership
-	if caller != id {
-		http.Error(w, "forbidden", http.StatusForbidden)
+	// 3) Write JSON
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(newUserDetailResponse(user))
+	if err != nil {
 		return
 	}
+}
 
-	// 4) Fetch & return
-	user, err := h.userService.GetByID(id)
-	if err != nil {
-		if errors.Is(err, services.ErrUserNotFound) {
-			http.Error(w, "user not found", http.StatusNotFound)
+type updateUserRequest struct {
+	FirstName   *string `json:"firstName"`
+	LastName    *string `json:"lastName"`
+	Country     *string `json:"country"`
+	DateOfBirth *string `json:"dateOfBirth"` // RFC3339 or YYYY-MM-DD; "" clears it
+}
+
+func (req updateUserRequest) Validate() error {
+	var v validate.Errors
+	if req.FirstName != nil {
+		v.MaxLength("firstName", *req.FirstName, 255)
+	}
+	if req.LastName != nil {
+		v.MaxLength("lastName", *req.LastName, 255)
+	}
+	if req.Country != nil {
+		v.MaxLength("country", *req.Country, 100)
+	}
+	return v.Err()
+}
+
+// UpdateUser handles PATCH /users/{id}. Only fields present in the body change.
+func (h *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
+	id, ok := ownUserID(w, r)
+	if !ok {
+		return
+	}
+	var req updateUserRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	upd := models.ProfileUpdate{
+		FirstName: req.FirstName,
+		LastName:  req.LastName,
+		Country:   req.Country,
+	}
+	if req.DateOfBirth != nil {
+		if *req.DateOfBirth == "" {
+			upd.ClearDateOfBirth = true
 		} else {
-			http.Error(w, "internal server error", http.StatusInternalServerError)
+			dob, _, err := parseDateParam(*req.DateOfBirth)
+			if err != nil {
+				apierror.Write(w, r, apierror.InvalidParam("dateOfBirth").With("format", "RFC3339 or YYYY-MM-DD"))
+				return
+			}
+			upd.DateOfBirth = &dob
 		}
-		return
 	}
 
-	// 5) Write JSON
+	user, err := h.userService.UpdateProfile(r.Context(), id, upd)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
 	w.Header().Set("Content-Type", "application/json")
-	err = json.NewEncoder(w).Encode(struct {
-		ID       int64  `json:"id"`
-		Username string `json:"username"`
-		Email    string `json:"email"`
-	}{
-		ID:       user.ID,
-		Username: user.Username,
-		Email:    user.Email,
-	})
+	err = json.NewEncoder(w).Encode(newUserDetailResponse(user))
 	if err != nil {
 		return
 	}
 }
+
+type changePasswordRequest struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
+func (req changePasswordRequest) Validate() error {
+	var v validate.Errors
+	v.Required("current_password", req.CurrentPassword)
+	checkNewPassword(&v, "new_password", req.NewPassword)
+	return v.Err()
+}
+
+// ChangePassword handles POST /users/{id}/password. Every other session of
+// the user is logged out; the calling one stays.
+func (h *UserHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	id, ok := ownUserID(w, r)
+	if !ok {
+		return
+	}
+	var req changePasswordRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	var sessionID int64
+	if p := middleware.PrincipalFromContext(r.Context()); p != nil {
+		sessionID = p.SessionID
+	}
+	if err := h.accountService.ChangePassword(r.Context(), id, sessionID, req.CurrentPassword, req.NewPassword); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type changeEmailRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+func (req changeEmailRequest) Validate() error {
+	var v validate.Errors
+	v.Email("email", req.Email)
+	v.MaxLength("email", req.Email, 255)
+	v.Required("password", req.Password)
+	return v.Err()
+}
+
+// ChangeEmail handles POST /users/{id}/email. The change takes effect once
+// the link mailed to the new address is followed.
+func (h *UserHandler) ChangeEmail(w http.ResponseWriter, r *http.Request) {
+	id, ok := ownUserID(w, r)
+	if !ok {
+		return
+	}
+	var req changeEmailRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	if err := h.accountService.RequestEmailChange(r.Context(), id, req.Password, req.Email); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+type deleteUserRequest struct {
+	Password string `json:"password"`
+}
+
+func (req deleteUserRequest) Validate() error {
+	var v validate.Errors
+	v.Required("password", req.Password)
+	return v.Err()
+}
+
+// DeleteUser handles DELETE /users/{id}. Personal data is anonymised; orders
+// are kept for accounting.
+func (h *UserHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
+	id, ok := ownUserID(w, r)
+	if !ok {
+		return
+	}
+	var req deleteUserRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	if err := h.accountService.DeleteAccount(r.Context(), id, req.Password); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ownUserID parses {id} and checks it is the caller's own. On failure it
+// has already written the response.
+func ownUserID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	caller := middleware.FromContext(r.Context())
+	if caller == 0 {
+		apierror.Write(w, r, apierror.ErrUnauthorized)
+		return 0, false
+	}
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		apierror.Write(w, r, apierror.InvalidParam("id"))
+		return 0, false
+	}
+	if caller != id {
+		apierror.Write(w, r, apierror.ErrForbidden)
+		return 0, false
+	}
+	return id, true
+}
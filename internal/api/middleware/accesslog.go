@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"richisntreal-backend/internal/api/auth"
+	"richisntreal-backend/internal/infrastructure/logging"
+)
+
+const accessRecordKey ctxKey = "accessRecord"
+
+// accessRecord collects what later middleware learns about a request, such
+// as who made it, for the access log line written once it is done.
+type accessRecord struct {
+	userID         int64
+	impersonatorID int64
+	apiKeyID       int64
+}
+
+// AccessLog writes one log line per request with its status, size,
+// latency and caller. It must run after RequestID to include the ID.
+func AccessLog(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &accessRecord{}
+		sw := &statusWriter{ResponseWriter: w}
+		next.ServeHTTP(sw, r.WithContext(context.WithValue(r.Context(), accessRecordKey, rec)))
+
+		attrs := []any{
+			"method", r.Method,
+			"path", r.URL.Path,
+			"status", sw.Status(),
+			"bytes", sw.bytes,
+			"duration_ms", float64(time.Since(start).Microseconds()) / 1000,
+			"ip", ClientIP(r),
+		}
+		if rec.userID != 0 {
+			attrs = append(attrs, "user_id", rec.userID)
+		}
+		if rec.impersonatorID != 0 {
+			attrs = append(attrs, "impersonator_id", rec.impersonatorID)
+		}
+		if rec.apiKeyID != 0 {
+			attrs = append(attrs, "api_key_id", rec.apiKeyID)
+		}
+		level := slog.LevelInfo
+		if sw.Status() >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		logging.FromContext(r.Context()).Log(r.Context(), level, "request", attrs...)
+	})
+}
+
+// noteCaller tells the access log and the request's logger who is calling.
+func noteCaller(ctx context.Context, p *auth.Principal) context.Context {
+	if rec, ok := ctx.Value(accessRecordKey).(*accessRecord); ok {
+		rec.userID = p.UserID
+		rec.impersonatorID = p.ImpersonatorID
+		rec.apiKeyID = p.APIKeyID
+	}
+	return logging.With(ctx, "user_id", p.UserID)
+}
+
+// statusWriter remembers the status code and byte count of a response.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += n
+	return n, err
+}
+
+// Status is the status sent, 200 if the handler wrote nothing at all.
+func (w *statusWriter) Status() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
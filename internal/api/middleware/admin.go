@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"richisntreal-backend/internal/api/apierror"
+)
+
+const IsAdminKey ctxKey = "isAdmin"
+
+// RoleResolver tells whether a user holds the admin role.
+type RoleResolver interface {
+	IsAdmin(ctx context.Context, userID int64) (bool, error)
+}
+
+// ResolveRole looks up the caller's role and stores it in context.
+// It must run after AuthMiddleware.
+func ResolveRole(rr RoleResolver) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			admin, err := rr.IsAdmin(r.Context(), FromContext(r.Context()))
+			if err != nil {
+				apierror.Write(w, r, apierror.ErrUnauthorized)
+				return
+			}
+			ctx := context.WithValue(r.Context(), IsAdminKey, admin)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// RequireAdmin only lets admins through. It must run after AuthMiddleware.
+func RequireAdmin(rr RoleResolver) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return ResolveRole(rr)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !IsAdmin(r.Context()) {
+				apierror.Write(w, r, apierror.ErrForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		}))
+	}
+}
+
+// IsAdmin reports whether the caller was resolved as an admin.
+func IsAdmin(ctx context.Context) bool {
+	admin, _ := ctx.Value(IsAdminKey).(bool)
+	return admin
+}
@@ -2,25 +2,34 @@ package middleware
 
 import (
 	"context"
+	"errors"
 	"net/http"
 
+	"richisntreal-backend/internal/api/apierror"
 	"richisntreal-backend/internal/api/auth"
 )
 
 type ctxKey string
 
 const UserIDKey ctxKey = "userID"
+const PrincipalKey ctxKey = "principal"
 
 // AuthMiddleware injects an Authenticator and sets userID in context.
 func AuthMiddleware(a auth.Authenticator) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			uid, err := a.Authenticate(r)
+			p, err := a.Authenticate(r)
+			if errors.Is(err, auth.ErrAccountDisabled) {
+				apierror.Write(w, r, err)
+				return
+			}
 			if err != nil {
-				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				apierror.Write(w, r, apierror.ErrUnauthorized)
 				return
 			}
-			ctx := context.WithValue(r.Context(), UserIDKey, uid)
+			ctx := noteCaller(r.Context(), p)
+			ctx = context.WithValue(ctx, UserIDKey, p.UserID)
+			ctx = context.WithValue(ctx, PrincipalKey, p)
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
@@ -35,3 +44,36 @@ func FromContext(ctx context.Context) int64 {
 	}
 	return 0
 }
+
+// PrincipalFromContext retrieves the authenticated caller, or nil.
+func PrincipalFromContext(ctx context.Context) *auth.Principal {
+	p, _ := ctx.Value(PrincipalKey).(*auth.Principal)
+	return p
+}
+
+// RequireScope rejects API keys that weren't granted scope. Session logins
+// pass. It must run after AuthMiddleware.
+func RequireScope(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			p := PrincipalFromContext(r.Context())
+			if p == nil || !p.HasScope(scope) {
+				apierror.Write(w, r, apierror.New(http.StatusForbidden, "missing_scope", "this API key lacks the "+scope+" scope").With("scope", scope))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// DenyImpersonation keeps admins acting as a user away from the user's
+// credentials. It must run after AuthMiddleware.
+func DenyImpersonation(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if p := PrincipalFromContext(r.Context()); p != nil && p.ImpersonatorID != 0 {
+			apierror.Write(w, r, apierror.New(http.StatusForbidden, "impersonation_denied", "not allowed while impersonating"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// unmatchedRoute labels requests no route matched, so probes for random
+// paths all land in one series.
+const unmatchedRoute = "unmatched"
+
+// HTTPObserver records finished requests; metrics.Metrics implements it.
+type HTTPObserver interface {
+	ObserveHTTP(method, route string, status int, d time.Duration)
+}
+
+// Metrics reports every request to o, labelled by the chi route pattern
+// that handled it. Mount it on the root router: chi fills in the pattern
+// while routing, so it is only known once the request is done.
+func Metrics(o HTTPObserver) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			sw := &statusWriter{ResponseWriter: w}
+			next.ServeHTTP(sw, r)
+
+			o.ObserveHTTP(r.Method, routePattern(r), sw.Status(), time.Since(start))
+		})
+	}
+}
+
+// routePattern is the chi pattern that served r, once routing is done.
+func routePattern(r *http.Request) string {
+	if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+		return rctx.RoutePattern()
+	}
+	return unmatchedRoute
+}
@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"richisntreal-backend/internal/api/apierror"
+)
+
+// RateLimiter decides whether another request under key may go through.
+// When it may not, it also says how long until one would.
+type RateLimiter interface {
+	Allow(key string) (bool, time.Duration)
+}
+
+// RateLimitByIP rejects requests from a client IP once limiter runs dry.
+// scope keeps separate budgets for endpoints sharing one limiter.
+func RateLimitByIP(limiter RateLimiter, scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if ok, wait := limiter.Allow(scope + ":ip:" + ClientIP(r)); !ok {
+				TooManyRequests(w, r, wait)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// TooManyRequests answers 429 with a Retry-After header in whole seconds.
+func TooManyRequests(w http.ResponseWriter, r *http.Request, retryAfter time.Duration) {
+	secs := int(math.Ceil(retryAfter.Seconds()))
+	if secs < 1 {
+		secs = 1
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(secs))
+	apierror.Write(w, r, apierror.ErrTooManyRequests)
+}
+
+// ClientIP returns the IP the request came from. It trusts RemoteAddr only;
+// put chi's RealIP in front when running behind a trusted proxy.
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+type timeoutKey struct{}
+
+// timeout remembers what Timeout started from, for RenewTimeout.
+type timeout struct {
+	parent context.Context
+	d      time.Duration
+}
+
+// Timeout puts a deadline on the request context. Every query runs under
+// that context, so a slow database gives up after d instead of holding the
+// connection. Payment gateway calls are detached from it and have their own
+// budget. A zero d leaves requests unbounded.
+func Timeout(d time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if d <= 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			parent := r.Context()
+			ctx, cancel := context.WithTimeout(context.WithValue(parent, timeoutKey{}, timeout{parent, d}), d)
+			defer cancel()
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// RenewTimeout gives ctx a fresh request deadline, for handlers such as a
+// streamed export whose queries are each quick but together outlast it.
+// The result keeps ctx's values and is still cancelled when the client
+// goes away.
+func RenewTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	t, ok := ctx.Value(timeoutKey{}).(timeout)
+	if !ok {
+		return context.WithCancel(ctx)
+	}
+	fresh, cancel := context.WithTimeout(context.WithoutCancel(ctx), t.d)
+	stop := context.AfterFunc(t.parent, cancel)
+	return fresh, func() {
+		stop()
+		cancel()
+	}
+}
@@ -0,0 +1,22 @@
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Undocumented lists the "METHOD path" of every route registered on r that
+// the document doesn't describe.
+func (d *Document) Undocumented(r chi.Routes) ([]string, error) {
+	var missing []string
+	err := chi.Walk(r, func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		if _, ok := d.Paths[route][strings.ToLower(method)]; !ok {
+			missing = append(missing, fmt.Sprintf("%s %s", method, route))
+		}
+		return nil
+	})
+	return missing, err
+}
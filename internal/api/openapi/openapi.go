@@ -0,0 +1,431 @@
+package openapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"richisntreal-backend/internal/api/apierror"
+	"richisntreal-backend/internal/api/validate"
+)
+
+// Version is the OpenAPI version documents are written against.
+const Version = "3.1.0"
+
+// Document is an OpenAPI document, trimmed to the parts this API uses.
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Tags       []Tag               `json:"tags,omitempty"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+// Info describes the API as a whole.
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// Tag groups operations in the docs UI.
+type Tag struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// PathItem holds the operations on one path, keyed by lower-case method.
+type PathItem map[string]*Operation
+
+// Operation is one method on one path.
+type Operation struct {
+	OperationID string                `json:"operationId,omitempty"`
+	Summary     string                `json:"summary,omitempty"`
+	Description string                `json:"description,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]Response   `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+}
+
+// Parameter is a path, query or header parameter.
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// RequestBody is the payload an operation accepts.
+type RequestBody struct {
+	Required bool                 `json:"required,omitempty"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// Response is one possible answer of an operation.
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// MediaType is the schema of a body in one content type.
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Schema is the JSON Schema subset the generated documents use.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	OneOf                []*Schema          `json:"oneOf,omitempty"`
+}
+
+// Components holds the schemas and security schemes operations refer to.
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas,omitempty"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+// SecurityScheme is a way of authenticating.
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+	In           string `json:"in,omitempty"`
+	Name         string `json:"name,omitempty"`
+	Description  string `json:"description,omitempty"`
+}
+
+// problemSchema is where every error response points.
+const problemSchema = "Problem"
+
+// pathParam matches the {name} segments of a chi pattern.
+var pathParam = regexp.MustCompile(`\{([^}/]+)\}`)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// Builder assembles a Document. Schemas are derived from Go values by
+// reflection, following encoding/json's rules, so they can't disagree with
+// what the handlers actually send.
+type Builder struct {
+	doc   *Document
+	names map[reflect.Type]string
+}
+
+// NewBuilder starts a document.
+func NewBuilder(info Info) *Builder {
+	b := &Builder{
+		doc: &Document{
+			OpenAPI: Version,
+			Info:    info,
+			Paths:   make(map[string]PathItem),
+			Components: Components{
+				Schemas:         make(map[string]*Schema),
+				SecuritySchemes: make(map[string]SecurityScheme),
+			},
+		},
+		names: make(map[reflect.Type]string),
+	}
+	b.Schema(apierror.Problem{}) // named problemSchema
+	return b
+}
+
+// Tag adds a tag with its description.
+func (b *Builder) Tag(name, description string) {
+	b.doc.Tags = append(b.doc.Tags, Tag{Name: name, Description: description})
+}
+
+// SecurityScheme registers a way of authenticating under name.
+func (b *Builder) SecurityScheme(name string, s SecurityScheme) {
+	b.doc.Components.SecuritySchemes[name] = s
+}
+
+// Document returns the assembled document.
+func (b *Builder) Document() *Document {
+	return b.doc
+}
+
+// Op adds the operation method path and returns it for describing. Path
+// parameters are taken from the chi pattern.
+func (b *Builder) Op(method, path, tag, summary string) *Op {
+	op := &Operation{
+		OperationID: operationID(method, path),
+		Summary:     summary,
+		Tags:        []string{tag},
+		Responses:   make(map[string]Response),
+	}
+	for _, m := range pathParam.FindAllStringSubmatch(path, -1) {
+		op.Parameters = append(op.Parameters, Parameter{
+			Name:     m[1],
+			In:       "path",
+			Required: true,
+			Schema:   &Schema{Type: "string"},
+		})
+	}
+	item, ok := b.doc.Paths[path]
+	if !ok {
+		item = make(PathItem)
+		b.doc.Paths[path] = item
+	}
+	item[strings.ToLower(method)] = op
+	return &Op{b: b, op: op}
+}
+
+// Schema returns the schema of v's type: a reference for named structs,
+// which are added to the components on first use, and inline otherwise.
+func (b *Builder) Schema(v any) *Schema {
+	return b.schema(reflect.TypeOf(v))
+}
+
+func (b *Builder) schema(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || t.Name() == "" || t == timeType {
+		return b.inline(t)
+	}
+	if name, ok := b.names[t]; ok {
+		return &Schema{Ref: "#/components/schemas/" + name}
+	}
+
+	// 1) Name it after the type, qualified by package if two types share a name
+	name := exported(t.Name())
+	if _, taken := b.doc.Components.Schemas[name]; taken {
+		pkg := t.PkgPath()[strings.LastIndex(t.PkgPath(), "/")+1:]
+		name = exported(pkg) + name
+	}
+
+	// 2) Register before descending, so self-referencing types terminate
+	b.names[t] = name
+	b.doc.Components.Schemas[name] = &Schema{}
+	s := b.inline(t)
+	if required, ok := validatorRequired(t); ok {
+		s.Required = required
+	}
+	b.doc.Components.Schemas[name] = s
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+func (b *Builder) inline(t reflect.Type) *Schema {
+	if t == timeType {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		return b.schema(t.Elem())
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: b.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: b.schema(t.Elem())}
+	case reflect.Struct:
+		s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+		b.fields(s, t)
+		return s
+	default:
+		// interface{} and friends: anything goes
+		return &Schema{}
+	}
+}
+
+// fields adds t's JSON fields to s, flattening embedded structs the way
+// encoding/json does.
+func (b *Builder) fields(s *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" || (!f.IsExported() && !f.Anonymous) {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		ft := f.Type
+		if f.Anonymous && name == "" {
+			for ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				b.fields(s, ft)
+				continue
+			}
+		}
+		if name == "" {
+			name = f.Name
+		}
+		s.Properties[name] = b.schema(ft)
+		if !strings.Contains(opts, "omitempty") && ft.Kind() != reflect.Pointer {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
+
+// validatorRequired lists the fields of a payload type that its Validate
+// rejects when left out: a missing field decodes to its zero value, so
+// those are exactly what the zero value fails on. The json tags alone
+// would call every field of a payload required.
+func validatorRequired(t reflect.Type) ([]string, bool) {
+	zero, ok := reflect.Zero(t).Interface().(validate.Validator)
+	if !ok {
+		return nil, false
+	}
+	var e *apierror.Error
+	if !errors.As(zero.Validate(), &e) {
+		return nil, true
+	}
+	fields, _ := e.Details["fields"].(map[string]string)
+	var required []string
+	for field := range fields {
+		// nested fields, e.g. "items[0].quantity", belong to other schemas
+		if !strings.ContainsAny(field, ".[") {
+			required = append(required, field)
+		}
+	}
+	sort.Strings(required)
+	return required, true
+}
+
+// Op describes an operation added with Builder.Op.
+type Op struct {
+	b  *Builder
+	op *Operation
+}
+
+// Describe sets the operation's longer description.
+func (o *Op) Describe(description string) *Op {
+	o.op.Description = description
+	return o
+}
+
+// Auth lists the security schemes that are accepted, any one of them.
+func (o *Op) Auth(schemes ...string) *Op {
+	for _, s := range schemes {
+		o.op.Security = append(o.op.Security, map[string][]string{s: {}})
+	}
+	return o
+}
+
+// Query documents an optional query parameter.
+func (o *Op) Query(name, typ, description string) *Op {
+	o.op.Parameters = append(o.op.Parameters, Parameter{
+		Name:        name,
+		In:          "query",
+		Description: description,
+		Schema:      &Schema{Type: typ},
+	})
+	return o
+}
+
+// Body documents a required JSON request body shaped like v.
+func (o *Op) Body(v any) *Op {
+	o.op.RequestBody = &RequestBody{
+		Required: true,
+		Content:  map[string]MediaType{"application/json": {Schema: o.b.Schema(v)}},
+	}
+	return o
+}
+
+// OptionalBody documents a JSON request body that may be left out.
+func (o *Op) OptionalBody(v any) *Op {
+	o.Body(v)
+	o.op.RequestBody.Required = false
+	return o
+}
+
+// Returns documents a JSON response shaped like v. With several values the
+// response is any one of them.
+func (o *Op) Returns(status int, description string, v ...any) *Op {
+	s := o.b.Schema(v[0])
+	if len(v) > 1 {
+		s = &Schema{}
+		for _, alt := range v {
+			s.OneOf = append(s.OneOf, o.b.Schema(alt))
+		}
+	}
+	return o.Content(status, description, "application/json", s)
+}
+
+// Content documents a response in another media type, e.g. a file download.
+func (o *Op) Content(status int, description, contentType string, s *Schema) *Op {
+	o.op.Responses[strconv.Itoa(status)] = Response{
+		Description: description,
+		Content:     map[string]MediaType{contentType: {Schema: s}},
+	}
+	return o
+}
+
+// NoContent documents a response without a body.
+func (o *Op) NoContent(status int, description string) *Op {
+	o.op.Responses[strconv.Itoa(status)] = Response{Description: description}
+	return o
+}
+
+// Errors documents problem responses with the given statuses.
+func (o *Op) Errors(statuses ...int) *Op {
+	for _, status := range statuses {
+		o.op.Responses[strconv.Itoa(status)] = Response{
+			Description: http.StatusText(status),
+			Content: map[string]MediaType{apierror.ContentType: {
+				Schema: &Schema{Ref: "#/components/schemas/" + problemSchema},
+			}},
+		}
+	}
+	return o
+}
+
+// operationID derives a stable ID, e.g. "post_users_id_password".
+func operationID(method, path string) string {
+	id := strings.ToLower(method)
+	for _, seg := range strings.Split(path, "/") {
+		seg = strings.Trim(seg, "{}")
+		seg = strings.NewReplacer(".", "_", "-", "_").Replace(seg)
+		if seg != "" {
+			id += "_" + seg
+		}
+	}
+	return id
+}
+
+func exported(name string) string {
+	if name == "" {
+		return name
+	}
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
+// Routes lists every "METHOD path" in the document, sorted.
+func (d *Document) Routes() []string {
+	var out []string
+	for path, item := range d.Paths {
+		for method := range item {
+			out = append(out, fmt.Sprintf("%s %s", strings.ToUpper(method), path))
+		}
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,29 @@
+package routes
+
+import (
+	"github.com/go-chi/chi/v5"
+	"richisntreal-backend/internal/api/auth"
+	"richisntreal-backend/internal/api/handlers"
+	"richisntreal-backend/internal/api/middleware"
+)
+
+// RegisterAccountRoutes wires up password reset and email verification.
+func RegisterAccountRoutes(
+	r chi.Router,
+	h *handlers.AccountHandler,
+	jwtAuth auth.Authenticator,
+	limiter middleware.RateLimiter,
+) {
+	// public, throttled per client IP
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.RateLimitByIP(limiter, "account"))
+		r.Post("/password/forgot", h.ForgotPassword)
+		r.Post("/password/reset", h.ResetPassword)
+		r.Post("/email/verify", h.VerifyEmail)
+		r.Post("/email/change/confirm", h.ConfirmEmailChange)
+	})
+
+	// private
+	r.With(middleware.AuthMiddleware(jwtAuth)).
+		Post("/email/verify/resend", h.ResendVerification)
+}
@@ -0,0 +1,31 @@
+package routes
+
+import (
+	"github.com/go-chi/chi/v5"
+	"richisntreal-backend/internal/api/auth"
+	"richisntreal-backend/internal/api/handlers"
+	"richisntreal-backend/internal/api/middleware"
+	"richisntreal-backend/internal/core/domain/models"
+)
+
+// RegisterAdminOrderRoutes wires up the back-office order endpoints.
+// authn may accept API keys of admins; each route names the scope a key needs.
+func RegisterAdminOrderRoutes(
+	r chi.Router,
+	h *handlers.AdminOrderHandler,
+	authn auth.Authenticator,
+	roles middleware.RoleResolver,
+) {
+	read := middleware.RequireScope(models.ScopeOrdersRead)
+	write := middleware.RequireScope(models.ScopeOrdersWrite)
+
+	r.Route("/admin/orders", func(r chi.Router) {
+		r.Use(middleware.AuthMiddleware(authn))
+		r.Use(middleware.RequireAdmin(roles))
+		r.With(read).Get("/", h.ListOrders)
+		r.With(read).Get("/export.csv", h.ExportOrders)
+		r.With(read).Get("/{orderID}", h.GetOrder)
+		r.With(write).Post("/{orderID}/notes", h.AddNote)
+		r.With(write).Post("/{orderID}/status", h.ChangeStatus)
+	})
+}
@@ -0,0 +1,31 @@
+package routes
+
+import (
+	"github.com/go-chi/chi/v5"
+	"richisntreal-backend/internal/api/auth"
+	"richisntreal-backend/internal/api/handlers"
+	"richisntreal-backend/internal/api/middleware"
+)
+
+// RegisterAdminUserRoutes wires up the support endpoints for users. They
+// share the /admin/users prefix with the privacy routes, hence a Group
+// rather than a mounted sub-router.
+func RegisterAdminUserRoutes(
+	r chi.Router,
+	h *handlers.AdminUserHandler,
+	jwtAuth auth.Authenticator,
+	roles middleware.RoleResolver,
+) {
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.AuthMiddleware(jwtAuth))
+		r.Use(middleware.RequireAdmin(roles))
+		r.Get("/admin/users", h.ListUsers)
+		r.Get("/admin/users/{id}", h.GetUser)
+		r.Get("/admin/users/{id}/orders", h.ListUserOrders)
+		r.Get("/admin/users/{id}/cart", h.GetUserCart)
+		r.Post("/admin/users/{id}/disable", h.DisableUser)
+		r.Post("/admin/users/{id}/enable", h.EnableUser)
+		r.Post("/admin/users/{id}/impersonate", h.Impersonate)
+		r.Get("/admin/audit-log", h.AuditLog)
+	})
+}
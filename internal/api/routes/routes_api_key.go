@@ -0,0 +1,24 @@
+package routes
+
+import (
+	"github.com/go-chi/chi/v5"
+	"richisntreal-backend/internal/api/auth"
+	"richisntreal-backend/internal/api/handlers"
+	"richisntreal-backend/internal/api/middleware"
+)
+
+// RegisterAPIKeyRoutes wires up API key management. Pass the session
+// authenticator: an API key can't be used to mint or revoke keys.
+func RegisterAPIKeyRoutes(
+	r chi.Router,
+	h *handlers.APIKeyHandler,
+	jwtAuth auth.Authenticator,
+) {
+	r.Route("/api-keys", func(r chi.Router) {
+		r.Use(middleware.AuthMiddleware(jwtAuth))
+		r.Use(middleware.DenyImpersonation)
+		r.Post("/", h.Create)
+		r.Get("/", h.List)
+		r.Delete("/{keyID}", h.Revoke)
+	})
+}
@@ -0,0 +1,14 @@
+package routes
+
+import (
+	"github.com/go-chi/chi/v5"
+	"richisntreal-backend/internal/api/handlers"
+)
+
+// RegisterDocsRoutes wires up the OpenAPI document and the docs UI.
+func RegisterDocsRoutes(r chi.Router, h *handlers.DocsHandler) {
+	r.Get("/openapi.json", h.OpenAPI)
+	r.Get("/docs", h.UI)
+	r.Get("/docs/swagger-ui.css", h.Asset("swagger-ui.css"))
+	r.Get("/docs/swagger-ui-bundle.js", h.Asset("swagger-ui-bundle.js"))
+}
@@ -0,0 +1,12 @@
+package routes
+
+import (
+	"github.com/go-chi/chi/v5"
+	"richisntreal-backend/internal/api/handlers"
+)
+
+// RegisterHealthRoutes wires up the liveness and readiness probes.
+func RegisterHealthRoutes(r chi.Router, h *handlers.HealthHandler) {
+	r.Get("/healthz", h.Live)
+	r.Get("/readyz", h.Ready)
+}
@@ -0,0 +1,24 @@
+package routes
+
+import (
+	"github.com/go-chi/chi/v5"
+	"richisntreal-backend/internal/api/auth"
+	"richisntreal-backend/internal/api/handlers"
+	"richisntreal-backend/internal/api/middleware"
+	"richisntreal-backend/internal/core/domain/models"
+)
+
+// RegisterInvoiceRoutes wires up invoice downloads for owners and staff.
+// authn may accept API keys with the invoices:read scope.
+func RegisterInvoiceRoutes(
+	r chi.Router,
+	h *handlers.InvoiceHandler,
+	authn auth.Authenticator,
+	roles middleware.RoleResolver,
+) {
+	r.With(
+		middleware.AuthMiddleware(authn),
+		middleware.RequireScope(models.ScopeInvoicesRead),
+		middleware.ResolveRole(roles),
+	).Get("/orders/{orderID}/invoice.pdf", h.GetInvoicePDF)
+}
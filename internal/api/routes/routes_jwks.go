@@ -0,0 +1,11 @@
+package routes
+
+import (
+	"github.com/go-chi/chi/v5"
+	"richisntreal-backend/internal/api/handlers"
+)
+
+// RegisterJWKSRoutes publishes the token verification keys.
+func RegisterJWKSRoutes(r chi.Router, h *handlers.JWKSHandler) {
+	r.Get("/.well-known/jwks.json", h.GetJWKS)
+}
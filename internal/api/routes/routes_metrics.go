@@ -0,0 +1,13 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// RegisterMetricsRoutes exposes the Prometheus scrape endpoint. It carries
+// no auth; keep /metrics off the public internet at the proxy.
+func RegisterMetricsRoutes(r chi.Router, metrics http.Handler) {
+	r.Method(http.MethodGet, "/metrics", metrics)
+}
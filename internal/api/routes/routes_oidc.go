@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"github.com/go-chi/chi/v5"
+	"richisntreal-backend/internal/api/handlers"
+	"richisntreal-backend/internal/api/middleware"
+)
+
+// RegisterOIDCRoutes wires up social login through OpenID Connect providers.
+func RegisterOIDCRoutes(
+	r chi.Router,
+	h *handlers.OIDCHandler,
+	limiter middleware.RateLimiter,
+) {
+	r.Route("/auth/oidc", func(r chi.Router) {
+		r.Get("/providers", h.ListProviders)
+		r.With(middleware.RateLimitByIP(limiter, "login")).
+			Get("/{provider}/authorize", h.Authorize)
+		r.With(middleware.RateLimitByIP(limiter, "login")).
+			Get("/{provider}/callback", h.Callback)
+	})
+}
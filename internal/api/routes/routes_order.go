@@ -5,21 +5,31 @@ import (
 	"richisntreal-backend/internal/api/auth"
 	"richisntreal-backend/internal/api/handlers"
 	"richisntreal-backend/internal/api/middleware"
+	"richisntreal-backend/internal/core/domain/models"
 )
 
+// RegisterOrderRoutes wires up customer order endpoints. authn may accept
+// API keys; each route names the scope a key needs.
 func RegisterOrderRoutes(
 	r chi.Router,
 	h *handlers.OrderHandler,
-	jwtAuth auth.Authenticator,
+	authn auth.Authenticator,
 ) {
 	// user’s orders
 	r.Route("/users/{userID}/orders", func(r chi.Router) {
-		r.Use(middleware.AuthMiddleware(jwtAuth))
-		r.Post("/", h.CreateOrder)
-		r.Get("/", h.ListOrders)
+		r.Use(middleware.AuthMiddleware(authn))
+		r.With(middleware.RequireScope(models.ScopeOrdersWrite)).
+			Post("/", h.CreateOrder)
+		r.With(middleware.RequireScope(models.ScopeOrdersRead)).
+			Get("/", h.ListOrders)
 	})
 
+	// public: guests look up an order by reference + email
+	r.Post("/orders/lookup", h.LookupOrder)
+
 	// fetch any single order
-	r.With(middleware.AuthMiddleware(jwtAuth)).
+	r.With(middleware.AuthMiddleware(authn), middleware.RequireScope(models.ScopeOrdersRead)).
 		Get("/orders/{orderID}", h.GetOrder)
+	r.With(middleware.AuthMiddleware(authn), middleware.RequireScope(models.ScopeOrdersWrite)).
+		Post("/orders/{orderID}/cancel", h.CancelOrder)
 }
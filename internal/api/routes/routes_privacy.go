@@ -0,0 +1,26 @@
+package routes
+
+import (
+	"github.com/go-chi/chi/v5"
+	"richisntreal-backend/internal/api/auth"
+	"richisntreal-backend/internal/api/handlers"
+	"richisntreal-backend/internal/api/middleware"
+)
+
+// RegisterPrivacyRoutes wires up data export and erasure.
+func RegisterPrivacyRoutes(
+	r chi.Router,
+	h *handlers.PrivacyHandler,
+	jwtAuth auth.Authenticator,
+	roles middleware.RoleResolver,
+) {
+	r.With(middleware.AuthMiddleware(jwtAuth)).
+		Get("/users/{id}/export", h.ExportUser)
+
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.AuthMiddleware(jwtAuth))
+		r.Use(middleware.RequireAdmin(roles))
+		r.Get("/admin/users/{id}/export", h.AdminExportUser)
+		r.Post("/admin/users/{id}/erase", h.AdminEraseUser)
+	})
+}
@@ -5,22 +5,21 @@ import (
 	"richisntreal-backend/internal/api/auth"
 	"richisntreal-backend/internal/api/handlers"
 	"richisntreal-backend/internal/api/middleware"
+	"richisntreal-backend/internal/core/domain/models"
 )
 
 func RegisterProductRoutes(
 	r chi.Router,
 	h *handlers.ProductHandler,
-	jwtAuth auth.Authenticator,
+	authn auth.Authenticator,
 ) {
 	// public
 	r.Get("/products", h.List)
 	r.Get("/products/{id}", h.GetByID)
 
 	// admin
-	r.With(middleware.AuthMiddleware(jwtAuth)).
-		Post("/products", h.Create)
-	r.With(middleware.AuthMiddleware(jwtAuth)).
-		Put("/products/{id}", h.Update)
-	r.With(middleware.AuthMiddleware(jwtAuth)).
-		Delete("/products/{id}", h.Delete)
+	write := r.With(middleware.AuthMiddleware(authn), middleware.RequireScope(models.ScopeProductsWrite))
+	write.Post("/products", h.Create)
+	write.Put("/products/{id}", h.Update)
+	write.Delete("/products/{id}", h.Delete)
 }
@@ -0,0 +1,39 @@
+package routes
+
+import (
+	"github.com/go-chi/chi/v5"
+	"richisntreal-backend/internal/api/auth"
+	"richisntreal-backend/internal/api/handlers"
+	"richisntreal-backend/internal/api/middleware"
+	"richisntreal-backend/internal/core/domain/models"
+)
+
+// RegisterReturnRoutes wires up customer and staff endpoints for returns.
+// authn may accept API keys; each route names the scope a key needs.
+func RegisterReturnRoutes(
+	r chi.Router,
+	h *handlers.ReturnHandler,
+	authn auth.Authenticator,
+	roles middleware.RoleResolver,
+) {
+	read := middleware.RequireScope(models.ScopeReturnsRead)
+	write := middleware.RequireScope(models.ScopeReturnsWrite)
+
+	// customer
+	r.Route("/orders/{orderID}/returns", func(r chi.Router) {
+		r.Use(middleware.AuthMiddleware(authn))
+		r.With(write).Post("/", h.CreateReturn)
+		r.With(read).Get("/", h.ListOrderReturns)
+	})
+
+	// admin
+	r.Route("/admin/returns", func(r chi.Router) {
+		r.Use(middleware.AuthMiddleware(authn))
+		r.Use(middleware.RequireAdmin(roles))
+		r.With(read).Get("/", h.ListReturns)
+		r.With(read).Get("/{returnID}", h.GetReturn)
+		r.With(write).Post("/{returnID}/approve", h.Approve)
+		r.With(write).Post("/{returnID}/reject", h.Reject)
+		r.With(write).Post("/{returnID}/receive", h.MarkReceived)
+	})
+}
@@ -0,0 +1,25 @@
+package routes
+
+import (
+	"github.com/go-chi/chi/v5"
+	"richisntreal-backend/internal/api/auth"
+	"richisntreal-backend/internal/api/handlers"
+	"richisntreal-backend/internal/api/middleware"
+)
+
+// RegisterSessionRoutes wires up token refresh and logout.
+func RegisterSessionRoutes(
+	r chi.Router,
+	h *handlers.SessionHandler,
+	jwtAuth auth.Authenticator,
+) {
+	// public
+	r.Post("/token/refresh", h.Refresh)
+
+	// private
+	r.With(middleware.AuthMiddleware(jwtAuth)).
+		Post("/logout", h.Logout)
+	// support acting as a customer must not sign them out everywhere
+	r.With(middleware.AuthMiddleware(jwtAuth), middleware.DenyImpersonation).
+		Post("/logout/all", h.LogoutAll)
+}
@@ -0,0 +1,25 @@
+package routes
+
+import (
+	"github.com/go-chi/chi/v5"
+	"richisntreal-backend/internal/api/auth"
+	"richisntreal-backend/internal/api/handlers"
+	"richisntreal-backend/internal/api/middleware"
+)
+
+// RegisterTwoFactorRoutes wires up TOTP enrolment and management.
+func RegisterTwoFactorRoutes(
+	r chi.Router,
+	h *handlers.TwoFactorHandler,
+	jwtAuth auth.Authenticator,
+) {
+	r.Route("/2fa", func(r chi.Router) {
+		r.Use(middleware.AuthMiddleware(jwtAuth))
+		r.Use(middleware.DenyImpersonation)
+		r.Get("/", h.Status)
+		r.Post("/enroll", h.Enroll)
+		r.Post("/confirm", h.Confirm)
+		r.Post("/recovery-codes", h.RegenerateRecoveryCodes)
+		r.Post("/disable", h.Disable)
+	})
+}
@@ -12,12 +12,28 @@ func RegisterUserRoutes(
 	r chi.Router,
 	h *handlers.UserHandler,
 	jwtAuth auth.Authenticator,
+	limiter middleware.RateLimiter,
 ) {
-	// public
-	r.Post("/users", h.CreateUser)
-	r.Post("/login", h.Login)
+	// public, throttled per client IP
+	r.With(middleware.RateLimitByIP(limiter, "signup")).
+		Post("/users", h.CreateUser)
+	r.With(middleware.RateLimitByIP(limiter, "login")).
+		Post("/login", h.Login)
+	r.With(middleware.RateLimitByIP(limiter, "login")).
+		Post("/login/2fa", h.LoginTwoFactor)
 
 	// private
-	r.With(middleware.AuthMiddleware(jwtAuth)).
-		Get("/users/{id}", h.GetUser)
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.AuthMiddleware(jwtAuth))
+		r.Get("/users/{id}", h.GetUser)
+		r.Patch("/users/{id}", h.UpdateUser)
+
+		// credentials stay with the user, even when support is acting as them
+		r.Group(func(r chi.Router) {
+			r.Use(middleware.DenyImpersonation)
+			r.Delete("/users/{id}", h.DeleteUser)
+			r.Post("/users/{id}/password", h.ChangePassword)
+			r.Post("/users/{id}/email", h.ChangeEmail)
+		})
+	})
 }
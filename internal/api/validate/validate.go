@@ -0,0 +1,78 @@
+package validate
+
+import (
+	"net/http"
+	"net/mail"
+	"unicode/utf8"
+
+	"richisntreal-backend/internal/api/apierror"
+)
+
+// ErrValidation is answered when a payload parsed but its fields don't hold
+// up; the "fields" detail maps each JSON field name to what is wrong with it.
+var ErrValidation = apierror.New(http.StatusUnprocessableEntity, "validation_failed", "request has invalid fields")
+
+// Validator is implemented by request payloads that check their own fields.
+type Validator interface {
+	Validate() error
+}
+
+// Errors collects field errors so a client learns about all of them in one
+// response. Only the first message per field is kept. The zero value is
+// ready to use.
+type Errors struct {
+	fields map[string]string
+}
+
+// Check records msg against field unless ok holds.
+func (e *Errors) Check(ok bool, field, msg string) {
+	if ok {
+		return
+	}
+	if e.fields == nil {
+		e.fields = make(map[string]string)
+	}
+	if _, seen := e.fields[field]; !seen {
+		e.fields[field] = msg
+	}
+}
+
+// Required checks that a string field is present and not empty.
+func (e *Errors) Required(field, value string) {
+	e.Check(value != "", field, "is required")
+}
+
+// MaxLength checks that a string field has at most n characters.
+func (e *Errors) MaxLength(field, value string, n int) {
+	e.Check(utf8.RuneCountInString(value) <= n, field, "is too long")
+}
+
+// MinLength checks that a string field has at least n characters.
+func (e *Errors) MinLength(field, value string, n int) {
+	e.Check(utf8.RuneCountInString(value) >= n, field, "is too short")
+}
+
+// Email checks that a required field holds a bare email address.
+func (e *Errors) Email(field, value string) {
+	e.Required(field, value)
+	addr, err := mail.ParseAddress(value)
+	e.Check(err == nil && addr.Address == value && addr.Name == "", field, "must be an email address")
+}
+
+// Positive checks that a number field is greater than zero.
+func (e *Errors) Positive(field string, value int64) {
+	e.Check(value > 0, field, "must be greater than zero")
+}
+
+// NotNegative checks that a number field is zero or more.
+func (e *Errors) NotNegative(field string, value float64) {
+	e.Check(value >= 0, field, "must not be negative")
+}
+
+// Err is nil if every check passed, or ErrValidation listing the failures.
+func (e *Errors) Err() error {
+	if len(e.fields) == 0 {
+		return nil
+	}
+	return ErrValidation.With("fields", e.fields)
+}
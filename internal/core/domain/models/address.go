@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// Address kinds attached to an order.
+const (
+	AddressKindBilling = "billing"
+)
+
+// Address is a postal address captured on an order.
+type Address struct {
+	ID         int64     `db:"id" json:"-"`
+	OrderID    int64     `db:"order_id" json:"-"`
+	Kind       string    `db:"kind" json:"-"`
+	Name       string    `db:"name" json:"name"`
+	Line1      string    `db:"line1" json:"line1"`
+	Line2      string    `db:"line2" json:"line2,omitempty"`
+	City       string    `db:"city" json:"city"`
+	PostalCode string    `db:"postal_code" json:"postal_code"`
+	Country    string    `db:"country" json:"country"`
+	CreatedAt  time.Time `db:"created_at" json:"-"`
+	UpdatedAt  time.Time `db:"updated_at" json:"-"`
+}
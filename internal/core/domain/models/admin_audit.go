@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// Audited admin actions.
+const (
+	AuditUserDisable        = "user.disable"
+	AuditUserEnable         = "user.enable"
+	AuditImpersonationStart = "impersonation.start"
+	AuditImpersonatedAction = "impersonation.request"
+)
+
+// AuditEntry records something an admin did, to or as a user.
+type AuditEntry struct {
+	ID        int64     `db:"id" json:"id"`
+	AdminID   int64     `db:"admin_id" json:"admin_id"`
+	UserID    *int64    `db:"user_id" json:"user_id,omitempty"`
+	Action    string    `db:"action" json:"action"`
+	Detail    string    `db:"detail" json:"detail,omitempty"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+// AuditFilter narrows down an audit log search. Zero values mean "any".
+type AuditFilter struct {
+	AdminID int64
+	UserID  int64
+	Limit   int
+	Offset  int
+}
+
+// UserFilter narrows down a user search. Zero values mean "any".
+type UserFilter struct {
+	Query    string // matched against email, username and name
+	Role     string
+	Disabled *bool
+	Limit    int
+	Offset   int
+}
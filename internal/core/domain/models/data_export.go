@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// DataExport is everything stored about a user, as handed over on a data
+// access request.
+type DataExport struct {
+	UserID      int64               `json:"user_id"`
+	GeneratedAt time.Time           `json:"generated_at"`
+	Sections    []DataExportSection `json:"sections"`
+}
+
+// DataExportSection holds the rows of one kind of data, e.g. "orders".
+type DataExportSection struct {
+	Name string                   `json:"name"`
+	Rows []map[string]interface{} `json:"rows"`
+}
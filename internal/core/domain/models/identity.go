@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// UserIdentity links an account at an external OpenID Connect provider to
+// a local user.
+type UserIdentity struct {
+	ID        int64     `db:"id" json:"id"`
+	UserID    int64     `db:"user_id" json:"user_id"`
+	Provider  string    `db:"provider" json:"provider"`
+	Subject   string    `db:"subject" json:"-"`
+	Email     string    `db:"email" json:"email"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+// ExternalIdentity is who a provider's validated ID token says the user is.
+type ExternalIdentity struct {
+	Provider          string
+	Subject           string
+	Email             string
+	EmailVerified     bool
+	GivenName         string
+	FamilyName        string
+	PreferredUsername string
+}
+
+// OIDCLoginState is the server-side half of a login in flight: what the
+// browser's state parameter must be redeemed against.
+type OIDCLoginState struct {
+	ID           int64      `db:"id"`
+	Provider     string     `db:"provider"`
+	StateHash    string     `db:"state_hash"`
+	Nonce        string     `db:"nonce"`
+	CodeVerifier string     `db:"code_verifier"`
+	ExpiresAt    time.Time  `db:"expires_at"`
+	UsedAt       *time.Time `db:"used_at"`
+	CreatedAt    time.Time  `db:"created_at"`
+}
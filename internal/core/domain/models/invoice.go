@@ -0,0 +1,40 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// Invoice is the accounting record issued once an order is paid. Numbers
+// are allocated sequentially without gaps.
+type Invoice struct {
+	ID        int64     `db:"id" json:"id"`
+	OrderID   int64     `db:"order_id" json:"order_id"`
+	Number    int64     `db:"number" json:"number"`
+	IssuedAt  time.Time `db:"issued_at" json:"issued_at"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// Reference is the human-readable invoice number, e.g. "INV-000042".
+func (i *Invoice) Reference() string {
+	return fmt.Sprintf("INV-%06d", i.Number)
+}
+
+// InvoiceLine is an order line as printed on an invoice.
+type InvoiceLine struct {
+	ProductName string
+	SKU         string
+	Quantity    int
+	UnitPrice   float64
+	Total       float64
+}
+
+// InvoiceDocument gathers everything needed to render an invoice.
+type InvoiceDocument struct {
+	Invoice        Invoice
+	Order          Order
+	Lines          []InvoiceLine
+	BillingAddress *Address
+	Payment        *PaymentTransaction
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// Why a login attempt failed.
+const (
+	LoginFailureInvalidCredentials  = "invalid_credentials"
+	LoginFailureInvalidSecondFactor = "invalid_second_factor"
+	LoginFailureEmailNotVerified    = "email_not_verified"
+	LoginFailureLocked              = "locked"
+	LoginFailureAccountDisabled     = "account_disabled"
+	LoginFailureRateLimited         = "rate_limited"
+)
+
+// LoginAttempt is the audit record of one password or second-factor login.
+type LoginAttempt struct {
+	ID            int64     `db:"id" json:"id"`
+	UserID        *int64    `db:"user_id" json:"user_id,omitempty"`
+	Email         string    `db:"email" json:"email"`
+	IP            string    `db:"ip" json:"ip"`
+	Success       bool      `db:"success" json:"success"`
+	FailureReason *string   `db:"failure_reason" json:"failure_reason,omitempty"`
+	CreatedAt     time.Time `db:"created_at" json:"created_at"`
+}
@@ -2,15 +2,79 @@ package models
 
 import "time"
 
+// Order statuses.
+const (
+	OrderStatusPending   = "pending"
+	OrderStatusPaying    = "paying" // claimed by a charge that is in flight
+	OrderStatusPaid      = "paid"
+	OrderStatusShipped   = "shipped"
+	OrderStatusDelivered = "delivered"
+	OrderStatusCancelled = "cancelled"
+)
+
+// orderTransitions lists the status changes staff may make by hand.
+// Cancelling goes through its own flow since it also settles the payment.
+// A charge that never reported back leaves its order paying; staff settle
+// it by hand once they have checked with the provider.
+var orderTransitions = map[string][]string{
+	OrderStatusPending: {OrderStatusPaid},
+	OrderStatusPaying:  {OrderStatusPaid, OrderStatusPending},
+	OrderStatusPaid:    {OrderStatusShipped},
+	OrderStatusShipped: {OrderStatusDelivered},
+}
+
 // Order represents a user's purchase.
 type Order struct {
-	ID        int64       `db:"id" json:"id"`
-	UserID    int64       `db:"user_id" json:"user_id"`
-	Total     float64     `db:"total" json:"total"`
-	Status    string      `db:"status" json:"status"`
-	CreatedAt time.Time   `db:"created_at" json:"created_at"`
-	UpdatedAt time.Time   `db:"updated_at" json:"updated_at"`
-	Items     []OrderItem `json:"items"`
+	ID                 int64       `db:"id" json:"id"`
+	Reference          string      `db:"reference" json:"reference"`
+	UserID             int64       `db:"user_id" json:"user_id"`
+	Total              float64     `db:"total" json:"total"`
+	Status             string      `db:"status" json:"status"`
+	CancellationReason *string     `db:"cancellation_reason" json:"cancellation_reason,omitempty"`
+	CancelledAt        *time.Time  `db:"cancelled_at" json:"cancelled_at,omitempty"`
+	CreatedAt          time.Time   `db:"created_at" json:"created_at"`
+	UpdatedAt          time.Time   `db:"updated_at" json:"updated_at"`
+	Items              []OrderItem `json:"items"`
+	BillingAddress     *Address    `json:"billing_address,omitempty"`
+}
+
+// Cancellable reports whether the order has not been fulfilled yet.
+func (o *Order) Cancellable() bool {
+	return o.Status == OrderStatusPending || o.Status == OrderStatusPaid
+}
+
+// CanTransition reports whether the order may move to the given status.
+func (o *Order) CanTransition(to string) bool {
+	for _, s := range orderTransitions[o.Status] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+// OrderFilter narrows down an order search. Zero values mean "any".
+type OrderFilter struct {
+	Status   string
+	UserID   int64
+	From     *time.Time
+	To       *time.Time
+	MinTotal *float64
+	MaxTotal *float64
+	Limit    int
+	Offset   int
+	// SkipCount spares the count of all matches for callers that ignore
+	// it; the total then comes back as 0.
+	SkipCount bool
+}
+
+// OrderNote is an internal staff note on an order; customers never see it.
+type OrderNote struct {
+	ID        int64     `db:"id" json:"id"`
+	OrderID   int64     `db:"order_id" json:"order_id"`
+	AuthorID  int64     `db:"author_id" json:"author_id"`
+	Body      string    `db:"body" json:"body"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
 }
 
 // OrderItem is a single line item in an order.
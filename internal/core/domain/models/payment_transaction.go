@@ -2,11 +2,23 @@ package models
 
 import "time"
 
+// Payment transaction statuses.
+const (
+	PaymentStatusPending   = "pending"
+	PaymentStatusSucceeded = "succeeded"
+	PaymentStatusFailed    = "failed"
+	PaymentStatusVoided    = "voided"
+	PaymentStatusRefunded  = "refunded"
+
+	PaymentStatusPartiallyRefunded = "partially_refunded"
+)
+
 // PaymentTransaction records an attempt to pay for an order.
 type PaymentTransaction struct {
 	ID             int64     `db:"id" json:"id"`
 	OrderID        int64     `db:"order_id" json:"order_id"`
 	Amount         float64   `db:"amount" json:"amount"`
+	RefundedAmount float64   `db:"refunded_amount" json:"refunded_amount"`
 	Currency       string    `db:"currency" json:"currency"`
 	Provider       string    `db:"provider" json:"provider"`
 	ProviderTxID   *string   `db:"provider_tx_id" json:"provider_tx_id,omitempty"`
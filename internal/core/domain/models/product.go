@@ -9,6 +9,7 @@ type Product struct {
 	Description string    `db:"description" json:"description"`
 	Price       float64   `db:"price" json:"price"`
 	SKU         string    `db:"sku" json:"sku"`
+	Stock       *int      `db:"stock" json:"stock,omitempty"` // nil means stock is not tracked
 	CreatedAt   time.Time `db:"created_at" json:"created_at"`
 	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
 }
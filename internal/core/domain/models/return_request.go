@@ -0,0 +1,62 @@
+package models
+
+import "time"
+
+// Return request statuses.
+const (
+	ReturnStatusRequested = "requested"
+	ReturnStatusApproved  = "approved"
+	ReturnStatusRejected  = "rejected"
+	ReturnStatusReceived  = "received"
+	ReturnStatusRefunding = "refunding" // refund claimed and sent; stays here if recording the outcome fails
+	ReturnStatusRefunded  = "refunded"
+	ReturnStatusRestocked = "restocked"
+)
+
+// How an approved return is settled once the goods are back.
+const (
+	ReturnResolutionRefund  = "refund"
+	ReturnResolutionRestock = "restock"
+)
+
+var returnTransitions = map[string][]string{
+	ReturnStatusRequested: {ReturnStatusApproved, ReturnStatusRejected},
+	ReturnStatusApproved:  {ReturnStatusReceived},
+	ReturnStatusReceived:  {ReturnStatusRefunding, ReturnStatusRestocked},
+	ReturnStatusRefunding: {ReturnStatusRefunded},
+}
+
+// ReturnRequest is a customer's request to send back lines of a delivered order.
+type ReturnRequest struct {
+	ID           int64        `db:"id" json:"id"`
+	OrderID      int64        `db:"order_id" json:"order_id"`
+	UserID       int64        `db:"user_id" json:"user_id"`
+	Status       string       `db:"status" json:"status"`
+	Reason       string       `db:"reason" json:"reason"`
+	Resolution   *string      `db:"resolution" json:"resolution,omitempty"`
+	AdminNote    *string      `db:"admin_note" json:"admin_note,omitempty"`
+	RefundAmount *float64     `db:"refund_amount" json:"refund_amount,omitempty"`
+	CreatedAt    time.Time    `db:"created_at" json:"created_at"`
+	UpdatedAt    time.Time    `db:"updated_at" json:"updated_at"`
+	Items        []ReturnItem `json:"items"`
+}
+
+// CanTransition reports whether the return may move to the given status.
+func (r *ReturnRequest) CanTransition(to string) bool {
+	for _, s := range returnTransitions[r.Status] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+// ReturnItem is a single order line (or part of one) being returned.
+type ReturnItem struct {
+	ID          int64     `db:"id" json:"id"`
+	ReturnID    int64     `db:"return_id" json:"return_id"`
+	OrderItemID int64     `db:"order_item_id" json:"order_item_id"`
+	Quantity    int       `db:"quantity" json:"quantity"`
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
+}
@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// Session is one login on one device. Every refresh token rotated from that
+// login belongs to the same session, so revoking it logs the device out.
+// An impersonation session is opened by an admin acting as the user; it
+// has no refresh token and ends at ExpiresAt.
+type Session struct {
+	ID             int64      `db:"id" json:"id"`
+	UserID         int64      `db:"user_id" json:"user_id"`
+	ImpersonatorID *int64     `db:"impersonator_id" json:"impersonator_id,omitempty"`
+	ExpiresAt      *time.Time `db:"expires_at" json:"expires_at,omitempty"`
+	RevokedAt      *time.Time `db:"revoked_at" json:"revoked_at,omitempty"`
+	RevokedReason  *string    `db:"revoked_reason" json:"revoked_reason,omitempty"`
+	CreatedAt      time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time  `db:"updated_at" json:"updated_at"`
+}
+
+// RefreshToken is a single-use credential for obtaining a new access token.
+// Only its SHA-256 hash is stored.
+type RefreshToken struct {
+	ID        int64      `db:"id"`
+	SessionID int64      `db:"session_id"`
+	TokenHash string     `db:"token_hash"`
+	ExpiresAt time.Time  `db:"expires_at"`
+	RotatedAt *time.Time `db:"rotated_at"`
+	CreatedAt time.Time  `db:"created_at"`
+}
+
+// TokenPair is what a client receives on login or refresh.
+type TokenPair struct {
+	AccessToken  string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"` // access token lifetime in seconds
+}
@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// TokenPurposeLoginChallenge marks the user token handed out after a correct
+// password when the account still needs its second factor.
+const TokenPurposeLoginChallenge = "login_challenge"
+
+// TOTPSecret is a user's authenticator app secret. It is pending until the
+// user proves their app works by confirming a first code.
+type TOTPSecret struct {
+	UserID       int64      `db:"user_id"`
+	Secret       string     `db:"secret"` // base32, as shown to the user
+	LastUsedStep int64      `db:"last_used_step"`
+	EnabledAt    *time.Time `db:"enabled_at"`
+	CreatedAt    time.Time  `db:"created_at"`
+	UpdatedAt    time.Time  `db:"updated_at"`
+}
+
+// Enabled reports whether the secret has been confirmed.
+func (t *TOTPSecret) Enabled() bool {
+	return t.EnabledAt != nil
+}
+
+// TOTPEnrollment is what a user scans into their authenticator app.
+type TOTPEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauth_uri"`
+}
+
+// LoginResult is the outcome of a login attempt with valid credentials:
+// either a session, or a challenge to be completed with a second factor.
+type LoginResult struct {
+	User               *User
+	Tokens             *TokenPair
+	ChallengeToken     string
+	ChallengeExpiresIn int64 // seconds
+}
@@ -2,16 +2,46 @@ package models
 
 import "time"
 
+// User roles.
+const (
+	RoleCustomer = "customer"
+	RoleAdmin    = "admin"
+)
+
 // User represents a system user.
 type User struct {
-	ID          int64      `db:"id"            json:"id"`
-	Username    string     `db:"username"      json:"username"`
-	Email       string     `db:"email"         json:"email"`
-	Password    string     `db:"password"      json:"-"`
-	FirstName   string     `db:"first_name"    json:"firstName,omitempty"`
-	LastName    string     `db:"last_name"     json:"lastName,omitempty"`
-	Country     string     `db:"country"       json:"country,omitempty"`
-	DateOfBirth *time.Time `db:"date_of_birth" json:"dateOfBirth,omitempty"`
-	CreatedAt   time.Time  `db:"created_at"    json:"createdAt"`
-	UpdatedAt   time.Time  `db:"updated_at"    json:"updatedAt"`
+	ID              int64      `db:"id"                json:"id"`
+	Username        string     `db:"username"          json:"username"`
+	Email           string     `db:"email"             json:"email"`
+	Password        string     `db:"password"          json:"-"`
+	FirstName       string     `db:"first_name"        json:"firstName,omitempty"`
+	LastName        string     `db:"last_name"         json:"lastName,omitempty"`
+	Country         string     `db:"country"           json:"country,omitempty"`
+	DateOfBirth     *time.Time `db:"date_of_birth"     json:"dateOfBirth,omitempty"`
+	Role            string     `db:"role"              json:"role"`
+	EmailVerifiedAt *time.Time `db:"email_verified_at" json:"emailVerifiedAt,omitempty"`
+	DisabledAt      *time.Time `db:"disabled_at"       json:"disabledAt,omitempty"`
+	DeletedAt       *time.Time `db:"deleted_at"        json:"-"`
+	CreatedAt       time.Time  `db:"created_at"        json:"createdAt"`
+	UpdatedAt       time.Time  `db:"updated_at"        json:"updatedAt"`
+}
+
+// ProfileUpdate holds the profile fields a user may change themselves.
+// Nil fields are left as they are.
+type ProfileUpdate struct {
+	FirstName        *string
+	LastName         *string
+	Country          *string
+	DateOfBirth      *time.Time
+	ClearDateOfBirth bool
+}
+
+// IsDisabled reports whether an admin has disabled the account.
+func (u *User) IsDisabled() bool {
+	return u.DisabledAt != nil
+}
+
+// IsAdmin reports whether the user holds the admin role.
+func (u *User) IsAdmin() bool {
+	return u.Role == RoleAdmin
 }
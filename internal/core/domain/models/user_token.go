@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// What a user token may be redeemed for.
+const (
+	TokenPurposePasswordReset     = "password_reset"
+	TokenPurposeEmailVerification = "email_verification"
+	TokenPurposeEmailChange       = "email_change"
+)
+
+// UserToken is a single-use, expiring secret mailed to a user. Only its
+// SHA-256 hash is stored.
+type UserToken struct {
+	ID        int64      `db:"id"`
+	UserID    int64      `db:"user_id"`
+	Purpose   string     `db:"purpose"`
+	TokenHash string     `db:"token_hash"`
+	Payload   *string    `db:"payload"` // e.g. the new address of an email change
+	ExpiresAt time.Time  `db:"expires_at"`
+	UsedAt    *time.Time `db:"used_at"`
+	CreatedAt time.Time  `db:"created_at"`
+}
+
+// EmailMessage is a plain-text email.
+type EmailMessage struct {
+	To      string
+	Subject string
+	Body    string
+}
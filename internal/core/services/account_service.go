@@ -0,0 +1,349 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"richisntreal-backend/internal/core/domain/models"
+)
+
+var ErrInvalidUserToken = errors.New("invalid or expired token")
+var ErrWeakPassword = errors.New("password must be at least 8 characters")
+var ErrEmailAlreadyVerified = errors.New("email already verified")
+var ErrInvalidEmail = errors.New("invalid email address")
+var ErrWrongPassword = errors.New("current password is incorrect")
+
+// MinPasswordLength is the shortest password an account may have.
+const MinPasswordLength = 8
+
+const (
+	passwordResetTTL      = time.Hour
+	emailVerificationTTL  = 48 * time.Hour
+	emailChangeTTL        = 24 * time.Hour
+	passwordResetPath     = "/reset-password"
+	emailVerificationPath = "/verify-email"
+	emailChangePath       = "/confirm-email-change"
+)
+
+// AccountService handles changes to a user's credentials: password reset
+// and change, email verification and change, and account deletion.
+type AccountService struct {
+	userRepository      UserRepository
+	userTokenRepository UserTokenRepository
+	mailer              Mailer
+	sessions            SessionRevoker
+	publicURL           string
+}
+
+// NewAccountService constructs a new AccountService. publicURL is the
+// storefront base URL the links in mails point to.
+func NewAccountService(
+	userRepository UserRepository,
+	userTokenRepository UserTokenRepository,
+	mailer Mailer,
+	sessions SessionRevoker,
+	publicURL string,
+) *AccountService {
+	return &AccountService{
+		userRepository:      userRepository,
+		userTokenRepository: userTokenRepository,
+		mailer:              mailer,
+		sessions:            sessions,
+		publicURL:           strings.TrimRight(publicURL, "/"),
+	}
+}
+
+// RequestPasswordReset mails a reset link. Unknown addresses are ignored
+// without error so the endpoint can't be used to probe for accounts.
+func (s *AccountService) RequestPasswordReset(ctx context.Context, email string) error {
+	ctx, span := startSpan(ctx, "AccountService.RequestPasswordReset")
+	defer span.End()
+
+	user, err := s.userRepository.FindByEmail(ctx, email)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return nil
+	}
+
+	token, err := s.issue(ctx, user.ID, models.TokenPurposePasswordReset, "", passwordResetTTL)
+	if err != nil {
+		return err
+	}
+	return s.mailer.Send(models.EmailMessage{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\n\n"+
+			"Someone asked to reset the password of your account. If it was you, open\n"+
+			"the link below within the next hour:\n\n%s\n\n"+
+			"If it wasn't, you can ignore this mail.\n",
+			user.FirstName, s.link(passwordResetPath, token)),
+	})
+}
+
+// ResetPassword redeems a reset token, sets the new password and logs the
+// user out everywhere.
+func (s *AccountService) ResetPassword(ctx context.Context, token, newPassword string) error {
+	ctx, span := startSpan(ctx, "AccountService.ResetPassword")
+	defer span.End()
+
+	if len(newPassword) < MinPasswordLength {
+		return ErrWeakPassword
+	}
+	ut, err := s.redeem(ctx, models.TokenPurposePasswordReset, token)
+	if err != nil {
+		return err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	if err := s.userRepository.UpdatePassword(ctx, ut.UserID, string(hash)); err != nil {
+		return err
+	}
+	return s.sessions.LogoutAll(ctx, ut.UserID)
+}
+
+// SendEmailVerification mails a link that confirms the user owns their address.
+func (s *AccountService) SendEmailVerification(ctx context.Context, userID int64) error {
+	ctx, span := startSpan(ctx, "AccountService.SendEmailVerification")
+	defer span.End()
+
+	user, err := s.userRepository.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return ErrUserNotFound
+	}
+	if user.EmailVerifiedAt != nil {
+		return ErrEmailAlreadyVerified
+	}
+
+	token, err := s.issue(ctx, user.ID, models.TokenPurposeEmailVerification, "", emailVerificationTTL)
+	if err != nil {
+		return err
+	}
+	return s.mailer.Send(models.EmailMessage{
+		To:      user.Email,
+		Subject: "Confirm your email address",
+		Body: fmt.Sprintf("Hi %s,\n\n"+
+			"Please confirm your email address by opening the link below:\n\n%s\n\n"+
+			"The link is valid for 48 hours.\n",
+			user.FirstName, s.link(emailVerificationPath, token)),
+	})
+}
+
+// VerifyEmail redeems a verification token.
+func (s *AccountService) VerifyEmail(ctx context.Context, token string) error {
+	ctx, span := startSpan(ctx, "AccountService.VerifyEmail")
+	defer span.End()
+
+	ut, err := s.redeem(ctx, models.TokenPurposeEmailVerification, token)
+	if err != nil {
+		return err
+	}
+	return s.userRepository.MarkEmailVerified(ctx, ut.UserID)
+}
+
+// ChangePassword sets a new password for a user who knows the current one,
+// and logs out every other session.
+func (s *AccountService) ChangePassword(ctx context.Context, userID, sessionID int64, currentPassword, newPassword string) error {
+	ctx, span := startSpan(ctx, "AccountService.ChangePassword")
+	defer span.End()
+
+	if len(newPassword) < MinPasswordLength {
+		return ErrWeakPassword
+	}
+	if _, err := s.checkPassword(ctx, userID, currentPassword); err != nil {
+		return err
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	if err := s.userRepository.UpdatePassword(ctx, userID, string(hash)); err != nil {
+		return err
+	}
+	return s.sessions.LogoutOthers(ctx, userID, sessionID)
+}
+
+// RequestEmailChange mails a confirmation link to the new address. The
+// address only changes once that link is followed; the old address is
+// told about the request.
+func (s *AccountService) RequestEmailChange(ctx context.Context, userID int64, password, newEmail string) error {
+	ctx, span := startSpan(ctx, "AccountService.RequestEmailChange")
+	defer span.End()
+
+	newEmail = strings.TrimSpace(newEmail)
+	if !strings.Contains(newEmail, "@") {
+		return ErrInvalidEmail
+	}
+	user, err := s.checkPassword(ctx, userID, password)
+	if err != nil {
+		return err
+	}
+	taken, err := s.userRepository.ExistsByEmail(ctx, newEmail)
+	if err != nil {
+		return err
+	}
+	if taken {
+		return ErrUserExists
+	}
+
+	token, err := s.issue(ctx, user.ID, models.TokenPurposeEmailChange, newEmail, emailChangeTTL)
+	if err != nil {
+		return err
+	}
+	err = s.mailer.Send(models.EmailMessage{
+		To:      newEmail,
+		Subject: "Confirm your new email address",
+		Body: fmt.Sprintf("Hi %s,\n\n"+
+			"Open the link below to start using this address for your account:\n\n%s\n\n"+
+			"The link is valid for 24 hours.\n",
+			user.FirstName, s.link(emailChangePath, token)),
+	})
+	if err != nil {
+		return err
+	}
+	return s.mailer.Send(models.EmailMessage{
+		To:      user.Email,
+		Subject: "Your email address is being changed",
+		Body: fmt.Sprintf("Hi %s,\n\n"+
+			"Someone asked to move your account to %s. If that wasn't you, reset\n"+
+			"your password now; nothing changes until the new address is confirmed.\n",
+			user.FirstName, newEmail),
+	})
+}
+
+// ConfirmEmailChange redeems an email change token and switches the address.
+func (s *AccountService) ConfirmEmailChange(ctx context.Context, token string) error {
+	ctx, span := startSpan(ctx, "AccountService.ConfirmEmailChange")
+	defer span.End()
+
+	ut, err := s.redeem(ctx, models.TokenPurposeEmailChange, token)
+	if err != nil {
+		return err
+	}
+	if ut.Payload == nil {
+		return ErrInvalidUserToken
+	}
+	// someone may have registered the address since the mail went out
+	taken, err := s.userRepository.ExistsByEmail(ctx, *ut.Payload)
+	if err != nil {
+		return err
+	}
+	if taken {
+		return ErrUserExists
+	}
+	return s.userRepository.UpdateEmail(ctx, ut.UserID, *ut.Payload)
+}
+
+// DeleteAccount anonymises the user and ends all their sessions. Orders
+// and invoices are kept for accounting.
+func (s *AccountService) DeleteAccount(ctx context.Context, userID int64, password string) error {
+	ctx, span := startSpan(ctx, "AccountService.DeleteAccount")
+	defer span.End()
+
+	if _, err := s.checkPassword(ctx, userID, password); err != nil {
+		return err
+	}
+	if err := s.sessions.LogoutAll(ctx, userID); err != nil {
+		return err
+	}
+	return s.userRepository.Anonymise(ctx, userID)
+}
+
+func (s *AccountService) checkPassword(ctx context.Context, userID int64, password string) (*models.User, error) {
+	user, err := s.userRepository.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+		return nil, ErrWrongPassword
+	}
+	return user, nil
+}
+
+// issue creates a token for purpose, burning any the user still holds.
+func (s *AccountService) issue(ctx context.Context, userID int64, purpose, payload string, ttl time.Duration) (string, error) {
+	if err := s.userTokenRepository.InvalidateOutstanding(ctx, userID, purpose); err != nil {
+		return "", err
+	}
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+	ut := &models.UserToken{
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: hashToken(token),
+		ExpiresAt: time.Now().Add(ttl),
+	}
+	if payload != "" {
+		ut.Payload = &payload
+	}
+	if _, err := s.userTokenRepository.Create(ctx, ut); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// redeem looks a token up and marks it used; it works exactly once.
+func (s *AccountService) redeem(ctx context.Context, purpose, token string) (*models.UserToken, error) {
+	if token == "" {
+		return nil, ErrInvalidUserToken
+	}
+	ut, err := s.userTokenRepository.FindByHash(ctx, purpose, hashToken(token))
+	if err != nil {
+		return nil, err
+	}
+	if ut == nil || ut.UsedAt != nil || time.Now().After(ut.ExpiresAt) {
+		return nil, ErrInvalidUserToken
+	}
+	ok, err := s.userTokenRepository.MarkUsed(ctx, ut.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrInvalidUserToken
+	}
+	return ut, nil
+}
+
+func (s *AccountService) link(path, token string) string {
+	return s.publicURL + path + "?token=" + url.QueryEscape(token)
+}
+
+// Mailer delivers email; see the infrastructure/mail package.
+type Mailer interface {
+	Send(msg models.EmailMessage) error
+}
+
+// SessionRevoker ends a user's sessions; SessionService implements it.
+type SessionRevoker interface {
+	LogoutAll(ctx context.Context, userID int64) error
+	LogoutOthers(ctx context.Context, userID, keepSessionID int64) error
+}
+
+// UserTokenRepository defines persistence operations for mailed user tokens.
+type UserTokenRepository interface {
+	Create(ctx context.Context, t *models.UserToken) (int64, error)
+	FindByHash(ctx context.Context, purpose, tokenHash string) (*models.UserToken, error)
+	MarkUsed(ctx context.Context, id int64) (bool, error)
+	InvalidateOutstanding(ctx context.Context, userID int64, purpose string) error
+}
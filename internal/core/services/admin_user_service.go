@@ -0,0 +1,187 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"richisntreal-backend/internal/core/domain/models"
+	"richisntreal-backend/internal/infrastructure/logging"
+)
+
+var ErrCannotTargetSelf = errors.New("admins can't do this to their own account")
+var ErrCannotImpersonateAdmin = errors.New("admins can't be impersonated")
+
+// AdminUserService backs the support tools: finding customers, disabling
+// accounts and impersonating users. Everything it changes is audit-logged.
+type AdminUserService struct {
+	userRepository   UserRepository
+	auditRepository  AuditRepository
+	sessions         Impersonator
+	impersonationTTL time.Duration
+}
+
+// NewAdminUserService constructs a new AdminUserService. impersonationTTL
+// is how long an impersonation token stays valid.
+func NewAdminUserService(
+	userRepository UserRepository,
+	auditRepository AuditRepository,
+	sessions Impersonator,
+	impersonationTTL time.Duration,
+) *AdminUserService {
+	return &AdminUserService{
+		userRepository:   userRepository,
+		auditRepository:  auditRepository,
+		sessions:         sessions,
+		impersonationTTL: impersonationTTL,
+	}
+}
+
+// SearchUsers pages through users matching the filter.
+func (s *AdminUserService) SearchUsers(ctx context.Context, filter models.UserFilter) ([]*models.User, int, error) {
+	ctx, span := startSpan(ctx, "AdminUserService.SearchUsers")
+	defer span.End()
+
+	users, total, err := s.userRepository.Search(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, u := range users {
+		u.Password = ""
+	}
+	return users, total, nil
+}
+
+// GetUser looks up any user, disabled ones included.
+func (s *AdminUserService) GetUser(ctx context.Context, userID int64) (*models.User, error) {
+	ctx, span := startSpan(ctx, "AdminUserService.GetUser")
+	defer span.End()
+
+	user, err := s.userRepository.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || user.DeletedAt != nil {
+		return nil, ErrUserNotFound
+	}
+	user.Password = ""
+	return user, nil
+}
+
+// DisableUser stops a user from logging in and ends their sessions,
+// including any they opened as other users.
+func (s *AdminUserService) DisableUser(ctx context.Context, adminID, userID int64) (*models.User, error) {
+	ctx, span := startSpan(ctx, "AdminUserService.DisableUser")
+	defer span.End()
+
+	if adminID == userID {
+		return nil, ErrCannotTargetSelf
+	}
+	if _, err := s.GetUser(ctx, userID); err != nil {
+		return nil, err
+	}
+	if err := s.userRepository.SetDisabled(ctx, userID, true); err != nil {
+		return nil, err
+	}
+	if err := s.sessions.LogoutAll(ctx, userID); err != nil {
+		return nil, err
+	}
+	if err := s.sessions.EndImpersonations(ctx, userID); err != nil {
+		return nil, err
+	}
+	if err := s.audit(ctx, adminID, userID, models.AuditUserDisable, ""); err != nil {
+		return nil, err
+	}
+	return s.GetUser(ctx, userID)
+}
+
+// EnableUser lifts a DisableUser.
+func (s *AdminUserService) EnableUser(ctx context.Context, adminID, userID int64) (*models.User, error) {
+	ctx, span := startSpan(ctx, "AdminUserService.EnableUser")
+	defer span.End()
+
+	if _, err := s.GetUser(ctx, userID); err != nil {
+		return nil, err
+	}
+	if err := s.userRepository.SetDisabled(ctx, userID, false); err != nil {
+		return nil, err
+	}
+	if err := s.audit(ctx, adminID, userID, models.AuditUserEnable, ""); err != nil {
+		return nil, err
+	}
+	return s.GetUser(ctx, userID)
+}
+
+// Impersonate issues a short-lived access token with which the admin acts
+// as the user. Admins and disabled accounts can't be impersonated.
+func (s *AdminUserService) Impersonate(ctx context.Context, adminID, userID int64, reason string) (*models.TokenPair, error) {
+	ctx, span := startSpan(ctx, "AdminUserService.Impersonate")
+	defer span.End()
+
+	if adminID == userID {
+		return nil, ErrCannotTargetSelf
+	}
+	user, err := s.GetUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.IsAdmin() {
+		return nil, ErrCannotImpersonateAdmin
+	}
+	if user.IsDisabled() {
+		return nil, ErrAccountDisabled
+	}
+
+	tokens, err := s.sessions.StartImpersonation(ctx, adminID, userID, s.impersonationTTL)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.audit(ctx, adminID, userID, models.AuditImpersonationStart, reason); err != nil {
+		return nil, err
+	}
+	logging.FromContext(ctx).Info("impersonation started", "admin_id", adminID, "target_user_id", userID)
+	return tokens, nil
+}
+
+// RecordImpersonatedRequest logs a request an admin made as a user.
+func (s *AdminUserService) RecordImpersonatedRequest(ctx context.Context, adminID, userID int64, method, path string) error {
+	ctx, span := startSpan(ctx, "AdminUserService.RecordImpersonatedRequest")
+	defer span.End()
+
+	return s.audit(ctx, adminID, userID, models.AuditImpersonatedAction, fmt.Sprintf("%s %s", method, path))
+}
+
+// AuditLog pages through the audit log.
+func (s *AdminUserService) AuditLog(ctx context.Context, filter models.AuditFilter) ([]*models.AuditEntry, int, error) {
+	ctx, span := startSpan(ctx, "AdminUserService.AuditLog")
+	defer span.End()
+
+	return s.auditRepository.Search(ctx, filter)
+}
+
+func (s *AdminUserService) audit(ctx context.Context, adminID, userID int64, action, detail string) error {
+	if len(detail) > 255 {
+		detail = detail[:255]
+	}
+	return s.auditRepository.Record(ctx, &models.AuditEntry{
+		AdminID: adminID,
+		UserID:  &userID,
+		Action:  action,
+		Detail:  detail,
+	})
+}
+
+// Impersonator opens impersonation sessions and ends a user's sessions;
+// SessionService implements it.
+type Impersonator interface {
+	StartImpersonation(ctx context.Context, adminID, userID int64, ttl time.Duration) (*models.TokenPair, error)
+	LogoutAll(ctx context.Context, userID int64) error
+	EndImpersonations(ctx context.Context, adminID int64) error
+}
+
+// AuditRepository defines persistence operations for the admin audit log.
+type AuditRepository interface {
+	Record(ctx context.Context, e *models.AuditEntry) error
+	Search(ctx context.Context, filter models.AuditFilter) ([]*models.AuditEntry, int, error)
+}
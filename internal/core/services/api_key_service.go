@@ -0,0 +1,149 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"richisntreal-backend/internal/core/domain/models"
+)
+
+var ErrAPIKeyNotFound = errors.New("api key not found")
+var ErrInvalidAPIKey = errors.New("invalid api key")
+var ErrInvalidScope = errors.New("unknown or missing scope")
+
+const apiKeyPrefix = "rnr_"
+
+// APIKeyService issues and checks API keys.
+type APIKeyService struct {
+	apiKeyRepository APIKeyRepository
+}
+
+func NewAPIKeyService(apiKeyRepository APIKeyRepository) *APIKeyService {
+	return &APIKeyService{apiKeyRepository: apiKeyRepository}
+}
+
+// Create issues a key for the user. The plaintext key is returned only
+// here; afterwards only its hash exists.
+func (s *APIKeyService) Create(ctx context.Context, userID int64, name string, scopes []string, expiresAt *time.Time) (*models.APIKey, string, error) {
+	ctx, span := startSpan(ctx, "APIKeyService.Create")
+	defer span.End()
+
+	if len(scopes) == 0 {
+		return nil, "", ErrInvalidScope
+	}
+	for _, sc := range scopes {
+		if !knownScope(sc) {
+			return nil, "", ErrInvalidScope
+		}
+	}
+
+	prefix, err := randomHex(6)
+	if err != nil {
+		return nil, "", err
+	}
+	secret, err := randomToken()
+	if err != nil {
+		return nil, "", err
+	}
+	raw := apiKeyPrefix + prefix + "_" + secret
+
+	key := &models.APIKey{
+		UserID:    userID,
+		Name:      name,
+		Prefix:    prefix,
+		KeyHash:   hashToken(raw),
+		Scopes:    scopes,
+		ExpiresAt: expiresAt,
+		CreatedAt: time.Now(),
+	}
+	id, err := s.apiKeyRepository.Create(ctx, key)
+	if err != nil {
+		return nil, "", err
+	}
+	key.ID = id
+	return key, raw, nil
+}
+
+func (s *APIKeyService) List(ctx context.Context, userID int64) ([]*models.APIKey, error) {
+	ctx, span := startSpan(ctx, "APIKeyService.List")
+	defer span.End()
+
+	return s.apiKeyRepository.FindByUser(ctx, userID)
+}
+
+// Revoke disables one of the user's keys.
+func (s *APIKeyService) Revoke(ctx context.Context, userID, keyID int64) error {
+	ctx, span := startSpan(ctx, "APIKeyService.Revoke")
+	defer span.End()
+
+	key, err := s.apiKeyRepository.FindByID(ctx, keyID)
+	if err != nil {
+		return err
+	}
+	if key == nil || key.UserID != userID {
+		return ErrAPIKeyNotFound
+	}
+	return s.apiKeyRepository.Revoke(ctx, keyID)
+}
+
+// ResolveAPIKey checks a presented key and returns who it acts for.
+func (s *APIKeyService) ResolveAPIKey(ctx context.Context, raw string) (userID, keyID int64, scopes []string, err error) {
+	ctx, span := startSpan(ctx, "APIKeyService.ResolveAPIKey")
+	defer span.End()
+
+	rest, ok := strings.CutPrefix(raw, apiKeyPrefix)
+	if !ok {
+		return 0, 0, nil, ErrInvalidAPIKey
+	}
+	prefix, _, ok := strings.Cut(rest, "_")
+	if !ok {
+		return 0, 0, nil, ErrInvalidAPIKey
+	}
+
+	key, err := s.apiKeyRepository.FindByPrefix(ctx, prefix)
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	if key == nil || subtle.ConstantTimeCompare([]byte(key.KeyHash), []byte(hashToken(raw))) != 1 {
+		return 0, 0, nil, ErrInvalidAPIKey
+	}
+	if !key.Active(time.Now()) {
+		return 0, 0, nil, ErrInvalidAPIKey
+	}
+	if err := s.apiKeyRepository.TouchLastUsed(ctx, key.ID); err != nil {
+		return 0, 0, nil, err
+	}
+	return key.UserID, key.ID, key.Scopes, nil
+}
+
+func knownScope(scope string) bool {
+	for _, sc := range models.APIKeyScopes {
+		if sc == scope {
+			return true
+		}
+	}
+	return false
+}
+
+func randomHex(n int) (string, error) {
+	raw := make([]byte, n)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(raw), nil
+}
+
+// APIKeyRepository defines persistence operations for API keys.
+type APIKeyRepository interface {
+	Create(ctx context.Context, k *models.APIKey) (int64, error)
+	FindByID(ctx context.Context, id int64) (*models.APIKey, error)
+	FindByPrefix(ctx context.Context, prefix string) (*models.APIKey, error)
+	FindByUser(ctx context.Context, userID int64) ([]*models.APIKey, error)
+	Revoke(ctx context.Context, id int64) error
+	TouchLastUsed(ctx context.Context, id int64) error
+}
@@ -0,0 +1,228 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"richisntreal-backend/internal/core/domain/models"
+	"richisntreal-backend/internal/core/services"
+)
+
+// apiKeyRepo keeps keys in a slice.
+type apiKeyRepo struct {
+	mu   sync.Mutex
+	keys []*models.APIKey
+}
+
+func (r *apiKeyRepo) Create(_ context.Context, k *models.APIKey) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	c := *k
+	c.ID = int64(len(r.keys) + 1)
+	r.keys = append(r.keys, &c)
+	return c.ID, nil
+}
+
+func (r *apiKeyRepo) find(match func(*models.APIKey) bool) *models.APIKey {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, k := range r.keys {
+		if match(k) {
+			c := *k
+			return &c
+		}
+	}
+	return nil
+}
+
+func (r *apiKeyRepo) FindByID(_ context.Context, id int64) (*models.APIKey, error) {
+	return r.find(func(k *models.APIKey) bool { return k.ID == id }), nil
+}
+
+func (r *apiKeyRepo) FindByPrefix(_ context.Context, prefix string) (*models.APIKey, error) {
+	return r.find(func(k *models.APIKey) bool { return k.Prefix == prefix }), nil
+}
+
+func (r *apiKeyRepo) FindByUser(_ context.Context, userID int64) ([]*models.APIKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []*models.APIKey
+	for _, k := range r.keys {
+		if k.UserID == userID {
+			c := *k
+			out = append(out, &c)
+		}
+	}
+	return out, nil
+}
+
+func (r *apiKeyRepo) Revoke(_ context.Context, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	for _, k := range r.keys {
+		if k.ID == id && k.RevokedAt == nil {
+			k.RevokedAt = &now
+		}
+	}
+	return nil
+}
+
+func (r *apiKeyRepo) TouchLastUsed(_ context.Context, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	for _, k := range r.keys {
+		if k.ID == id {
+			k.LastUsedAt = &now
+		}
+	}
+	return nil
+}
+
+func TestResolveAPIKey(t *testing.T) {
+	scopes := []string{models.ScopeOrdersRead, models.ScopeInvoicesRead}
+	tests := []struct {
+		name string
+		// present returns the key to present, given the one issued
+		present func(t *testing.T, svc *services.APIKeyService, repo *apiKeyRepo, raw string) string
+		expires time.Duration // from now; zero never expires
+		wantErr error
+	}{
+		{
+			name:    "the issued key",
+			present: func(_ *testing.T, _ *services.APIKeyService, _ *apiKeyRepo, raw string) string { return raw },
+		},
+		{
+			name:    "a key that has not expired yet",
+			present: func(_ *testing.T, _ *services.APIKeyService, _ *apiKeyRepo, raw string) string { return raw },
+			expires: time.Hour,
+		},
+		{
+			name:    "an expired key",
+			present: func(_ *testing.T, _ *services.APIKeyService, _ *apiKeyRepo, raw string) string { return raw },
+			expires: -time.Second,
+			wantErr: services.ErrInvalidAPIKey,
+		},
+		{
+			name: "a revoked key",
+			present: func(t *testing.T, svc *services.APIKeyService, _ *apiKeyRepo, raw string) string {
+				if err := svc.Revoke(context.Background(), 1, 1); err != nil {
+					t.Fatal(err)
+				}
+				return raw
+			},
+			wantErr: services.ErrInvalidAPIKey,
+		},
+		{
+			name: "someone else can't revoke it",
+			present: func(t *testing.T, svc *services.APIKeyService, _ *apiKeyRepo, raw string) string {
+				if err := svc.Revoke(context.Background(), 2, 1); !errors.Is(err, services.ErrAPIKeyNotFound) {
+					t.Fatalf("Revoke by another user error = %v, want ErrAPIKeyNotFound", err)
+				}
+				return raw
+			},
+		},
+		{
+			name: "the right prefix with the wrong secret",
+			present: func(_ *testing.T, _ *services.APIKeyService, _ *apiKeyRepo, raw string) string {
+				last := "A"
+				if strings.HasSuffix(raw, last) {
+					last = "B"
+				}
+				return raw[:len(raw)-1] + last
+			},
+			wantErr: services.ErrInvalidAPIKey,
+		},
+		{
+			name: "an unknown prefix",
+			present: func(_ *testing.T, _ *services.APIKeyService, _ *apiKeyRepo, raw string) string {
+				return "rnr_000000000000_" + raw[strings.LastIndexByte(raw, '_')+1:]
+			},
+			wantErr: services.ErrInvalidAPIKey,
+		},
+		{
+			name: "a key without the rnr_ marker",
+			present: func(_ *testing.T, _ *services.APIKeyService, _ *apiKeyRepo, raw string) string {
+				return strings.TrimPrefix(raw, "rnr_")
+			},
+			wantErr: services.ErrInvalidAPIKey,
+		},
+		{
+			name:    "a key missing the separator",
+			present: func(*testing.T, *services.APIKeyService, *apiKeyRepo, string) string { return "rnr_nounderscore" },
+			wantErr: services.ErrInvalidAPIKey,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := &apiKeyRepo{}
+			svc := services.NewAPIKeyService(repo)
+			var expiresAt *time.Time
+			if tt.expires != 0 {
+				at := time.Now().Add(tt.expires)
+				expiresAt = &at
+			}
+			key, raw, err := svc.Create(ctx, 1, "warehouse", scopes, expiresAt)
+			if err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+			if stored := repo.keys[0]; stored.KeyHash == "" || strings.Contains(stored.KeyHash, raw) || strings.Contains(raw, stored.KeyHash) {
+				t.Fatalf("stored key hash %q does not hide the key", stored.KeyHash)
+			}
+
+			userID, keyID, gotScopes, err := svc.ResolveAPIKey(ctx, tt.present(t, svc, repo, raw))
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("ResolveAPIKey error = %v, want %v", err, tt.wantErr)
+				}
+				if repo.keys[0].LastUsedAt != nil {
+					t.Error("a refused key was marked as used")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ResolveAPIKey: %v", err)
+			}
+			if userID != 1 || keyID != key.ID {
+				t.Errorf("ResolveAPIKey = user %d key %d, want user 1 key %d", userID, keyID, key.ID)
+			}
+			if strings.Join(gotScopes, ",") != strings.Join(scopes, ",") {
+				t.Errorf("scopes = %v, want %v", gotScopes, scopes)
+			}
+			if repo.keys[0].LastUsedAt == nil {
+				t.Error("LastUsedAt not set after use")
+			}
+		})
+	}
+}
+
+func TestCreateAPIKeyScopes(t *testing.T) {
+	tests := []struct {
+		name    string
+		scopes  []string
+		wantErr error
+	}{
+		{name: "known scopes", scopes: []string{models.ScopeOrdersRead, models.ScopeProductsWrite}},
+		{name: "no scopes", wantErr: services.ErrInvalidScope},
+		{name: "an unknown scope", scopes: []string{models.ScopeOrdersRead, "admin"}, wantErr: services.ErrInvalidScope},
+		{name: "a scope in the wrong case", scopes: []string{"Orders:Read"}, wantErr: services.ErrInvalidScope},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &apiKeyRepo{}
+			_, _, err := services.NewAPIKeyService(repo).Create(context.Background(), 1, "warehouse", tt.scopes, nil)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Create error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil && len(repo.keys) != 0 {
+				t.Errorf("a refused key was stored")
+			}
+		})
+	}
+}
@@ -1,43 +1,68 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"richisntreal-backend/internal/core/domain/models"
 )
 
 type CartService struct {
 	cartRepository CartRepository
+	metrics        CartMetrics
 }
 
-func NewCartService(cartRepository CartRepository) *CartService {
-	return &CartService{cartRepository: cartRepository}
+func NewCartService(cartRepository CartRepository, metrics CartMetrics) *CartService {
+	return &CartService{cartRepository: cartRepository, metrics: metrics}
 }
 
-func (s *CartService) GetCart(userID int64) (*models.Cart, error) {
-	cart, err := s.cartRepository.FindByUserID(userID)
+func (s *CartService) GetCart(ctx context.Context, userID int64) (*models.Cart, error) {
+	ctx, span := startSpan(ctx, "CartService.GetCart")
+	defer span.End()
+
+	cart, err := s.cartRepository.FindByUserID(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
 	if cart == nil {
-		id, err := s.cartRepository.CreateCart(&models.Cart{UserID: userID})
+		id, err := s.cartRepository.CreateCart(ctx, &models.Cart{UserID: userID})
 		if err != nil {
 			return nil, err
 		}
-		cart, _ = s.cartRepository.FindByUserID(userID) // now it exists
+		s.metrics.CartCreated()
+		cart, _ = s.cartRepository.FindByUserID(ctx, userID) // now it exists
 		cart.ID = id
 	}
 	return cart, nil
 }
 
-func (s *CartService) AddItem(userID, productID int64, qty int, price float64) (*models.CartItem, error) {
-	cart, err := s.GetCart(userID)
+// FindCart looks up a user's cart without creating one, for staff who
+// only look. Users without a cart get an empty one.
+func (s *CartService) FindCart(ctx context.Context, userID int64) (*models.Cart, error) {
+	ctx, span := startSpan(ctx, "CartService.FindCart")
+	defer span.End()
+
+	cart, err := s.cartRepository.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if cart == nil {
+		return &models.Cart{UserID: userID, Items: []models.CartItem{}}, nil
+	}
+	return cart, nil
+}
+
+func (s *CartService) AddItem(ctx context.Context, userID, productID int64, qty int, price float64) (*models.CartItem, error) {
+	ctx, span := startSpan(ctx, "CartService.AddItem")
+	defer span.End()
+
+	cart, err := s.GetCart(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
 	// merge if exists
-	if existing, _ := s.cartRepository.FindItemByCartAndProduct(cart.ID, productID); existing != nil {
+	if existing, _ := s.cartRepository.FindItemByCartAndProduct(ctx, cart.ID, productID); existing != nil {
 		existing.Quantity += qty
-		if err := s.cartRepository.UpdateItem(existing); err != nil {
+		if err := s.cartRepository.UpdateItem(ctx, existing); err != nil {
 			return nil, err
 		}
 		return existing, nil
@@ -48,7 +73,7 @@ func (s *CartService) AddItem(userID, productID int64, qty int, price float64) (
 		Quantity:  qty,
 		UnitPrice: price,
 	}
-	id, err := s.cartRepository.CreateItem(item)
+	id, err := s.cartRepository.CreateItem(ctx, item)
 	if err != nil {
 		return nil, err
 	}
@@ -56,8 +81,11 @@ func (s *CartService) AddItem(userID, productID int64, qty int, price float64) (
 	return item, nil
 }
 
-func (s *CartService) UpdateItem(itemID int64, qty int) (*models.CartItem, error) {
-	item, err := s.cartRepository.FindItem(itemID)
+func (s *CartService) UpdateItem(ctx context.Context, itemID int64, qty int) (*models.CartItem, error) {
+	ctx, span := startSpan(ctx, "CartService.UpdateItem")
+	defer span.End()
+
+	item, err := s.cartRepository.FindItem(ctx, itemID)
 	if err != nil {
 		return nil, err
 	}
@@ -65,33 +93,44 @@ func (s *CartService) UpdateItem(itemID int64, qty int) (*models.CartItem, error
 		return nil, ErrCartItemNotFound
 	}
 	item.Quantity = qty
-	if err := s.cartRepository.UpdateItem(item); err != nil {
+	if err := s.cartRepository.UpdateItem(ctx, item); err != nil {
 		return nil, err
 	}
 	return item, nil
 }
 
-func (s *CartService) RemoveItem(itemID int64) error {
-	return s.cartRepository.DeleteItem(itemID)
+func (s *CartService) RemoveItem(ctx context.Context, itemID int64) error {
+	ctx, span := startSpan(ctx, "CartService.RemoveItem")
+	defer span.End()
+
+	return s.cartRepository.DeleteItem(ctx, itemID)
 }
 
-func (s *CartService) ClearCart(userID int64) error {
-	cart, err := s.GetCart(userID)
+func (s *CartService) ClearCart(ctx context.Context, userID int64) error {
+	ctx, span := startSpan(ctx, "CartService.ClearCart")
+	defer span.End()
+
+	cart, err := s.GetCart(ctx, userID)
 	if err != nil {
 		return err
 	}
-	return s.cartRepository.DeleteItemsByCartID(cart.ID)
+	return s.cartRepository.DeleteItemsByCartID(ctx, cart.ID)
 }
 
 var ErrCartItemNotFound = errors.New("cart item not found")
 
+// CartMetrics counts cart events; metrics.Metrics implements it.
+type CartMetrics interface {
+	CartCreated()
+}
+
 type CartRepository interface {
-	FindByUserID(userID int64) (*models.Cart, error)
-	CreateCart(cart *models.Cart) (int64, error)
-	FindItem(itemID int64) (*models.CartItem, error)
-	FindItemByCartAndProduct(cartID, productID int64) (*models.CartItem, error)
-	CreateItem(item *models.CartItem) (int64, error)
-	UpdateItem(item *models.CartItem) error
-	DeleteItem(itemID int64) error
-	DeleteItemsByCartID(cartID int64) error
+	FindByUserID(ctx context.Context, userID int64) (*models.Cart, error)
+	CreateCart(ctx context.Context, cart *models.Cart) (int64, error)
+	FindItem(ctx context.Context, itemID int64) (*models.CartItem, error)
+	FindItemByCartAndProduct(ctx context.Context, cartID, productID int64) (*models.CartItem, error)
+	CreateItem(ctx context.Context, item *models.CartItem) (int64, error)
+	UpdateItem(ctx context.Context, item *models.CartItem) error
+	DeleteItem(ctx context.Context, itemID int64) error
+	DeleteItemsByCartID(ctx context.Context, cartID int64) error
 }
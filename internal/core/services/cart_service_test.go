@@ -0,0 +1,85 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+)
+
+func TestCartAddItemMerges(t *testing.T) {
+	type add struct {
+		sku   string
+		qty   int
+		price float64
+	}
+	type line struct {
+		sku string
+		qty int
+	}
+	tests := []struct {
+		name string
+		adds []add
+		want []line
+	}{
+		{
+			name: "first item creates the cart",
+			adds: []add{{"MUG", 1, 9.5}},
+			want: []line{{"MUG", 1}},
+		},
+		{
+			name: "the same product again adds to its line",
+			adds: []add{{"MUG", 1, 9.5}, {"MUG", 2, 9.5}},
+			want: []line{{"MUG", 3}},
+		},
+		{
+			name: "a merged line keeps the price it was added at",
+			adds: []add{{"MUG", 1, 9.5}, {"MUG", 1, 12}},
+			want: []line{{"MUG", 2}},
+		},
+		{
+			name: "other products get lines of their own",
+			adds: []add{{"MUG", 1, 9.5}, {"TEE", 2, 20}, {"MUG", 1, 9.5}},
+			want: []line{{"MUG", 2}, {"TEE", 2}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newShop()
+			ctx := context.Background()
+			userID := s.addUser(t)
+			ids := map[string]int64{}
+			skus := map[int64]string{}
+			for _, a := range tt.adds {
+				if _, ok := ids[a.sku]; !ok {
+					ids[a.sku] = s.addProduct(t, a.sku, a.price, nil)
+					skus[ids[a.sku]] = a.sku
+				}
+				if _, err := s.cartSvc.AddItem(ctx, userID, ids[a.sku], a.qty, a.price); err != nil {
+					t.Fatalf("AddItem(%s): %v", a.sku, err)
+				}
+			}
+
+			cart, err := s.cartSvc.GetCart(ctx, userID)
+			if err != nil {
+				t.Fatalf("GetCart: %v", err)
+			}
+			if len(cart.Items) != len(tt.want) {
+				t.Fatalf("cart has %d lines, want %d: %+v", len(cart.Items), len(tt.want), cart.Items)
+			}
+			firstPrice := map[string]float64{}
+			for _, a := range tt.adds {
+				if _, ok := firstPrice[a.sku]; !ok {
+					firstPrice[a.sku] = a.price
+				}
+			}
+			for i, w := range tt.want {
+				got := cart.Items[i]
+				if skus[got.ProductID] != w.sku || got.Quantity != w.qty {
+					t.Errorf("line %d = %s x%d, want %s x%d", i, skus[got.ProductID], got.Quantity, w.sku, w.qty)
+				}
+				if got.UnitPrice != firstPrice[w.sku] {
+					t.Errorf("line %d unit price = %v, want %v", i, got.UnitPrice, firstPrice[w.sku])
+				}
+			}
+		})
+	}
+}
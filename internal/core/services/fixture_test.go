@@ -0,0 +1,77 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"richisntreal-backend/internal/core/domain/models"
+	"richisntreal-backend/internal/core/services"
+	"richisntreal-backend/internal/infrastructure/memory"
+)
+
+// shop is the catalogue, carts, orders and payments services on one
+// in-memory store.
+type shop struct {
+	users    *memory.UserRepository
+	products *memory.ProductRepository
+	carts    *memory.CartRepository
+	orders   *memory.OrderRepository
+	payments *memory.PaymentRepository
+
+	cartSvc    *services.CartService
+	orderSvc   *services.OrderService
+	paymentSvc *services.PaymentService
+}
+
+func newShop() *shop {
+	store := memory.NewStore()
+	s := &shop{
+		users:    memory.NewUserRepository(store),
+		products: memory.NewProductRepository(store),
+		carts:    memory.NewCartRepository(store),
+		orders:   memory.NewOrderRepository(store),
+		payments: memory.NewPaymentRepository(store),
+	}
+	s.cartSvc = services.NewCartService(s.carts, noMetrics{})
+	s.orderSvc = services.NewOrderService(s.orders, s.carts, s.products, nil, noMetrics{})
+	s.paymentSvc = services.NewPaymentService(s.payments, "sk_test_shop", noMetrics{})
+	return s
+}
+
+func (s *shop) addUser(t *testing.T) int64 {
+	t.Helper()
+	id, err := s.users.Create(context.Background(), &models.User{Username: "shopper", Email: "shopper@example.com", Role: models.RoleCustomer})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+// addProduct adds a product; nil stock is untracked.
+func (s *shop) addProduct(t *testing.T, sku string, price float64, stock *int) int64 {
+	t.Helper()
+	id, err := s.products.Create(context.Background(), &models.Product{Name: sku, SKU: sku, Price: price, Stock: stock})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+func (s *shop) stock(t *testing.T, productID int64) int {
+	t.Helper()
+	p, err := s.products.FindByID(context.Background(), productID)
+	if err != nil || p == nil || p.Stock == nil {
+		t.Fatalf("FindByID(%d) = %+v, %v; want a product with tracked stock", productID, p, err)
+	}
+	return *p.Stock
+}
+
+// noMetrics discards what the services count.
+type noMetrics struct{}
+
+func (noMetrics) CartCreated()            {}
+func (noMetrics) OrderCreated()           {}
+func (noMetrics) PaymentSucceeded(string) {}
+func (noMetrics) PaymentFailed(string)    {}
+
+func intPtr(n int) *int { return &n }
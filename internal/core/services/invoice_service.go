@@ -0,0 +1,112 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"richisntreal-backend/internal/core/domain/models"
+)
+
+var ErrInvoiceNotFound = errors.New("invoice not found")
+
+// InvoiceService assembles and renders invoices for paid orders.
+type InvoiceService struct {
+	invoiceRepository InvoiceRepository
+	orderRepository   OrderRepository
+	productRepository ProductRepository
+	paymentRepository PaymentRepository
+	renderer          InvoiceRenderer
+}
+
+func NewInvoiceService(
+	invoiceRepository InvoiceRepository,
+	orderRepository OrderRepository,
+	productRepository ProductRepository,
+	paymentRepository PaymentRepository,
+	renderer InvoiceRenderer,
+) *InvoiceService {
+	return &InvoiceService{
+		invoiceRepository: invoiceRepository,
+		orderRepository:   orderRepository,
+		productRepository: productRepository,
+		paymentRepository: paymentRepository,
+		renderer:          renderer,
+	}
+}
+
+// GetInvoiceDocument gathers the invoice, order lines with product names,
+// billing address and payment for an order.
+func (s *InvoiceService) GetInvoiceDocument(ctx context.Context, orderID int64) (*models.InvoiceDocument, error) {
+	ctx, span := startSpan(ctx, "InvoiceService.GetInvoiceDocument")
+	defer span.End()
+
+	inv, err := s.invoiceRepository.FindByOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if inv == nil {
+		return nil, ErrInvoiceNotFound
+	}
+	ord, err := s.orderRepository.FindOrderByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if ord == nil {
+		return nil, ErrOrderNotFound
+	}
+	payment, err := s.paymentRepository.FindByOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := &models.InvoiceDocument{
+		Invoice:        *inv,
+		Order:          *ord,
+		BillingAddress: ord.BillingAddress,
+		Payment:        payment,
+	}
+	for _, it := range ord.Items {
+		line := models.InvoiceLine{
+			Quantity:  it.Quantity,
+			UnitPrice: it.UnitPrice,
+			Total:     float64(it.Quantity) * it.UnitPrice,
+		}
+		prod, err := s.productRepository.FindByID(ctx, it.ProductID)
+		if err != nil {
+			return nil, err
+		}
+		if prod != nil {
+			line.ProductName = prod.Name
+			line.SKU = prod.SKU
+		}
+		doc.Lines = append(doc.Lines, line)
+	}
+	return doc, nil
+}
+
+// RenderInvoicePDF renders the invoice of an order as a PDF.
+func (s *InvoiceService) RenderInvoicePDF(ctx context.Context, orderID int64) ([]byte, *models.Invoice, error) {
+	ctx, span := startSpan(ctx, "InvoiceService.RenderInvoicePDF")
+	defer span.End()
+
+	doc, err := s.GetInvoiceDocument(ctx, orderID)
+	if err != nil {
+		return nil, nil, err
+	}
+	pdf, err := s.renderer.RenderInvoice(doc)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pdf, &doc.Invoice, nil
+}
+
+// InvoiceRepository defines persistence operations for invoices.
+type InvoiceRepository interface {
+	Allocate(ctx context.Context, orderID int64) (*models.Invoice, error)
+	FindByOrder(ctx context.Context, orderID int64) (*models.Invoice, error)
+}
+
+// InvoiceRenderer turns an invoice document into a printable file.
+type InvoiceRenderer interface {
+	RenderInvoice(doc *models.InvoiceDocument) ([]byte, error)
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"richisntreal-backend/internal/core/domain/models"
+	"richisntreal-backend/internal/infrastructure/logging"
+)
+
+// LoginGuard locks an account for a while after too many wrong passwords
+// or second-factor codes and keeps the audit trail of login attempts.
+type LoginGuard struct {
+	loginAttemptRepository LoginAttemptRepository
+	maxFailures            int
+	window                 time.Duration
+	lockout                time.Duration
+}
+
+// NewLoginGuard constructs a new LoginGuard. An account is locked for
+// lockout once maxFailures wrong passwords or codes pile up within window.
+func NewLoginGuard(
+	loginAttemptRepository LoginAttemptRepository,
+	maxFailures int,
+	window, lockout time.Duration,
+) *LoginGuard {
+	return &LoginGuard{
+		loginAttemptRepository: loginAttemptRepository,
+		maxFailures:            maxFailures,
+		window:                 window,
+		lockout:                lockout,
+	}
+}
+
+// LockedFor returns how much longer the account behind email stays
+// locked, or zero when it isn't.
+func (g *LoginGuard) LockedFor(ctx context.Context, email string) (time.Duration, error) {
+	ctx, span := startSpan(ctx, "LoginGuard.LockedFor")
+	defer span.End()
+
+	if g.maxFailures <= 0 {
+		return 0, nil
+	}
+	failures, sinceLast, err := g.loginAttemptRepository.RecentFailures(ctx, normalizeEmail(email), g.window)
+	if err != nil {
+		return 0, err
+	}
+	if failures < g.maxFailures || sinceLast >= g.lockout {
+		return 0, nil
+	}
+	logging.FromContext(ctx).Warn("login refused: account locked", "failures", failures)
+	return g.lockout - sinceLast, nil
+}
+
+// RecordSuccess logs a successful login, which also clears the failure count.
+func (g *LoginGuard) RecordSuccess(ctx context.Context, userID int64, email, ip string) error {
+	ctx, span := startSpan(ctx, "LoginGuard.RecordSuccess")
+	defer span.End()
+
+	_, err := g.loginAttemptRepository.Create(ctx, &models.LoginAttempt{
+		UserID:  &userID,
+		Email:   normalizeEmail(email),
+		IP:      ip,
+		Success: true,
+	})
+	return err
+}
+
+// RecordFailure logs a rejected login. Only LoginFailureInvalidCredentials
+// and LoginFailureInvalidSecondFactor count towards a lockout.
+func (g *LoginGuard) RecordFailure(ctx context.Context, email, ip, reason string) error {
+	ctx, span := startSpan(ctx, "LoginGuard.RecordFailure")
+	defer span.End()
+
+	_, err := g.loginAttemptRepository.Create(ctx, &models.LoginAttempt{
+		Email:         normalizeEmail(email),
+		IP:            ip,
+		FailureReason: &reason,
+	})
+	return err
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// LoginAttemptRepository defines persistence operations for login attempts.
+type LoginAttemptRepository interface {
+	Create(ctx context.Context, a *models.LoginAttempt) (int64, error)
+	RecentFailures(ctx context.Context, email string, window time.Duration) (int, time.Duration, error)
+}
@@ -0,0 +1,193 @@
+package services_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"richisntreal-backend/internal/core/domain/models"
+	"richisntreal-backend/internal/core/services"
+)
+
+// loginAttemptRepo keeps attempts in a slice and counts failures the way
+// the MySQL query does.
+type loginAttemptRepo struct {
+	mu       sync.Mutex
+	attempts []*models.LoginAttempt
+}
+
+func (r *loginAttemptRepo) Create(_ context.Context, a *models.LoginAttempt) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	a.ID = int64(len(r.attempts) + 1)
+	if a.CreatedAt.IsZero() {
+		a.CreatedAt = time.Now()
+	}
+	r.attempts = append(r.attempts, a)
+	return a.ID, nil
+}
+
+func (r *loginAttemptRepo) RecentFailures(_ context.Context, email string, window time.Duration) (int, time.Duration, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var lastSuccess time.Time
+	for _, a := range r.attempts {
+		if a.Email == email && a.Success && a.CreatedAt.After(lastSuccess) {
+			lastSuccess = a.CreatedAt
+		}
+	}
+	var count int
+	var latest time.Time
+	for _, a := range r.attempts {
+		if a.Email != email || a.Success || a.FailureReason == nil ||
+			a.CreatedAt.Before(time.Now().Add(-window)) || !a.CreatedAt.After(lastSuccess) {
+			continue
+		}
+		switch *a.FailureReason {
+		case models.LoginFailureInvalidCredentials, models.LoginFailureInvalidSecondFactor:
+			count++
+			if a.CreatedAt.After(latest) {
+				latest = a.CreatedAt
+			}
+		}
+	}
+	if count == 0 {
+		return 0, 0, nil
+	}
+	return count, time.Since(latest), nil
+}
+
+func TestLoginGuardLockedFor(t *testing.T) {
+	const (
+		maxFailures = 3
+		window      = 15 * time.Minute
+		lockout     = 10 * time.Minute
+	)
+	type attempt struct {
+		ago    time.Duration
+		reason string // empty for a successful login
+	}
+	fails := func(n int, reason string, ago time.Duration) []attempt {
+		out := make([]attempt, n)
+		for i := range out {
+			out[i] = attempt{ago, reason}
+		}
+		return out
+	}
+	tests := []struct {
+		name     string
+		attempts []attempt
+		disabled bool // a zero failure limit
+		// wantLocked is roughly how much longer the account stays locked
+		wantLocked time.Duration
+	}{
+		{
+			name: "no attempts",
+		},
+		{
+			name:     "one short of the limit",
+			attempts: fails(maxFailures-1, models.LoginFailureInvalidCredentials, time.Minute),
+		},
+		{
+			name:       "wrong passwords reach the limit",
+			attempts:   fails(maxFailures, models.LoginFailureInvalidCredentials, time.Minute),
+			wantLocked: lockout - time.Minute,
+		},
+		{
+			name: "wrong second-factor codes count as well",
+			attempts: append(fails(1, models.LoginFailureInvalidCredentials, 2*time.Minute),
+				fails(maxFailures-1, models.LoginFailureInvalidSecondFactor, time.Minute)...),
+			wantLocked: lockout - time.Minute,
+		},
+		{
+			name: "refusals that prove nothing don't count",
+			attempts: []attempt{
+				{time.Minute, models.LoginFailureEmailNotVerified},
+				{time.Minute, models.LoginFailureAccountDisabled},
+				{time.Minute, models.LoginFailureRateLimited},
+				{time.Minute, models.LoginFailureLocked},
+			},
+		},
+		{
+			name:     "the lock runs out after the lockout",
+			attempts: fails(maxFailures, models.LoginFailureInvalidCredentials, lockout+time.Minute),
+		},
+		{
+			name: "failures older than the window are forgotten",
+			attempts: append(fails(maxFailures-1, models.LoginFailureInvalidCredentials, window+time.Minute),
+				attempt{time.Minute, models.LoginFailureInvalidCredentials}),
+		},
+		{
+			name: "a successful login clears earlier failures",
+			attempts: append(fails(maxFailures-1, models.LoginFailureInvalidCredentials, 3*time.Minute),
+				attempt{2 * time.Minute, ""}, attempt{time.Minute, models.LoginFailureInvalidCredentials}),
+		},
+		{
+			name:     "a zero limit disables locking",
+			attempts: fails(10, models.LoginFailureInvalidCredentials, time.Minute),
+			disabled: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := &loginAttemptRepo{}
+			for _, a := range tt.attempts {
+				la := &models.LoginAttempt{Email: "ada@example.com", IP: "192.0.2.1", CreatedAt: time.Now().Add(-a.ago)}
+				if a.reason == "" {
+					la.Success = true
+				} else {
+					reason := a.reason
+					la.FailureReason = &reason
+				}
+				if _, err := repo.Create(ctx, la); err != nil {
+					t.Fatal(err)
+				}
+			}
+			limit := maxFailures
+			if tt.disabled {
+				limit = 0
+			}
+			guard := services.NewLoginGuard(repo, limit, window, lockout)
+
+			// the address is matched however it was typed
+			got, err := guard.LockedFor(ctx, " ADA@example.com")
+			if err != nil {
+				t.Fatalf("LockedFor: %v", err)
+			}
+			if tt.wantLocked == 0 && got != 0 {
+				t.Fatalf("LockedFor = %v, want unlocked", got)
+			}
+			if tt.wantLocked != 0 && (got <= tt.wantLocked-time.Second || got > tt.wantLocked) {
+				t.Fatalf("LockedFor = %v, want about %v", got, tt.wantLocked)
+			}
+		})
+	}
+}
+
+func TestLoginGuardRecords(t *testing.T) {
+	ctx := context.Background()
+	repo := &loginAttemptRepo{}
+	guard := services.NewLoginGuard(repo, 2, time.Hour, time.Hour)
+
+	for i := 0; i < 2; i++ {
+		if err := guard.RecordFailure(ctx, "Ada@Example.com ", "192.0.2.1", models.LoginFailureInvalidSecondFactor); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got, _ := guard.LockedFor(ctx, "ada@example.com"); got == 0 {
+		t.Fatal("LockedFor = 0 after two wrong codes, want the account locked")
+	}
+	if err := guard.RecordSuccess(ctx, 1, "ada@example.com", "192.0.2.1"); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := guard.LockedFor(ctx, "ada@example.com"); got != 0 {
+		t.Errorf("LockedFor = %v after a successful login, want unlocked", got)
+	}
+	for _, a := range repo.attempts {
+		if a.Email != "ada@example.com" {
+			t.Errorf("attempt recorded for %q, want the normalized address", a.Email)
+		}
+	}
+}
@@ -0,0 +1,268 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"richisntreal-backend/internal/core/domain/models"
+)
+
+var ErrUnknownProvider = errors.New("unknown identity provider")
+var ErrInvalidOIDCState = errors.New("invalid or expired login state")
+var ErrIdentityRejected = errors.New("identity provider login failed")
+var ErrIdentityEmailUnverified = errors.New("the provider did not confirm an email address for this account")
+var ErrLocalEmailUnverified = errors.New("an account with this email exists but its address is not verified; verify it before signing in with a provider")
+
+const oidcStateTTL = 10 * time.Minute
+
+// OIDCService signs users in through external OpenID Connect providers,
+// creating or linking local accounts as needed.
+type OIDCService struct {
+	providers          map[string]IdentityProvider
+	identityRepository IdentityRepository
+	userRepository     UserRepository
+	loginStarter       LoginStarter
+}
+
+// NewOIDCService constructs a new OIDCService.
+func NewOIDCService(
+	providers []IdentityProvider,
+	identityRepository IdentityRepository,
+	userRepository UserRepository,
+	loginStarter LoginStarter,
+) *OIDCService {
+	byName := make(map[string]IdentityProvider, len(providers))
+	for _, p := range providers {
+		byName[p.Name()] = p
+	}
+	return &OIDCService{
+		providers:          byName,
+		identityRepository: identityRepository,
+		userRepository:     userRepository,
+		loginStarter:       loginStarter,
+	}
+}
+
+// Providers lists the configured provider names, sorted.
+func (s *OIDCService) Providers(ctx context.Context) []string {
+	ctx, span := startSpan(ctx, "OIDCService.Providers")
+	defer span.End()
+
+	names := make([]string, 0, len(s.providers))
+	for name := range s.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// BeginLogin starts an authorization-code flow with PKCE and returns the
+// provider URL to send the browser to.
+func (s *OIDCService) BeginLogin(ctx context.Context, provider string) (string, error) {
+	ctx, span := startSpan(ctx, "OIDCService.BeginLogin")
+	defer span.End()
+
+	p, ok := s.providers[provider]
+	if !ok {
+		return "", ErrUnknownProvider
+	}
+
+	state, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+	nonce, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+	verifier, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+	_, err = s.identityRepository.CreateLoginState(ctx, &models.OIDCLoginState{
+		Provider:     provider,
+		StateHash:    hashToken(state),
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		ExpiresAt:    time.Now().Add(oidcStateTTL),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	challenge := sha256.Sum256([]byte(verifier))
+	return p.AuthCodeURL(ctx, state, nonce, base64.RawURLEncoding.EncodeToString(challenge[:]))
+}
+
+// CompleteLogin finishes the flow the provider redirected back from. The
+// external identity is matched to a user by an earlier link, else by a
+// verified email address, else a new user is created.
+//
+// A local account whose owner never verified the address is not linked:
+// whoever registered it may not own the mailbox, and linking would hand the
+// mailbox owner an account the registrant still holds the password to.
+func (s *OIDCService) CompleteLogin(ctx context.Context, provider, state, code string) (*models.LoginResult, error) {
+	ctx, span := startSpan(ctx, "OIDCService.CompleteLogin")
+	defer span.End()
+
+	p, ok := s.providers[provider]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+
+	// 1) redeem the state we handed out in BeginLogin
+	st, err := s.identityRepository.FindLoginState(ctx, hashToken(state))
+	if err != nil {
+		return nil, err
+	}
+	if st == nil || st.UsedAt != nil || st.Provider != provider || time.Now().After(st.ExpiresAt) {
+		return nil, ErrInvalidOIDCState
+	}
+	used, err := s.identityRepository.MarkLoginStateUsed(ctx, st.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !used {
+		return nil, ErrInvalidOIDCState
+	}
+
+	// 2) swap the code for a validated identity
+	ext, err := p.Exchange(ctx, code, st.CodeVerifier, st.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrIdentityRejected, err)
+	}
+
+	// 3) find or create the local user, then log them in
+	user, err := s.resolveUser(ctx, ext)
+	if err != nil {
+		return nil, err
+	}
+	return s.loginStarter.StartLogin(ctx, user)
+}
+
+func (s *OIDCService) resolveUser(ctx context.Context, ext *models.ExternalIdentity) (*models.User, error) {
+	link, err := s.identityRepository.FindIdentity(ctx, ext.Provider, ext.Subject)
+	if err != nil {
+		return nil, err
+	}
+	if link != nil {
+		user, err := s.userRepository.FindByID(ctx, link.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if user == nil {
+			return nil, ErrUserNotFound
+		}
+		return user, nil
+	}
+
+	// an unverified address could belong to anyone, so it must not be
+	// used to take over or claim an account
+	if ext.Email == "" || !ext.EmailVerified {
+		return nil, ErrIdentityEmailUnverified
+	}
+
+	user, err := s.userRepository.FindByEmail(ctx, ext.Email)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case user == nil:
+		if user, err = s.createUser(ctx, ext); err != nil {
+			return nil, err
+		}
+		// the provider vouched for the address it was created with
+		if err := s.userRepository.MarkEmailVerified(ctx, user.ID); err != nil {
+			return nil, err
+		}
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+	case user.EmailVerifiedAt == nil:
+		return nil, ErrLocalEmailUnverified
+	}
+
+	_, err = s.identityRepository.CreateIdentity(ctx, &models.UserIdentity{
+		UserID:   user.ID,
+		Provider: ext.Provider,
+		Subject:  ext.Subject,
+		Email:    ext.Email,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// createUser registers a user who only ever signs in through a provider.
+// Their password is random and unknown to them; they can set one through
+// the password reset flow.
+func (s *OIDCService) createUser(ctx context.Context, ext *models.ExternalIdentity) (*models.User, error) {
+	secret, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(secret), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	username := ext.PreferredUsername
+	if username == "" {
+		username, _, _ = strings.Cut(ext.Email, "@")
+	}
+	user := &models.User{
+		Username:  username,
+		Email:     ext.Email,
+		Password:  string(hash),
+		FirstName: ext.GivenName,
+		LastName:  ext.FamilyName,
+		Role:      models.RoleCustomer,
+	}
+	id, err := s.userRepository.Create(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	user.ID = id
+	return user, nil
+}
+
+func randomToken() (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// IdentityProvider is one external OpenID Connect provider; see the
+// infrastructure/oidc package.
+type IdentityProvider interface {
+	Name() string
+	AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error)
+	Exchange(ctx context.Context, code, codeVerifier, nonce string) (*models.ExternalIdentity, error)
+}
+
+// LoginStarter logs in a user whose identity is already established;
+// UserService implements it.
+type LoginStarter interface {
+	StartLogin(ctx context.Context, user *models.User) (*models.LoginResult, error)
+}
+
+// IdentityRepository defines persistence operations for external identities.
+type IdentityRepository interface {
+	FindIdentity(ctx context.Context, provider, subject string) (*models.UserIdentity, error)
+	FindIdentitiesByUser(ctx context.Context, userID int64) ([]*models.UserIdentity, error)
+	CreateIdentity(ctx context.Context, id *models.UserIdentity) (int64, error)
+	CreateLoginState(ctx context.Context, s *models.OIDCLoginState) (int64, error)
+	FindLoginState(ctx context.Context, stateHash string) (*models.OIDCLoginState, error)
+	MarkLoginStateUsed(ctx context.Context, id int64) (bool, error)
+}
@@ -0,0 +1,328 @@
+package services_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"richisntreal-backend/internal/core/domain/models"
+	"richisntreal-backend/internal/core/services"
+	"richisntreal-backend/internal/infrastructure/memory"
+)
+
+// fakeProvider stands in for the issuer: it remembers the challenge and
+// nonce of each authorization URL and only redeems a code with the verifier
+// and nonce that belong to it.
+type fakeProvider struct {
+	identity models.ExternalIdentity
+
+	mu      sync.Mutex
+	pending map[string][2]string // code -> challenge, nonce
+}
+
+func (p *fakeProvider) Name() string { return p.identity.Provider }
+
+func (p *fakeProvider) AuthCodeURL(_ context.Context, state, nonce, codeChallenge string) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.pending == nil {
+		p.pending = make(map[string][2]string)
+	}
+	p.pending["code-"+state] = [2]string{codeChallenge, nonce}
+	return "https://idp.example/authorize?" + url.Values{"state": {state}}.Encode(), nil
+}
+
+func (p *fakeProvider) Exchange(_ context.Context, code, codeVerifier, nonce string) (*models.ExternalIdentity, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	want, ok := p.pending[code]
+	if !ok {
+		return nil, errors.New("unknown code")
+	}
+	sum := sha256.Sum256([]byte(codeVerifier))
+	if base64.RawURLEncoding.EncodeToString(sum[:]) != want[0] {
+		return nil, errors.New("PKCE verifier does not match the challenge")
+	}
+	if nonce != want[1] {
+		return nil, errors.New("nonce mismatch")
+	}
+	ext := p.identity
+	return &ext, nil
+}
+
+// identityRepo keeps links and login states in maps.
+type identityRepo struct {
+	mu         sync.Mutex
+	identities []*models.UserIdentity
+	states     map[string]*models.OIDCLoginState
+}
+
+func (r *identityRepo) FindIdentity(_ context.Context, provider, subject string) (*models.UserIdentity, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, id := range r.identities {
+		if id.Provider == provider && id.Subject == subject {
+			return id, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *identityRepo) FindIdentitiesByUser(_ context.Context, userID int64) ([]*models.UserIdentity, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []*models.UserIdentity
+	for _, id := range r.identities {
+		if id.UserID == userID {
+			out = append(out, id)
+		}
+	}
+	return out, nil
+}
+
+func (r *identityRepo) CreateIdentity(_ context.Context, id *models.UserIdentity) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	id.ID = int64(len(r.identities) + 1)
+	r.identities = append(r.identities, id)
+	return id.ID, nil
+}
+
+func (r *identityRepo) CreateLoginState(_ context.Context, s *models.OIDCLoginState) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.states == nil {
+		r.states = make(map[string]*models.OIDCLoginState)
+	}
+	s.ID = int64(len(r.states) + 1)
+	r.states[s.StateHash] = s
+	return s.ID, nil
+}
+
+func (r *identityRepo) FindLoginState(_ context.Context, stateHash string) (*models.OIDCLoginState, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if s, ok := r.states[stateHash]; ok {
+		c := *s
+		return &c, nil
+	}
+	return nil, nil
+}
+
+func (r *identityRepo) MarkLoginStateUsed(_ context.Context, id int64) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, s := range r.states {
+		if s.ID == id && s.UsedAt == nil {
+			now := time.Now()
+			s.UsedAt = &now
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// loginStarter logs the user in without issuing tokens.
+type loginStarter struct{}
+
+func (loginStarter) StartLogin(_ context.Context, user *models.User) (*models.LoginResult, error) {
+	return &models.LoginResult{User: user}, nil
+}
+
+type oidcFixture struct {
+	svc        *services.OIDCService
+	provider   *fakeProvider
+	identities *identityRepo
+	users      *memory.UserRepository
+}
+
+func newOIDCFixture(ext models.ExternalIdentity) *oidcFixture {
+	f := &oidcFixture{
+		provider:   &fakeProvider{identity: ext},
+		identities: &identityRepo{},
+		users:      memory.NewUserRepository(memory.NewStore()),
+	}
+	f.svc = services.NewOIDCService([]services.IdentityProvider{f.provider}, f.identities, f.users, loginStarter{})
+	return f
+}
+
+// begin starts a login and returns the state the browser carries back.
+func (f *oidcFixture) begin(t *testing.T) string {
+	t.Helper()
+	raw, err := f.svc.BeginLogin(context.Background(), f.provider.Name())
+	if err != nil {
+		t.Fatalf("BeginLogin: %v", err)
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u.Query().Get("state")
+}
+
+func (f *oidcFixture) addUser(t *testing.T, email, password string, verified bool) *models.User {
+	t.Helper()
+	ctx := context.Background()
+	u := &models.User{Username: "local", Email: email, Password: password, Role: models.RoleCustomer}
+	id, err := f.users.Create(ctx, u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if verified {
+		if err := f.users.MarkEmailVerified(ctx, id); err != nil {
+			t.Fatal(err)
+		}
+	}
+	u, err = f.users.FindByID(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u
+}
+
+func TestOIDCCompleteLoginResolvesUser(t *testing.T) {
+	verified := models.ExternalIdentity{Provider: "idp", Subject: "sub-1", Email: "ada@example.com", EmailVerified: true, GivenName: "Ada"}
+
+	tests := []struct {
+		name  string
+		ext   models.ExternalIdentity
+		setup func(t *testing.T, f *oidcFixture) (userID int64)
+		// wantErr is the error CompleteLogin must fail with; otherwise it
+		// must log in the user setup returned, or a new one when that is 0.
+		wantErr error
+	}{
+		{
+			name: "creates a verified user",
+			ext:  verified,
+		},
+		{
+			name: "links a verified local account",
+			ext:  verified,
+			setup: func(t *testing.T, f *oidcFixture) int64 {
+				return f.addUser(t, "ADA@example.com", "hash", true).ID
+			},
+		},
+		{
+			name: "refuses an unverified local account",
+			ext:  verified,
+			setup: func(t *testing.T, f *oidcFixture) int64 {
+				f.addUser(t, "ada@example.com", "attacker-hash", false)
+				return 0
+			},
+			wantErr: services.ErrLocalEmailUnverified,
+		},
+		{
+			name: "follows an existing link whatever the email",
+			ext:  models.ExternalIdentity{Provider: "idp", Subject: "sub-1", Email: "new@example.com"},
+			setup: func(t *testing.T, f *oidcFixture) int64 {
+				u := f.addUser(t, "ada@example.com", "hash", true)
+				_, _ = f.identities.CreateIdentity(context.Background(), &models.UserIdentity{UserID: u.ID, Provider: "idp", Subject: "sub-1"})
+				return u.ID
+			},
+		},
+		{
+			name:    "rejects an email the provider did not verify",
+			ext:     models.ExternalIdentity{Provider: "idp", Subject: "sub-1", Email: "ada@example.com"},
+			wantErr: services.ErrIdentityEmailUnverified,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newOIDCFixture(tt.ext)
+			var wantID int64
+			if tt.setup != nil {
+				wantID = tt.setup(t, f)
+			}
+			links := len(f.identities.identities)
+			state := f.begin(t)
+
+			res, err := f.svc.CompleteLogin(context.Background(), "idp", state, "code-"+state)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("CompleteLogin error = %v, want %v", err, tt.wantErr)
+				}
+				if got := len(f.identities.identities); got != links {
+					t.Errorf("identities = %d after a refused login, want %d", got, links)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CompleteLogin: %v", err)
+			}
+			if wantID != 0 && res.User.ID != wantID {
+				t.Errorf("logged in user %d, want %d", res.User.ID, wantID)
+			}
+			stored, err := f.users.FindByID(context.Background(), res.User.ID)
+			if err != nil || stored == nil {
+				t.Fatalf("FindByID: %v, %v", stored, err)
+			}
+			if stored.EmailVerifiedAt == nil {
+				t.Error("user is not verified after logging in through the provider")
+			}
+			link, _ := f.identities.FindIdentity(context.Background(), "idp", "sub-1")
+			if link == nil || link.UserID != res.User.ID {
+				t.Errorf("identity link = %+v, want one to user %d", link, res.User.ID)
+			}
+		})
+	}
+}
+
+func TestOIDCCompleteLoginState(t *testing.T) {
+	ext := models.ExternalIdentity{Provider: "idp", Subject: "sub-1", Email: "ada@example.com", EmailVerified: true}
+	ctx := context.Background()
+
+	t.Run("state is single use", func(t *testing.T) {
+		f := newOIDCFixture(ext)
+		state := f.begin(t)
+		if _, err := f.svc.CompleteLogin(ctx, "idp", state, "code-"+state); err != nil {
+			t.Fatalf("first CompleteLogin: %v", err)
+		}
+		if _, err := f.svc.CompleteLogin(ctx, "idp", state, "code-"+state); !errors.Is(err, services.ErrInvalidOIDCState) {
+			t.Fatalf("replayed CompleteLogin error = %v, want ErrInvalidOIDCState", err)
+		}
+	})
+
+	t.Run("unknown state", func(t *testing.T) {
+		f := newOIDCFixture(ext)
+		f.begin(t)
+		if _, err := f.svc.CompleteLogin(ctx, "idp", "forged", "code-forged"); !errors.Is(err, services.ErrInvalidOIDCState) {
+			t.Fatalf("CompleteLogin error = %v, want ErrInvalidOIDCState", err)
+		}
+	})
+
+	t.Run("expired state", func(t *testing.T) {
+		f := newOIDCFixture(ext)
+		state := f.begin(t)
+		for _, s := range f.identities.states {
+			s.ExpiresAt = time.Now().Add(-time.Second)
+		}
+		if _, err := f.svc.CompleteLogin(ctx, "idp", state, "code-"+state); !errors.Is(err, services.ErrInvalidOIDCState) {
+			t.Fatalf("CompleteLogin error = %v, want ErrInvalidOIDCState", err)
+		}
+	})
+
+	t.Run("PKCE verifier and nonce reach the provider", func(t *testing.T) {
+		// the fake provider rejects the code unless the stored verifier
+		// hashes to the challenge and the nonce is the one sent out
+		f := newOIDCFixture(ext)
+		state := f.begin(t)
+		for _, s := range f.identities.states {
+			s.Nonce = "tampered"
+		}
+		if _, err := f.svc.CompleteLogin(ctx, "idp", state, "code-"+state); !errors.Is(err, services.ErrIdentityRejected) {
+			t.Fatalf("CompleteLogin error = %v, want ErrIdentityRejected", err)
+		}
+	})
+
+	t.Run("unknown provider", func(t *testing.T) {
+		f := newOIDCFixture(ext)
+		if _, err := f.svc.BeginLogin(ctx, "nope"); !errors.Is(err, services.ErrUnknownProvider) {
+			t.Fatalf("BeginLogin error = %v, want ErrUnknownProvider", err)
+		}
+	})
+}
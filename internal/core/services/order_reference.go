@@ -0,0 +1,42 @@
+package services
+
+import (
+	"crypto/rand"
+	"math/big"
+	"strings"
+)
+
+// referenceAlphabet leaves out 0/O, 1/I/L so references survive being read
+// out over the phone.
+const referenceAlphabet = "23456789ABCDEFGHJKMNPQRSTUVWXYZ"
+
+const referencePrefix = "RNR"
+
+// newOrderReference returns a random reference such as "RNR-7K3Q-9XZ2".
+func newOrderReference() (string, error) {
+	var b strings.Builder
+	b.WriteString(referencePrefix)
+	max := big.NewInt(int64(len(referenceAlphabet)))
+	for i := 0; i < 8; i++ {
+		if i%4 == 0 {
+			b.WriteByte('-')
+		}
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		b.WriteByte(referenceAlphabet[n.Int64()])
+	}
+	return b.String(), nil
+}
+
+// normalizeOrderReference accepts references typed loosely (lower case,
+// spaces, missing dashes) and returns them in canonical form.
+func normalizeOrderReference(ref string) string {
+	compact := strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(ref))
+	compact = strings.TrimPrefix(compact, referencePrefix)
+	if len(compact) != 8 {
+		return ""
+	}
+	return referencePrefix + "-" + compact[:4] + "-" + compact[4:]
+}
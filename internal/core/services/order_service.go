@@ -1,27 +1,49 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"richisntreal-backend/internal/core/domain/models"
+	"richisntreal-backend/internal/infrastructure/logging"
 )
 
 type OrderService struct {
-	orderRepository OrderRepository
-	cartRepository  CartRepository
+	orderRepository   OrderRepository
+	cartRepository    CartRepository
+	productRepository ProductRepository
+	invoiceRepository InvoiceRepository
+	metrics           OrderMetrics
 }
 
-func NewOrderService(orderRepository OrderRepository, cartRepository CartRepository) *OrderService {
-	return &OrderService{orderRepository: orderRepository, cartRepository: cartRepository}
+func NewOrderService(
+	orderRepository OrderRepository,
+	cartRepository CartRepository,
+	productRepository ProductRepository,
+	invoiceRepository InvoiceRepository,
+	metrics OrderMetrics,
+) *OrderService {
+	return &OrderService{
+		orderRepository:   orderRepository,
+		cartRepository:    cartRepository,
+		productRepository: productRepository,
+		invoiceRepository: invoiceRepository,
+		metrics:           metrics,
+	}
 }
 
-func (s *OrderService) CreateOrder(userID int64) (*models.Order, error) {
+// CreateOrder turns the user's cart into an order. The billing address is
+// optional and only needed for invoicing.
+func (s *OrderService) CreateOrder(ctx context.Context, userID int64, billing *models.Address) (*models.Order, error) {
+	ctx, span := startSpan(ctx, "OrderService.CreateOrder")
+	defer span.End()
+
 	// 1) fetch the cart
-	cart, err := s.cartRepository.FindByUserID(userID)
+	cart, err := s.cartRepository.FindByUserID(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
 	if cart == nil || len(cart.Items) == 0 {
-		return nil, errors.New("cart is empty")
+		return nil, ErrCartEmpty
 	}
 
 	// 2) calculate total
@@ -30,19 +52,47 @@ func (s *OrderService) CreateOrder(userID int64) (*models.Order, error) {
 		total += float64(ci.Quantity) * ci.UnitPrice
 	}
 
-	// 3) insert into orders table
+	// 3) reserve stock, handing back what we took if any line falls short
+	for i, ci := range cart.Items {
+		ok, err := s.productRepository.ReserveStock(ctx, ci.ProductID, ci.Quantity)
+		if err == nil && !ok {
+			err = ErrInsufficientStock
+		}
+		if err != nil {
+			for _, reserved := range cart.Items[:i] {
+				_ = s.productRepository.ReleaseStock(ctx, reserved.ProductID, reserved.Quantity)
+			}
+			return nil, err
+		}
+	}
+
+	// from here on a failure hands the stock back and withdraws whatever was
+	// written of the order, so no inventory is lost and no half-written
+	// order can be paid for
+	var orderID int64
+	fail := func(err error) (*models.Order, error) {
+		s.abandonOrder(ctx, orderID, cart.Items)
+		return nil, err
+	}
+
+	// 4) insert into orders table
+	reference, err := s.uniqueReference(ctx)
+	if err != nil {
+		return fail(err)
+	}
 	order := &models.Order{
-		UserID: userID,
-		Total:  total,
-		Status: "pending",
+		Reference: reference,
+		UserID:    userID,
+		Total:     total,
+		Status:    models.OrderStatusPending,
 	}
-	orderID, err := s.orderRepository.CreateOrder(order)
+	orderID, err = s.orderRepository.CreateOrder(ctx, order)
 	if err != nil {
-		return nil, err
+		return fail(err)
 	}
 	order.ID = orderID
 
-	// 4) insert each cart item as an order_item
+	// 5) insert each cart item as an order_item
 	for _, ci := range cart.Items {
 		oi := &models.OrderItem{
 			OrderID:   orderID,
@@ -50,27 +100,85 @@ func (s *OrderService) CreateOrder(userID int64) (*models.Order, error) {
 			Quantity:  ci.Quantity,
 			UnitPrice: ci.UnitPrice,
 		}
-		_, err := s.orderRepository.CreateOrderItem(oi)
+		_, err := s.orderRepository.CreateOrderItem(ctx, oi)
 		if err != nil {
-			return nil, err
+			return fail(err)
 		}
 		order.Items = append(order.Items, *oi)
 	}
 
-	// 5) clear the cart
-	if err := s.cartRepository.DeleteItemsByCartID(cart.ID); err != nil {
-		return nil, err
+	// 6) remember where to bill
+	if billing != nil {
+		billing.OrderID = orderID
+		billing.Kind = models.AddressKindBilling
+		id, err := s.orderRepository.CreateAddress(ctx, billing)
+		if err != nil {
+			return fail(err)
+		}
+		billing.ID = id
+		order.BillingAddress = billing
+	}
+
+	// 7) clear the cart
+	if err := s.cartRepository.DeleteItemsByCartID(ctx, cart.ID); err != nil {
+		return fail(err)
 	}
 
+	s.metrics.OrderCreated()
 	return order, nil
 }
 
-func (s *OrderService) GetOrdersForUser(userID int64) ([]*models.Order, error) {
-	return s.orderRepository.FindOrdersByUser(userID)
+// abandonOrder undoes a CreateOrder that failed after reserving stock: it
+// cancels the order, if one was written, and releases every reservation.
+// It runs even if the caller has gone, since nobody else will clean up.
+func (s *OrderService) abandonOrder(ctx context.Context, orderID int64, items []models.CartItem) {
+	ctx = context.WithoutCancel(ctx)
+	log := logging.FromContext(ctx)
+	if orderID != 0 {
+		if _, err := s.orderRepository.CancelOrder(ctx, orderID, "could not be completed"); err != nil {
+			log.Error("could not cancel abandoned order", "order_id", orderID, "error", err)
+		}
+	}
+	for _, ci := range items {
+		if err := s.productRepository.ReleaseStock(ctx, ci.ProductID, ci.Quantity); err != nil {
+			log.Error("could not release stock of abandoned order", "order_id", orderID, "product_id", ci.ProductID, "error", err)
+		}
+	}
+}
+
+func (s *OrderService) GetOrdersForUser(ctx context.Context, userID int64) ([]*models.Order, error) {
+	ctx, span := startSpan(ctx, "OrderService.GetOrdersForUser")
+	defer span.End()
+
+	return s.orderRepository.FindOrdersByUser(ctx, userID)
+}
+
+func (s *OrderService) GetOrderByID(ctx context.Context, orderID int64) (*models.Order, error) {
+	ctx, span := startSpan(ctx, "OrderService.GetOrderByID")
+	defer span.End()
+
+	ord, err := s.orderRepository.FindOrderByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if ord == nil {
+		return nil, ErrOrderNotFound
+	}
+	return ord, nil
 }
 
-func (s *OrderService) GetOrderByID(orderID int64) (*models.Order, error) {
-	ord, err := s.orderRepository.FindOrderByID(orderID)
+// LookupOrder finds an order by its public reference for a guest who can
+// also name the email it was placed with. Any mismatch is ErrOrderNotFound,
+// so the endpoint can't be used to probe which references exist.
+func (s *OrderService) LookupOrder(ctx context.Context, reference, email string) (*models.Order, error) {
+	ctx, span := startSpan(ctx, "OrderService.LookupOrder")
+	defer span.End()
+
+	reference = normalizeOrderReference(reference)
+	if reference == "" || email == "" {
+		return nil, ErrOrderNotFound
+	}
+	ord, err := s.orderRepository.FindOrderByReferenceAndEmail(ctx, reference, email)
 	if err != nil {
 		return nil, err
 	}
@@ -80,11 +188,201 @@ func (s *OrderService) GetOrderByID(orderID int64) (*models.Order, error) {
 	return ord, nil
 }
 
+// uniqueReference draws references until one is free. With 31^8 possible
+// values a retry is rare; the unique index guards against races.
+func (s *OrderService) uniqueReference(ctx context.Context) (string, error) {
+	for i := 0; i < 5; i++ {
+		ref, err := newOrderReference()
+		if err != nil {
+			return "", err
+		}
+		existing, err := s.orderRepository.FindOrderByReference(ctx, ref)
+		if err != nil {
+			return "", err
+		}
+		if existing == nil {
+			return ref, nil
+		}
+	}
+	return "", errors.New("could not allocate an order reference")
+}
+
+// CancelOrder marks an unfulfilled order as cancelled, records why and puts
+// its reserved stock back. Settling the payment is up to the caller.
+func (s *OrderService) CancelOrder(ctx context.Context, orderID int64, reason string) (*models.Order, error) {
+	ctx, span := startSpan(ctx, "OrderService.CancelOrder")
+	defer span.End()
+
+	ord, err := s.GetOrderByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if !ord.Cancellable() {
+		return nil, ErrOrderNotCancellable
+	}
+
+	ok, err := s.orderRepository.CancelOrder(ctx, orderID, reason)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrOrderNotCancellable
+	}
+
+	for _, it := range ord.Items {
+		if err := s.productRepository.ReleaseStock(ctx, it.ProductID, it.Quantity); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.GetOrderByID(ctx, orderID)
+}
+
+// ClaimForPayment moves a pending order to paying, so that only one charge
+// for it can be in flight and it can't be cancelled under the charge.
+func (s *OrderService) ClaimForPayment(ctx context.Context, orderID int64) (*models.Order, error) {
+	ctx, span := startSpan(ctx, "OrderService.ClaimForPayment")
+	defer span.End()
+
+	ord, err := s.GetOrderByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if ord.Status != models.OrderStatusPending {
+		return nil, ErrOrderNotPayable
+	}
+	ok, err := s.orderRepository.UpdateStatus(ctx, orderID, models.OrderStatusPending, models.OrderStatusPaying)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrOrderNotPayable
+	}
+	ord.Status = models.OrderStatusPaying
+	return ord, nil
+}
+
+// ReleasePaymentClaim puts an order claimed by ClaimForPayment back to
+// pending after its charge failed.
+func (s *OrderService) ReleasePaymentClaim(ctx context.Context, orderID int64) error {
+	ctx, span := startSpan(ctx, "OrderService.ReleasePaymentClaim")
+	defer span.End()
+
+	_, err := s.orderRepository.UpdateStatus(ctx, orderID, models.OrderStatusPaying, models.OrderStatusPending)
+	return err
+}
+
+// MarkPaid moves a pending or paying order to paid and issues its invoice.
+// Calling it again for an order that is already paid only makes sure the
+// invoice exists.
+func (s *OrderService) MarkPaid(ctx context.Context, orderID int64) (*models.Invoice, error) {
+	ctx, span := startSpan(ctx, "OrderService.MarkPaid")
+	defer span.End()
+
+	ord, err := s.GetOrderByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if ord.Status != models.OrderStatusPaid {
+		if ord.Status != models.OrderStatusPending && ord.Status != models.OrderStatusPaying {
+			return nil, ErrInvalidOrderTransition
+		}
+		ok, err := s.orderRepository.UpdateStatus(ctx, orderID, ord.Status, models.OrderStatusPaid)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, ErrInvalidOrderTransition
+		}
+	}
+	return s.invoiceRepository.Allocate(ctx, orderID)
+}
+
+// SearchOrders lists orders across all customers for staff.
+func (s *OrderService) SearchOrders(ctx context.Context, filter models.OrderFilter) ([]*models.Order, int, error) {
+	ctx, span := startSpan(ctx, "OrderService.SearchOrders")
+	defer span.End()
+
+	return s.orderRepository.SearchOrders(ctx, filter)
+}
+
+// ChangeStatus moves an order along its fulfilment path on behalf of staff.
+// Marking an order paid issues its invoice; cancelling has its own flow.
+func (s *OrderService) ChangeStatus(ctx context.Context, orderID int64, to string) (*models.Order, error) {
+	ctx, span := startSpan(ctx, "OrderService.ChangeStatus")
+	defer span.End()
+
+	ord, err := s.GetOrderByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if !ord.CanTransition(to) {
+		return nil, ErrInvalidOrderTransition
+	}
+
+	if to == models.OrderStatusPaid {
+		if _, err := s.MarkPaid(ctx, orderID); err != nil {
+			return nil, err
+		}
+	} else {
+		ok, err := s.orderRepository.UpdateStatus(ctx, orderID, ord.Status, to)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, ErrInvalidOrderTransition
+		}
+	}
+	return s.GetOrderByID(ctx, orderID)
+}
+
+// AddNote attaches an internal staff note to an order.
+func (s *OrderService) AddNote(ctx context.Context, orderID, authorID int64, body string) (*models.OrderNote, error) {
+	ctx, span := startSpan(ctx, "OrderService.AddNote")
+	defer span.End()
+
+	if _, err := s.GetOrderByID(ctx, orderID); err != nil {
+		return nil, err
+	}
+	note := &models.OrderNote{OrderID: orderID, AuthorID: authorID, Body: body}
+	id, err := s.orderRepository.CreateNote(ctx, note)
+	if err != nil {
+		return nil, err
+	}
+	note.ID = id
+	return note, nil
+}
+
+func (s *OrderService) GetNotes(ctx context.Context, orderID int64) ([]*models.OrderNote, error) {
+	ctx, span := startSpan(ctx, "OrderService.GetNotes")
+	defer span.End()
+
+	return s.orderRepository.FindNotes(ctx, orderID)
+}
+
 var ErrOrderNotFound = errors.New("order not found")
+var ErrInvalidOrderTransition = errors.New("order cannot move to that status")
+var ErrOrderNotCancellable = errors.New("order can no longer be cancelled")
+var ErrOrderNotPayable = errors.New("order is not awaiting payment")
+var ErrInsufficientStock = errors.New("insufficient stock")
+var ErrCartEmpty = errors.New("cart is empty")
+
+// OrderMetrics counts order events; metrics.Metrics implements it.
+type OrderMetrics interface {
+	OrderCreated()
+}
 
 type OrderRepository interface {
-	CreateOrder(o *models.Order) (int64, error)
-	CreateOrderItem(item *models.OrderItem) (int64, error)
-	FindOrdersByUser(userID int64) ([]*models.Order, error)
-	FindOrderByID(orderID int64) (*models.Order, error)
+	CreateOrder(ctx context.Context, o *models.Order) (int64, error)
+	CreateOrderItem(ctx context.Context, item *models.OrderItem) (int64, error)
+	FindOrdersByUser(ctx context.Context, userID int64) ([]*models.Order, error)
+	FindOrderByID(ctx context.Context, orderID int64) (*models.Order, error)
+	CancelOrder(ctx context.Context, orderID int64, reason string) (bool, error)
+	UpdateStatus(ctx context.Context, orderID int64, from, to string) (bool, error)
+	CreateAddress(ctx context.Context, addr *models.Address) (int64, error)
+	FindOrderByReference(ctx context.Context, reference string) (*models.Order, error)
+	FindOrderByReferenceAndEmail(ctx context.Context, reference, email string) (*models.Order, error)
+	SearchOrders(ctx context.Context, filter models.OrderFilter) ([]*models.Order, int, error)
+	CreateNote(ctx context.Context, note *models.OrderNote) (int64, error)
+	FindNotes(ctx context.Context, orderID int64) ([]*models.OrderNote, error)
 }
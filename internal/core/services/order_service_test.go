@@ -0,0 +1,207 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"richisntreal-backend/internal/core/domain/models"
+	"richisntreal-backend/internal/core/services"
+	"richisntreal-backend/internal/infrastructure/memory"
+)
+
+func TestCreateOrder(t *testing.T) {
+	type line struct {
+		sku   string
+		qty   int
+		price float64
+		stock *int // nil is untracked
+	}
+	tests := []struct {
+		name    string
+		cart    []line
+		billing bool
+		wantErr error
+		// wantTotal and wantStock are checked when the order goes through;
+		// on failure every tracked stock level must be back where it was
+		wantTotal float64
+		wantStock map[string]int
+	}{
+		{
+			name:    "empty cart",
+			wantErr: services.ErrCartEmpty,
+		},
+		{
+			name:      "one line, untracked stock",
+			cart:      []line{{"MUG", 2, 9.5, nil}},
+			wantTotal: 19,
+		},
+		{
+			name:      "reserves tracked stock and bills",
+			cart:      []line{{"MUG", 2, 9.5, intPtr(5)}, {"TEE", 1, 20, intPtr(1)}},
+			billing:   true,
+			wantTotal: 39,
+			wantStock: map[string]int{"MUG": 3, "TEE": 0},
+		},
+		{
+			name:    "a short line hands back what was reserved",
+			cart:    []line{{"MUG", 2, 9.5, intPtr(5)}, {"TEE", 2, 20, intPtr(1)}},
+			wantErr: services.ErrInsufficientStock,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newShop()
+			ctx := context.Background()
+			userID := s.addUser(t)
+			ids := map[string]int64{}
+			for _, l := range tt.cart {
+				ids[l.sku] = s.addProduct(t, l.sku, l.price, l.stock)
+				if _, err := s.cartSvc.AddItem(ctx, userID, ids[l.sku], l.qty, l.price); err != nil {
+					t.Fatalf("AddItem: %v", err)
+				}
+			}
+			var billing *models.Address
+			if tt.billing {
+				billing = &models.Address{Name: "Ada Lovelace", Line1: "1 Main Street", City: "London", PostalCode: "N1 1AA", Country: "GB"}
+			}
+
+			order, err := s.orderSvc.CreateOrder(ctx, userID, billing)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("CreateOrder error = %v, want %v", err, tt.wantErr)
+				}
+				for _, l := range tt.cart {
+					if l.stock != nil {
+						if got := s.stock(t, ids[l.sku]); got != *l.stock {
+							t.Errorf("%s stock = %d after a failed order, want %d", l.sku, got, *l.stock)
+						}
+					}
+				}
+				if orders, _ := s.orderSvc.GetOrdersForUser(ctx, userID); len(orders) != 0 {
+					t.Errorf("failed CreateOrder left %d orders", len(orders))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CreateOrder: %v", err)
+			}
+
+			stored, err := s.orderSvc.GetOrderByID(ctx, order.ID)
+			if err != nil || stored == nil {
+				t.Fatalf("GetOrderByID = %+v, %v", stored, err)
+			}
+			if stored.Status != models.OrderStatusPending || stored.Total != tt.wantTotal || stored.UserID != userID {
+				t.Errorf("order = %s total %v user %d, want pending total %v user %d",
+					stored.Status, stored.Total, stored.UserID, tt.wantTotal, userID)
+			}
+			if stored.Reference == "" || stored.Reference != order.Reference {
+				t.Errorf("reference = %q, returned %q", stored.Reference, order.Reference)
+			}
+			if len(stored.Items) != len(tt.cart) {
+				t.Errorf("order has %d items, want %d", len(stored.Items), len(tt.cart))
+			}
+			for i, it := range stored.Items {
+				if l := tt.cart[i]; it.ProductID != ids[l.sku] || it.Quantity != l.qty || it.UnitPrice != l.price {
+					t.Errorf("item %d = %+v, want %s x%d at %v", i, it, l.sku, l.qty, l.price)
+				}
+			}
+			if tt.billing && (stored.BillingAddress == nil || stored.BillingAddress.Kind != models.AddressKindBilling || stored.BillingAddress.City != "London") {
+				t.Errorf("billing address = %+v", stored.BillingAddress)
+			}
+			for sku, want := range tt.wantStock {
+				if got := s.stock(t, ids[sku]); got != want {
+					t.Errorf("%s stock = %d, want %d", sku, got, want)
+				}
+			}
+			if cart, _ := s.cartSvc.GetCart(ctx, userID); len(cart.Items) != 0 {
+				t.Errorf("cart still has %d items after ordering", len(cart.Items))
+			}
+		})
+	}
+}
+
+// failingOrders is the in-memory order store with one write broken.
+type failingOrders struct {
+	*memory.OrderRepository
+	failOn string
+}
+
+var errWriteFailed = errors.New("write failed")
+
+func (r failingOrders) CreateOrder(ctx context.Context, o *models.Order) (int64, error) {
+	if r.failOn == "order" {
+		return 0, errWriteFailed
+	}
+	return r.OrderRepository.CreateOrder(ctx, o)
+}
+
+func (r failingOrders) CreateOrderItem(ctx context.Context, item *models.OrderItem) (int64, error) {
+	if r.failOn == "item" {
+		return 0, errWriteFailed
+	}
+	return r.OrderRepository.CreateOrderItem(ctx, item)
+}
+
+func (r failingOrders) CreateAddress(ctx context.Context, addr *models.Address) (int64, error) {
+	if r.failOn == "address" {
+		return 0, errWriteFailed
+	}
+	return r.OrderRepository.CreateAddress(ctx, addr)
+}
+
+// failingCarts is the in-memory cart store unable to clear a cart.
+type failingCarts struct {
+	*memory.CartRepository
+}
+
+func (failingCarts) DeleteItemsByCartID(context.Context, int64) error {
+	return errWriteFailed
+}
+
+func TestCreateOrderUndoesFailedWrites(t *testing.T) {
+	for _, failOn := range []string{"order", "item", "address", "cart"} {
+		t.Run(failOn, func(t *testing.T) {
+			s := newShop()
+			ctx := context.Background()
+			orders := failingOrders{OrderRepository: s.orders, failOn: failOn}
+			var carts services.CartRepository = s.carts
+			if failOn == "cart" {
+				carts = failingCarts{s.carts}
+			}
+			svc := services.NewOrderService(orders, carts, s.products, nil, noMetrics{})
+
+			userID := s.addUser(t)
+			mug := s.addProduct(t, "MUG", 9.5, intPtr(5))
+			tee := s.addProduct(t, "TEE", 20, intPtr(1))
+			for _, id := range []int64{mug, tee} {
+				if _, err := s.cartSvc.AddItem(ctx, userID, id, 1, 10); err != nil {
+					t.Fatal(err)
+				}
+			}
+			billing := &models.Address{Name: "Ada Lovelace", Line1: "1 Main Street", City: "London", PostalCode: "N1 1AA", Country: "GB"}
+
+			if _, err := svc.CreateOrder(ctx, userID, billing); !errors.Is(err, errWriteFailed) {
+				t.Fatalf("CreateOrder error = %v, want the write error", err)
+			}
+			if got := s.stock(t, mug); got != 5 {
+				t.Errorf("MUG stock = %d, want 5 back", got)
+			}
+			if got := s.stock(t, tee); got != 1 {
+				t.Errorf("TEE stock = %d, want 1 back", got)
+			}
+			userOrders, err := s.orders.FindOrdersByUser(ctx, userID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, o := range userOrders {
+				if o.Status != models.OrderStatusCancelled {
+					t.Errorf("order %d left %s, want cancelled", o.ID, o.Status)
+				}
+			}
+			if cart, _ := s.cartSvc.GetCart(ctx, userID); len(cart.Items) != 2 {
+				t.Errorf("cart has %d items, want both kept for another try", len(cart.Items))
+			}
+		})
+	}
+}
@@ -1,51 +1,96 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"math"
 	"strings"
+	"time"
 
 	stripe "github.com/stripe/stripe-go/v74"
 	"github.com/stripe/stripe-go/v74/charge"
+	"github.com/stripe/stripe-go/v74/refund"
 
 	"richisntreal-backend/internal/core/domain/models"
+	"richisntreal-backend/internal/infrastructure/logging"
 )
 
 // ErrPaymentFailed is returned when the gateway reports a failure.
 var ErrPaymentFailed = errors.New("payment failed")
 
+// ErrPaymentOutcomeUnknown is returned when the gateway could not be heard
+// back from, so the charge may or may not have gone through.
+var ErrPaymentOutcomeUnknown = errors.New("payment outcome unknown")
+
+// ErrRefundFailed is returned when the gateway refuses to refund or void a charge.
+var ErrRefundFailed = errors.New("refund failed")
+
+// ErrRefundOutcomeUnknown is returned when the gateway could not be heard
+// back from about a refund, so the money may or may not have gone out.
+var ErrRefundOutcomeUnknown = errors.New("refund outcome unknown")
+
+// ErrNothingToRefund is returned when an order has no captured payment.
+var ErrNothingToRefund = errors.New("no captured payment to refund")
+
+// ErrRefundExceedsPayment is returned when a refund is larger than what is
+// left of the charge after earlier refunds.
+var ErrRefundExceedsPayment = errors.New("refund exceeds the amount still refundable")
+
+// paymentGateway is who actually charges the card, whatever provider the
+// client named; metrics are labelled with it so clients can't add series.
+const paymentGateway = "stripe"
+
+// gatewayTimeout bounds a call to the gateway. Calls are detached from the
+// request, whose deadline is sized for database work: abandoning a charge
+// half way would leave us not knowing whether the customer paid.
+const gatewayTimeout = 30 * time.Second
+
 // PaymentService handles charging and recording payment transactions.
 type PaymentService struct {
 	paymentRepository PaymentRepository
 	stripeKey         string
+	metrics           PaymentMetrics
 }
 
 // NewPaymentService constructs a PaymentService.
 // Pass cfg.App.StripeSecretKey from your bootstrap.
-func NewPaymentService(paymentRepository PaymentRepository, stripeKey string) *PaymentService {
+func NewPaymentService(paymentRepository PaymentRepository, stripeKey string, metrics PaymentMetrics) *PaymentService {
 	return &PaymentService{
 		paymentRepository: paymentRepository,
 		stripeKey:         stripeKey,
+		metrics:           metrics,
 	}
 }
 
 // ProcessPayment creates a pending record, sends a Stripe charge,
 // updates the record with the result, and returns the final transaction.
 func (s *PaymentService) ProcessPayment(
+	ctx context.Context,
 	orderID int64,
 	amount float64,
 	currency, provider, token string,
 ) (*models.PaymentTransaction, error) {
-	// 1) Create a pending transaction
+	ctx, span := startSpan(ctx, "PaymentService.ProcessPayment")
+	defer span.End()
+
+	// 1) Build the charge, then create a pending transaction for it
+	params := &stripe.ChargeParams{
+		Amount:   stripe.Int64(int64(math.Round(amount * 100))), // convert dollars to cents
+		Currency: stripe.String(strings.ToLower(currency)),
+	}
+	if err := params.SetSource(token); err != nil { // e.g. "tok_visa" in test mode
+		return nil, err
+	}
 	tx := &models.PaymentTransaction{
 		OrderID:  orderID,
 		Amount:   amount,
 		Currency: strings.ToLower(currency),
 		Provider: provider,
 		Token:    token,
-		Status:   "pending",
+		Status:   models.PaymentStatusPending,
 	}
-	id, err := s.paymentRepository.Create(tx)
+	id, err := s.paymentRepository.Create(ctx, tx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create payment record: %w", err)
 	}
@@ -53,29 +98,42 @@ func (s *PaymentService) ProcessPayment(
 
 	// 2) Configure Stripe and send the charge
 	stripe.Key = s.stripeKey
-
-	params := &stripe.ChargeParams{
-		Amount:   stripe.Int64(int64(amount * 100)), // convert dollars to cents
-		Currency: stripe.String(tx.Currency),
-	}
-	err = params.SetSource(token)
-	if err != nil {
-		return nil, err
-	} // e.g. "tok_visa" in test mode
-
+	gctx, cancel := gatewayContext(ctx)
+	defer cancel()
+	params.Context = gctx
 	ch, err := charge.New(params)
+	// once Stripe has answered, record the outcome even if the client is gone
+	ctx = context.WithoutCancel(ctx)
 	if err != nil {
-		// update record as failed
+		s.metrics.PaymentFailed(paymentGateway)
+		span.RecordError(err)
 		msg := err.Error()
-		_ = s.paymentRepository.UpdateStatus(id, "failed", &msg)
+		if !gatewayRefused(err) {
+			// the charge may have been made; keep it pending for someone to check
+			logging.FromContext(ctx).Error("stripe charge outcome unknown", "order_id", orderID, "payment_id", id, "error", err)
+			msg = "outcome unknown: " + msg
+			if uerr := s.paymentRepository.UpdateStatus(ctx, id, models.PaymentStatusPending, &msg); uerr != nil {
+				logging.FromContext(ctx).Error("could not record unknown payment outcome", "payment_id", id, "error", uerr)
+			}
+			return nil, fmt.Errorf("%w: %s", ErrPaymentOutcomeUnknown, err.Error())
+		}
+		// update record as failed
+		logging.FromContext(ctx).Warn("stripe charge failed", "order_id", orderID, "payment_id", id, "error", err)
+		if uerr := s.paymentRepository.UpdateStatus(ctx, id, models.PaymentStatusFailed, &msg); uerr != nil {
+			logging.FromContext(ctx).Error("could not mark payment failed", "payment_id", id, "error", uerr)
+		}
 		return nil, fmt.Errorf("%w: %s", ErrPaymentFailed, err.Error())
 	}
 
 	// 3) On success, update our transaction
+	s.metrics.PaymentSucceeded(paymentGateway)
 	providerTxID := ch.ID
 	tx.ProviderTxID = &providerTxID
 	tx.Status = string(ch.Status) // e.g. "succeeded"
-	if err = s.paymentRepository.UpdateStatus(id, tx.Status, nil); err != nil {
+	if err = s.paymentRepository.UpdateProviderTxID(ctx, id, providerTxID); err != nil {
+		return nil, fmt.Errorf("failed to record provider transaction: %w", err)
+	}
+	if err = s.paymentRepository.UpdateStatus(ctx, id, tx.Status, nil); err != nil {
 		return nil, fmt.Errorf("failed to update payment status: %w", err)
 	}
 
@@ -83,13 +141,168 @@ func (s *PaymentService) ProcessPayment(
 }
 
 // GetPaymentByOrder fetches the transaction associated with an order.
-func (s *PaymentService) GetPaymentByOrder(orderID int64) (*models.PaymentTransaction, error) {
-	return s.paymentRepository.FindByOrder(orderID)
+func (s *PaymentService) GetPaymentByOrder(ctx context.Context, orderID int64) (*models.PaymentTransaction, error) {
+	ctx, span := startSpan(ctx, "PaymentService.GetPaymentByOrder")
+	defer span.End()
+
+	return s.paymentRepository.FindByOrder(ctx, orderID)
+}
+
+// CancelPayment settles the latest transaction of a cancelled order: a pending
+// payment is voided and a captured one is refunded in full. Orders that were
+// never paid, or whose last attempt failed, are returned untouched. When the
+// gateway refuses, the transaction keeps its status with the reason in its
+// failure message, for RetryCancellationRefunds to pick up.
+func (s *PaymentService) CancelPayment(ctx context.Context, orderID int64) (*models.PaymentTransaction, error) {
+	ctx, span := startSpan(ctx, "PaymentService.CancelPayment")
+	defer span.End()
+
+	tx, err := s.paymentRepository.FindByOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if tx == nil {
+		return nil, nil
+	}
+
+	var status string
+	switch tx.Status {
+	case models.PaymentStatusPending:
+		// refunding an uncaptured charge releases the authorisation
+		status = models.PaymentStatusVoided
+	case models.PaymentStatusSucceeded:
+		status = models.PaymentStatusRefunded
+	default:
+		return tx, nil
+	}
+
+	if tx.ProviderTxID != nil {
+		stripe.Key = s.stripeKey
+		params := &stripe.RefundParams{Charge: tx.ProviderTxID}
+		gctx, cancel := gatewayContext(ctx)
+		defer cancel()
+		params.Context = gctx
+		_, err := refund.New(params)
+		ctx = context.WithoutCancel(ctx)
+		if err != nil {
+			span.RecordError(err)
+			msg := "refund failed: " + err.Error()
+			if uerr := s.paymentRepository.UpdateStatus(ctx, tx.ID, tx.Status, &msg); uerr != nil {
+				logging.FromContext(ctx).Error("could not record failed refund", "payment_id", tx.ID, "error", uerr)
+			}
+			return nil, fmt.Errorf("%w: %s", ErrRefundFailed, err.Error())
+		}
+	}
+
+	if err := s.paymentRepository.UpdateStatus(ctx, tx.ID, status, nil); err != nil {
+		return nil, fmt.Errorf("failed to update payment status: %w", err)
+	}
+	tx.Status = status
+	return tx, nil
+}
+
+// RetryCancellationRefunds settles cancelled orders whose payment is still
+// pending or captured, because the refund failed when they were cancelled.
+// It reports how many it settled; the ones still failing are returned as
+// errors and stay queued.
+func (s *PaymentService) RetryCancellationRefunds(ctx context.Context) (int, error) {
+	ctx, span := startSpan(ctx, "PaymentService.RetryCancellationRefunds")
+	defer span.End()
+
+	txs, err := s.paymentRepository.FindUnsettledCancellations(ctx)
+	if err != nil {
+		return 0, err
+	}
+	var settled int
+	var errs []error
+	for _, tx := range txs {
+		if _, err := s.CancelPayment(ctx, tx.OrderID); err != nil {
+			errs = append(errs, fmt.Errorf("order %d: %w", tx.OrderID, err))
+			continue
+		}
+		settled++
+	}
+	return settled, errors.Join(errs...)
+}
+
+// Refund gives back part of a captured payment, e.g. for returned goods. It
+// may not give back more than earlier refunds have left of the charge. When
+// the gateway refuses it returns ErrRefundFailed and nothing went out; when
+// the gateway can't be heard back from it returns ErrRefundOutcomeUnknown.
+func (s *PaymentService) Refund(ctx context.Context, orderID int64, amount float64) (*models.PaymentTransaction, error) {
+	ctx, span := startSpan(ctx, "PaymentService.Refund")
+	defer span.End()
+
+	tx, err := s.paymentRepository.FindByOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if tx == nil || tx.ProviderTxID == nil ||
+		(tx.Status != models.PaymentStatusSucceeded && tx.Status != models.PaymentStatusPartiallyRefunded) {
+		return nil, ErrNothingToRefund
+	}
+	cents := int64(math.Round(amount * 100)) // convert dollars to cents
+	if cents > int64(math.Round((tx.Amount-tx.RefundedAmount)*100)) {
+		return nil, ErrRefundExceedsPayment
+	}
+
+	stripe.Key = s.stripeKey
+	params := &stripe.RefundParams{
+		Charge: tx.ProviderTxID,
+		Amount: stripe.Int64(cents),
+	}
+	gctx, cancel := gatewayContext(ctx)
+	defer cancel()
+	params.Context = gctx
+	_, err = refund.New(params)
+	// once Stripe has answered, record the outcome even if the client is gone
+	ctx = context.WithoutCancel(ctx)
+	if err != nil {
+		span.RecordError(err)
+		if gatewayRefused(err) {
+			return nil, fmt.Errorf("%w: %s", ErrRefundFailed, err.Error())
+		}
+		// the refund may have gone out; note it on the payment for someone to check
+		logging.FromContext(ctx).Error("stripe refund outcome unknown", "order_id", orderID, "payment_id", tx.ID, "amount", amount, "error", err)
+		msg := "refund outcome unknown: " + err.Error()
+		if uerr := s.paymentRepository.UpdateStatus(ctx, tx.ID, tx.Status, &msg); uerr != nil {
+			logging.FromContext(ctx).Error("could not record unknown refund outcome", "payment_id", tx.ID, "error", uerr)
+		}
+		return nil, fmt.Errorf("%w: %s", ErrRefundOutcomeUnknown, err.Error())
+	}
+
+	if err := s.paymentRepository.AddRefund(ctx, tx.ID, amount); err != nil {
+		return nil, fmt.Errorf("failed to record refund: %w", err)
+	}
+	return s.paymentRepository.FindByOrder(ctx, orderID)
+}
+
+// gatewayContext detaches a gateway call from the request's deadline and
+// cancellation and gives it gatewayTimeout instead.
+func gatewayContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.WithoutCancel(ctx), gatewayTimeout)
+}
+
+// gatewayRefused reports whether Stripe answered err with a client error,
+// which means it did not act on the request. Anything else, a timeout or a
+// server error, leaves the outcome open.
+func gatewayRefused(err error) bool {
+	var serr *stripe.Error
+	return errors.As(err, &serr) && serr.HTTPStatusCode >= 400 && serr.HTTPStatusCode < 500
+}
+
+// PaymentMetrics counts charge outcomes; metrics.Metrics implements it.
+type PaymentMetrics interface {
+	PaymentSucceeded(provider string)
+	PaymentFailed(provider string)
 }
 
 // PaymentRepository required by PaymentService.
 type PaymentRepository interface {
-	Create(tx *models.PaymentTransaction) (int64, error)
-	UpdateStatus(id int64, status string, failureMessage *string) error
-	FindByOrder(orderID int64) (*models.PaymentTransaction, error)
+	Create(ctx context.Context, tx *models.PaymentTransaction) (int64, error)
+	UpdateStatus(ctx context.Context, id int64, status string, failureMessage *string) error
+	UpdateProviderTxID(ctx context.Context, id int64, providerTxID string) error
+	AddRefund(ctx context.Context, id int64, amount float64) error
+	FindByOrder(ctx context.Context, orderID int64) (*models.PaymentTransaction, error)
+	FindUnsettledCancellations(ctx context.Context) ([]*models.PaymentTransaction, error)
 }
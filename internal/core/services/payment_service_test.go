@@ -0,0 +1,347 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	stripe "github.com/stripe/stripe-go/v74"
+
+	"richisntreal-backend/internal/core/domain/models"
+	"richisntreal-backend/internal/core/services"
+)
+
+// fakeStripe answers the Stripe API paths it is given and records the
+// requests it gets.
+type fakeStripe struct {
+	mu       sync.Mutex
+	answers  map[string]stripeAnswer // by path, e.g. "/v1/charges"
+	requests []stripeRequest
+}
+
+type stripeAnswer struct {
+	status int
+	body   string
+}
+
+type stripeRequest struct {
+	path string
+	form url.Values
+}
+
+var (
+	chargeSucceeded = stripeAnswer{http.StatusOK, `{"id":"ch_1","object":"charge","status":"succeeded"}`}
+	cardDeclined    = stripeAnswer{http.StatusPaymentRequired, `{"error":{"type":"card_error","code":"card_declined","message":"Your card was declined."}}`}
+	gatewayDown     = stripeAnswer{http.StatusInternalServerError, `{"error":{"type":"api_error","message":"Something went wrong."}}`}
+	refundOK        = stripeAnswer{http.StatusOK, `{"id":"re_1","object":"refund","status":"succeeded"}`}
+	refundRefused   = stripeAnswer{http.StatusBadRequest, `{"error":{"type":"invalid_request_error","code":"charge_already_refunded","message":"Charge ch_1 has already been refunded."}}`}
+)
+
+// newFakeStripe points the Stripe client at a fake for the rest of the test.
+func newFakeStripe(t *testing.T, answers map[string]stripeAnswer) *fakeStripe {
+	t.Helper()
+	f := &fakeStripe{answers: answers}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("fake Stripe: %v", err)
+		}
+		f.mu.Lock()
+		f.requests = append(f.requests, stripeRequest{path: r.URL.Path, form: r.PostForm})
+		a, ok := f.answers[r.URL.Path]
+		f.mu.Unlock()
+		if !ok {
+			a = stripeAnswer{http.StatusNotFound, `{"error":{"type":"invalid_request_error","message":"unexpected call"}}`}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(a.status)
+		fmt.Fprint(w, a.body)
+	}))
+	t.Cleanup(srv.Close)
+	stripe.SetBackend(stripe.APIBackend, stripe.GetBackendWithConfig(stripe.APIBackend, &stripe.BackendConfig{
+		URL:               stripe.String(srv.URL),
+		MaxNetworkRetries: stripe.Int64(0),
+		LeveledLogger:     &stripe.LeveledLogger{Level: stripe.LevelNull},
+	}))
+	return f
+}
+
+func (f *fakeStripe) calls() []stripeRequest {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]stripeRequest(nil), f.requests...)
+}
+
+// addOrder creates a pending order for a new user.
+func (s *shop) addOrder(t *testing.T, total float64) int64 {
+	t.Helper()
+	id, err := s.orders.CreateOrder(context.Background(), &models.Order{
+		Reference: "ORD-TEST",
+		UserID:    s.addUser(t),
+		Total:     total,
+		Status:    models.OrderStatusPending,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+// addPayment records a transaction for the order as the gateway left it;
+// an empty charge id means it never reached the gateway.
+func (s *shop) addPayment(t *testing.T, orderID int64, amount float64, status, chargeID string, refunded float64) {
+	t.Helper()
+	ctx := context.Background()
+	id, err := s.payments.Create(ctx, &models.PaymentTransaction{
+		OrderID: orderID, Amount: amount, Currency: "eur", Provider: "stripe", Token: "tok_visa", Status: models.PaymentStatusPending,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if chargeID != "" {
+		if err := s.payments.UpdateProviderTxID(ctx, id, chargeID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.payments.UpdateStatus(ctx, id, status, nil); err != nil {
+		t.Fatal(err)
+	}
+	if refunded > 0 {
+		if err := s.payments.AddRefund(ctx, id, refunded); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestProcessPayment(t *testing.T) {
+	tests := []struct {
+		name       string
+		answer     stripeAnswer
+		wantErr    error
+		wantStatus string
+		wantCharge bool   // whether the stored transaction has the charge id
+		wantMsg    string // prefix of the stored failure message
+	}{
+		{name: "charge succeeds", answer: chargeSucceeded, wantStatus: models.PaymentStatusSucceeded, wantCharge: true},
+		{name: "card declined", answer: cardDeclined, wantErr: services.ErrPaymentFailed, wantStatus: models.PaymentStatusFailed, wantMsg: "Your card was declined"},
+		{name: "gateway error leaves it pending", answer: gatewayDown, wantErr: services.ErrPaymentOutcomeUnknown, wantStatus: models.PaymentStatusPending, wantMsg: "outcome unknown: "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newShop()
+			stripeAPI := newFakeStripe(t, map[string]stripeAnswer{"/v1/charges": tt.answer})
+			ctx := context.Background()
+			orderID := s.addOrder(t, 12.5)
+
+			tx, err := s.paymentSvc.ProcessPayment(ctx, orderID, 12.5, "EUR", "stripe", "tok_visa")
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("ProcessPayment error = %v, want %v", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("ProcessPayment: %v", err)
+			} else if tx.Status != tt.wantStatus {
+				t.Errorf("returned status = %s, want %s", tx.Status, tt.wantStatus)
+			}
+
+			calls := stripeAPI.calls()
+			if len(calls) != 1 || calls[0].form.Get("amount") != "1250" || calls[0].form.Get("currency") != "eur" || calls[0].form.Get("source") != "tok_visa" {
+				t.Errorf("Stripe calls = %+v, want one charge of 1250 eur from tok_visa", calls)
+			}
+
+			stored, err := s.paymentSvc.GetPaymentByOrder(ctx, orderID)
+			if err != nil || stored == nil {
+				t.Fatalf("GetPaymentByOrder = %+v, %v", stored, err)
+			}
+			if stored.Status != tt.wantStatus {
+				t.Errorf("stored status = %s, want %s", stored.Status, tt.wantStatus)
+			}
+			if hasCharge := stored.ProviderTxID != nil && *stored.ProviderTxID == "ch_1"; hasCharge != tt.wantCharge {
+				t.Errorf("stored charge id = %v, want it recorded: %v", stored.ProviderTxID, tt.wantCharge)
+			}
+			switch {
+			case tt.wantMsg == "" && stored.FailureMessage != nil:
+				t.Errorf("failure message = %q, want none", *stored.FailureMessage)
+			case tt.wantMsg != "" && (stored.FailureMessage == nil || !strings.Contains(*stored.FailureMessage, tt.wantMsg)):
+				t.Errorf("failure message = %v, want one with %q", stored.FailureMessage, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestRefund(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   string  // of the 50.00 charge
+		refunded float64 // by earlier refunds
+		amount   float64
+		answer   stripeAnswer
+		wantErr  error
+		// wantStatus and wantRefunded describe the stored transaction after
+		// the call, whether or not it failed
+		wantStatus   string
+		wantRefunded float64
+		wantMsg      string // prefix of the stored failure message
+		wantCalls    int
+	}{
+		{
+			name: "part of the charge", status: models.PaymentStatusSucceeded, amount: 20, answer: refundOK,
+			wantStatus: models.PaymentStatusPartiallyRefunded, wantRefunded: 20, wantCalls: 1,
+		},
+		{
+			name: "the rest of the charge", status: models.PaymentStatusPartiallyRefunded, refunded: 20, amount: 30, answer: refundOK,
+			wantStatus: models.PaymentStatusRefunded, wantRefunded: 50, wantCalls: 1,
+		},
+		{
+			name: "more than is left", status: models.PaymentStatusPartiallyRefunded, refunded: 20, amount: 30.01,
+			wantErr:    services.ErrRefundExceedsPayment,
+			wantStatus: models.PaymentStatusPartiallyRefunded, wantRefunded: 20,
+		},
+		{
+			name: "nothing captured", status: models.PaymentStatusPending, amount: 10,
+			wantErr:    services.ErrNothingToRefund,
+			wantStatus: models.PaymentStatusPending,
+		},
+		{
+			name: "rounds to the cent", status: models.PaymentStatusSucceeded, amount: 19.99, answer: refundOK,
+			wantStatus: models.PaymentStatusPartiallyRefunded, wantRefunded: 19.99, wantCalls: 1,
+		},
+		{
+			name: "gateway refuses", status: models.PaymentStatusSucceeded, amount: 10, answer: refundRefused,
+			wantErr:    services.ErrRefundFailed,
+			wantStatus: models.PaymentStatusSucceeded, wantCalls: 1,
+		},
+		{
+			name: "gateway error leaves the outcome open", status: models.PaymentStatusSucceeded, amount: 10, answer: gatewayDown,
+			wantErr:    services.ErrRefundOutcomeUnknown,
+			wantStatus: models.PaymentStatusSucceeded, wantMsg: "refund outcome unknown: ", wantCalls: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newShop()
+			stripeAPI := newFakeStripe(t, map[string]stripeAnswer{"/v1/refunds": tt.answer})
+			ctx := context.Background()
+			orderID := s.addOrder(t, 50)
+			s.addPayment(t, orderID, 50, tt.status, "ch_1", tt.refunded)
+
+			_, err := s.paymentSvc.Refund(ctx, orderID, tt.amount)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Refund error = %v, want %v", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("Refund: %v", err)
+			}
+
+			calls := stripeAPI.calls()
+			if len(calls) != tt.wantCalls {
+				t.Fatalf("Stripe got %d calls, want %d", len(calls), tt.wantCalls)
+			}
+			if tt.wantCalls > 0 {
+				wantCents := strconv.FormatFloat(tt.amount*100, 'f', 0, 64)
+				if calls[0].form.Get("charge") != "ch_1" || calls[0].form.Get("amount") != wantCents {
+					t.Errorf("refund request = %v, want %s cents of ch_1", calls[0].form, wantCents)
+				}
+			}
+
+			stored, err := s.paymentSvc.GetPaymentByOrder(ctx, orderID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stored.Status != tt.wantStatus || stored.RefundedAmount != tt.wantRefunded {
+				t.Errorf("stored = %s with %v refunded, want %s with %v", stored.Status, stored.RefundedAmount, tt.wantStatus, tt.wantRefunded)
+			}
+			if tt.wantMsg != "" && (stored.FailureMessage == nil || !strings.HasPrefix(*stored.FailureMessage, tt.wantMsg)) {
+				t.Errorf("failure message = %v, want one starting %q", stored.FailureMessage, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestCancelPayment(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     string // of the latest transaction; empty for none
+		chargeID   string
+		answer     stripeAnswer
+		wantErr    error
+		wantStatus string
+		wantMsg    string // prefix of the stored failure message
+		wantCalls  int
+	}{
+		{name: "never paid"},
+		{
+			name: "captured charge is refunded", status: models.PaymentStatusSucceeded, chargeID: "ch_1", answer: refundOK,
+			wantStatus: models.PaymentStatusRefunded, wantCalls: 1,
+		},
+		{
+			name: "pending charge is voided", status: models.PaymentStatusPending, chargeID: "ch_1", answer: refundOK,
+			wantStatus: models.PaymentStatusVoided, wantCalls: 1,
+		},
+		{
+			name: "attempt that never reached the gateway", status: models.PaymentStatusPending,
+			wantStatus: models.PaymentStatusVoided,
+		},
+		{
+			name: "failed attempt is left alone", status: models.PaymentStatusFailed, chargeID: "ch_1",
+			wantStatus: models.PaymentStatusFailed,
+		},
+		{
+			name: "refused refund stays queued", status: models.PaymentStatusSucceeded, chargeID: "ch_1", answer: refundRefused,
+			wantErr:    services.ErrRefundFailed,
+			wantStatus: models.PaymentStatusSucceeded, wantMsg: "refund failed: ", wantCalls: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newShop()
+			stripeAPI := newFakeStripe(t, map[string]stripeAnswer{"/v1/refunds": tt.answer})
+			ctx := context.Background()
+			orderID := s.addOrder(t, 50)
+			if tt.status != "" {
+				s.addPayment(t, orderID, 50, tt.status, tt.chargeID, 0)
+			}
+
+			_, err := s.paymentSvc.CancelPayment(ctx, orderID)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("CancelPayment error = %v, want %v", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("CancelPayment: %v", err)
+			}
+
+			calls := stripeAPI.calls()
+			if len(calls) != tt.wantCalls {
+				t.Fatalf("Stripe got %d calls, want %d", len(calls), tt.wantCalls)
+			}
+			if tt.wantCalls > 0 && (calls[0].form.Get("charge") != "ch_1" || calls[0].form.Get("amount") != "") {
+				t.Errorf("refund request = %v, want all of ch_1", calls[0].form)
+			}
+
+			stored, err := s.paymentSvc.GetPaymentByOrder(ctx, orderID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.status == "" {
+				if stored != nil {
+					t.Errorf("transaction = %+v, want none", stored)
+				}
+				return
+			}
+			if stored.Status != tt.wantStatus {
+				t.Errorf("stored status = %s, want %s", stored.Status, tt.wantStatus)
+			}
+			if tt.wantMsg != "" && (stored.FailureMessage == nil || !strings.HasPrefix(*stored.FailureMessage, tt.wantMsg)) {
+				t.Errorf("failure message = %v, want one starting %q", stored.FailureMessage, tt.wantMsg)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"richisntreal-backend/internal/core/domain/models"
+)
+
+// PrivacyService answers data subject requests: handing a user all their
+// data, and erasing it.
+type PrivacyService struct {
+	privacyRepository PrivacyRepository
+	userRepository    UserRepository
+	sessions          SessionRevoker
+}
+
+func NewPrivacyService(
+	privacyRepository PrivacyRepository,
+	userRepository UserRepository,
+	sessions SessionRevoker,
+) *PrivacyService {
+	return &PrivacyService{
+		privacyRepository: privacyRepository,
+		userRepository:    userRepository,
+		sessions:          sessions,
+	}
+}
+
+// ExportUserData gathers everything stored about a user.
+func (s *PrivacyService) ExportUserData(ctx context.Context, userID int64) (*models.DataExport, error) {
+	ctx, span := startSpan(ctx, "PrivacyService.ExportUserData")
+	defer span.End()
+
+	if err := s.requireUser(ctx, userID); err != nil {
+		return nil, err
+	}
+	sections, err := s.privacyRepository.Export(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.DataExport{
+		UserID:      userID,
+		GeneratedAt: time.Now().UTC(),
+		Sections:    sections,
+	}, nil
+}
+
+// EraseUser logs the user out everywhere and scrubs their personal data,
+// keeping the financial records that must be retained.
+func (s *PrivacyService) EraseUser(ctx context.Context, userID int64) error {
+	ctx, span := startSpan(ctx, "PrivacyService.EraseUser")
+	defer span.End()
+
+	if err := s.requireUser(ctx, userID); err != nil {
+		return err
+	}
+	if err := s.sessions.LogoutAll(ctx, userID); err != nil {
+		return err
+	}
+	return s.privacyRepository.Erase(ctx, userID)
+}
+
+func (s *PrivacyService) requireUser(ctx context.Context, userID int64) error {
+	user, err := s.userRepository.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// PrivacyRepository reads and erases personal data across all tables.
+type PrivacyRepository interface {
+	Export(ctx context.Context, userID int64) ([]models.DataExportSection, error)
+	Erase(ctx context.Context, userID int64) error
+}
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"errors"
 
 	"richisntreal-backend/internal/core/domain/models"
@@ -17,22 +18,32 @@ func NewProductService(productRepository ProductRepository) *ProductService {
 	return &ProductService{productRepository: productRepository}
 }
 
-func (s *ProductService) ListProducts() ([]*models.Product, error) {
-	return s.productRepository.FindAll()
+func (s *ProductService) ListProducts(ctx context.Context) ([]*models.Product, error) {
+	ctx, span := startSpan(ctx, "ProductService.ListProducts")
+	defer span.End()
+
+	return s.productRepository.FindAll(ctx)
 }
 
-func (s *ProductService) GetProductByID(id int64) (*models.Product, error) {
-	return s.productRepository.FindByID(id)
+func (s *ProductService) GetProductByID(ctx context.Context, id int64) (*models.Product, error) {
+	ctx, span := startSpan(ctx, "ProductService.GetProductByID")
+	defer span.End()
+
+	return s.productRepository.FindByID(ctx, id)
 }
 
-func (s *ProductService) CreateProduct(name, description, sku string, price float64) (*models.Product, error) {
+func (s *ProductService) CreateProduct(ctx context.Context, name, description, sku string, price float64, stock *int) (*models.Product, error) {
+	ctx, span := startSpan(ctx, "ProductService.CreateProduct")
+	defer span.End()
+
 	p := &models.Product{
 		Name:        name,
 		Description: description,
 		SKU:         sku,
 		Price:       price,
+		Stock:       stock,
 	}
-	id, err := s.productRepository.Create(p)
+	id, err := s.productRepository.Create(ctx, p)
 	if err != nil {
 		return nil, err
 	}
@@ -40,8 +51,11 @@ func (s *ProductService) CreateProduct(name, description, sku string, price floa
 	return p, nil
 }
 
-func (s *ProductService) UpdateProduct(id int64, name, description, sku string, price float64) (*models.Product, error) {
-	existing, err := s.productRepository.FindByID(id)
+func (s *ProductService) UpdateProduct(ctx context.Context, id int64, name, description, sku string, price float64, stock *int) (*models.Product, error) {
+	ctx, span := startSpan(ctx, "ProductService.UpdateProduct")
+	defer span.End()
+
+	existing, err := s.productRepository.FindByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -52,21 +66,27 @@ func (s *ProductService) UpdateProduct(id int64, name, description, sku string,
 	existing.Description = description
 	existing.SKU = sku
 	existing.Price = price
-	if err := s.productRepository.Update(existing); err != nil {
+	existing.Stock = stock
+	if err := s.productRepository.Update(ctx, existing); err != nil {
 		return nil, err
 	}
 	return existing, nil
 }
 
-func (s *ProductService) DeleteProduct(id int64) error {
-	return s.productRepository.Delete(id)
+func (s *ProductService) DeleteProduct(ctx context.Context, id int64) error {
+	ctx, span := startSpan(ctx, "ProductService.DeleteProduct")
+	defer span.End()
+
+	return s.productRepository.Delete(ctx, id)
 }
 
 // ProductRepository defines persistence operations for products.
 type ProductRepository interface {
-	FindAll() ([]*models.Product, error)
-	FindByID(id int64) (*models.Product, error)
-	Create(p *models.Product) (int64, error)
-	Update(p *models.Product) error
-	Delete(id int64) error
+	FindAll(ctx context.Context) ([]*models.Product, error)
+	FindByID(ctx context.Context, id int64) (*models.Product, error)
+	Create(ctx context.Context, p *models.Product) (int64, error)
+	Update(ctx context.Context, p *models.Product) error
+	Delete(ctx context.Context, id int64) error
+	ReserveStock(ctx context.Context, productID int64, qty int) (bool, error)
+	ReleaseStock(ctx context.Context, productID int64, qty int) error
 }
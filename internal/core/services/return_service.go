@@ -0,0 +1,293 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"richisntreal-backend/internal/core/domain/models"
+	"richisntreal-backend/internal/infrastructure/logging"
+)
+
+var ErrReturnNotFound = errors.New("return not found")
+var ErrOrderNotReturnable = errors.New("only delivered orders can be returned")
+var ErrInvalidReturnItems = errors.New("invalid return items")
+var ErrInvalidReturnTransition = errors.New("return cannot move to that status")
+var ErrInvalidResolution = errors.New("resolution must be refund or restock")
+
+// ReturnService drives the RMA lifecycle: request, review, receipt and
+// settlement through either a refund or a restock.
+type ReturnService struct {
+	returnRepository  ReturnRepository
+	orderRepository   OrderRepository
+	productRepository ProductRepository
+	refunder          Refunder
+}
+
+func NewReturnService(
+	returnRepository ReturnRepository,
+	orderRepository OrderRepository,
+	productRepository ProductRepository,
+	refunder Refunder,
+) *ReturnService {
+	return &ReturnService{
+		returnRepository:  returnRepository,
+		orderRepository:   orderRepository,
+		productRepository: productRepository,
+		refunder:          refunder,
+	}
+}
+
+// RequestReturn opens a return for the given lines of a delivered order.
+// Each line may only be returned up to the quantity not already claimed by
+// an earlier, non-rejected return.
+func (s *ReturnService) RequestReturn(ctx context.Context, orderID int64, reason string, items []models.ReturnItem) (*models.ReturnRequest, error) {
+	ctx, span := startSpan(ctx, "ReturnService.RequestReturn")
+	defer span.End()
+
+	ord, err := s.orderRepository.FindOrderByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if ord == nil {
+		return nil, ErrOrderNotFound
+	}
+	if ord.Status != models.OrderStatusDelivered {
+		return nil, ErrOrderNotReturnable
+	}
+	if len(items) == 0 {
+		return nil, ErrInvalidReturnItems
+	}
+
+	// 1) work out how much of each line is still returnable
+	remaining := make(map[int64]int, len(ord.Items))
+	for _, oi := range ord.Items {
+		remaining[oi.ID] = oi.Quantity
+	}
+	previous, err := s.returnRepository.FindByOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	for _, rr := range previous {
+		if rr.Status == models.ReturnStatusRejected {
+			continue
+		}
+		for _, it := range rr.Items {
+			remaining[it.OrderItemID] -= it.Quantity
+		}
+	}
+	for _, it := range items {
+		left, ok := remaining[it.OrderItemID]
+		if !ok || it.Quantity <= 0 || it.Quantity > left {
+			return nil, ErrInvalidReturnItems
+		}
+		remaining[it.OrderItemID] -= it.Quantity
+	}
+
+	// 2) persist the request and its lines
+	rr := &models.ReturnRequest{
+		OrderID: orderID,
+		UserID:  ord.UserID,
+		Status:  models.ReturnStatusRequested,
+		Reason:  reason,
+	}
+	id, err := s.returnRepository.Create(ctx, rr)
+	if err != nil {
+		return nil, err
+	}
+	rr.ID = id
+	for _, it := range items {
+		it.ReturnID = id
+		itemID, err := s.returnRepository.CreateItem(ctx, &it)
+		if err != nil {
+			return nil, err
+		}
+		it.ID = itemID
+		rr.Items = append(rr.Items, it)
+	}
+	return rr, nil
+}
+
+func (s *ReturnService) GetReturn(ctx context.Context, id int64) (*models.ReturnRequest, error) {
+	ctx, span := startSpan(ctx, "ReturnService.GetReturn")
+	defer span.End()
+
+	rr, err := s.returnRepository.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if rr == nil {
+		return nil, ErrReturnNotFound
+	}
+	return rr, nil
+}
+
+func (s *ReturnService) GetReturnsForOrder(ctx context.Context, orderID int64) ([]*models.ReturnRequest, error) {
+	ctx, span := startSpan(ctx, "ReturnService.GetReturnsForOrder")
+	defer span.End()
+
+	return s.returnRepository.FindByOrder(ctx, orderID)
+}
+
+// ListReturns lists every return, optionally narrowed to one status.
+func (s *ReturnService) ListReturns(ctx context.Context, status string) ([]*models.ReturnRequest, error) {
+	ctx, span := startSpan(ctx, "ReturnService.ListReturns")
+	defer span.End()
+
+	return s.returnRepository.FindAll(ctx, status)
+}
+
+// Approve accepts a return and fixes how it will be settled.
+func (s *ReturnService) Approve(ctx context.Context, id int64, resolution, note string) (*models.ReturnRequest, error) {
+	ctx, span := startSpan(ctx, "ReturnService.Approve")
+	defer span.End()
+
+	if resolution != models.ReturnResolutionRefund && resolution != models.ReturnResolutionRestock {
+		return nil, ErrInvalidResolution
+	}
+	return s.transition(ctx, id, models.ReturnStatusApproved, func(rr *models.ReturnRequest) {
+		rr.Resolution = &resolution
+		if note != "" {
+			rr.AdminNote = &note
+		}
+	})
+}
+
+// Reject turns a return down.
+func (s *ReturnService) Reject(ctx context.Context, id int64, note string) (*models.ReturnRequest, error) {
+	ctx, span := startSpan(ctx, "ReturnService.Reject")
+	defer span.End()
+
+	return s.transition(ctx, id, models.ReturnStatusRejected, func(rr *models.ReturnRequest) {
+		if note != "" {
+			rr.AdminNote = &note
+		}
+	})
+}
+
+// MarkReceived records that the goods are back and settles the return as
+// agreed on approval. Each step claims the return by moving it on from the
+// status it was read in, so concurrent calls settle it once. If the gateway
+// refuses the refund the return goes back to received, and calling
+// MarkReceived again retries it; a return left refunding may have had its
+// refund sent and needs a look.
+func (s *ReturnService) MarkReceived(ctx context.Context, id int64) (*models.ReturnRequest, error) {
+	ctx, span := startSpan(ctx, "ReturnService.MarkReceived")
+	defer span.End()
+
+	rr, err := s.GetReturn(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// 1) goods are in
+	if rr.Status == models.ReturnStatusApproved {
+		if err := s.update(ctx, rr, models.ReturnStatusReceived); err != nil {
+			return nil, err
+		}
+	}
+	if rr.Status != models.ReturnStatusReceived || rr.Resolution == nil {
+		return nil, ErrInvalidReturnTransition
+	}
+
+	// 2) settle
+	ord, err := s.orderRepository.FindOrderByID(ctx, rr.OrderID)
+	if err != nil {
+		return nil, err
+	}
+	if ord == nil {
+		return nil, ErrOrderNotFound
+	}
+	lines := make(map[int64]models.OrderItem, len(ord.Items))
+	for _, oi := range ord.Items {
+		lines[oi.ID] = oi
+	}
+
+	switch *rr.Resolution {
+	case models.ReturnResolutionRefund:
+		var amount float64
+		for _, it := range rr.Items {
+			amount += float64(it.Quantity) * lines[it.OrderItemID].UnitPrice
+		}
+		rr.RefundAmount = &amount
+		if err := s.update(ctx, rr, models.ReturnStatusRefunding); err != nil {
+			return nil, err
+		}
+		if _, err := s.refunder.Refund(ctx, rr.OrderID, amount); err != nil {
+			if errors.Is(err, ErrRefundOutcomeUnknown) {
+				// the money may be gone; retrying could refund twice, so the
+				// return stays refunding for someone to settle by hand
+				logging.FromContext(ctx).Error("refund outcome unknown, return left refunding", "return_id", rr.ID, "error", err)
+				return nil, err
+			}
+			// nothing went out; hand the claim back so the refund can be retried
+			rr.RefundAmount = nil
+			if uerr := s.update(context.WithoutCancel(ctx), rr, models.ReturnStatusReceived); uerr != nil {
+				logging.FromContext(ctx).Error("could not release return after failed refund", "return_id", rr.ID, "error", uerr)
+			}
+			return nil, err
+		}
+		if err := s.update(context.WithoutCancel(ctx), rr, models.ReturnStatusRefunded); err != nil {
+			logging.FromContext(ctx).Error("refund sent but return not marked refunded", "return_id", rr.ID, "error", err)
+			return nil, err
+		}
+	case models.ReturnResolutionRestock:
+		if err := s.update(ctx, rr, models.ReturnStatusRestocked); err != nil {
+			return nil, err
+		}
+		for _, it := range rr.Items {
+			if err := s.productRepository.ReleaseStock(ctx, lines[it.OrderItemID].ProductID, it.Quantity); err != nil {
+				return nil, err
+			}
+		}
+	default:
+		return nil, ErrInvalidResolution
+	}
+	return rr, nil
+}
+
+// transition moves a return along a staff decision, applying change to it
+// on the way.
+func (s *ReturnService) transition(ctx context.Context, id int64, to string, change func(rr *models.ReturnRequest)) (*models.ReturnRequest, error) {
+	rr, err := s.GetReturn(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !rr.CanTransition(to) {
+		return nil, ErrInvalidReturnTransition
+	}
+	change(rr)
+	if err := s.update(ctx, rr, to); err != nil {
+		return nil, err
+	}
+	return rr, nil
+}
+
+// update stores rr with status to, provided nobody moved it on since it was
+// read; otherwise it reports ErrInvalidReturnTransition.
+func (s *ReturnService) update(ctx context.Context, rr *models.ReturnRequest, to string) error {
+	from := rr.Status
+	rr.Status = to
+	ok, err := s.returnRepository.Update(ctx, rr, from)
+	if err == nil && !ok {
+		err = ErrInvalidReturnTransition
+	}
+	if err != nil {
+		rr.Status = from
+	}
+	return err
+}
+
+// Refunder gives money back for part of an order; PaymentService implements it.
+type Refunder interface {
+	Refund(ctx context.Context, orderID int64, amount float64) (*models.PaymentTransaction, error)
+}
+
+// ReturnRepository defines persistence operations for return requests.
+type ReturnRepository interface {
+	Create(ctx context.Context, rr *models.ReturnRequest) (int64, error)
+	CreateItem(ctx context.Context, item *models.ReturnItem) (int64, error)
+	Update(ctx context.Context, rr *models.ReturnRequest, from string) (bool, error)
+	FindByID(ctx context.Context, id int64) (*models.ReturnRequest, error)
+	FindByOrder(ctx context.Context, orderID int64) ([]*models.ReturnRequest, error)
+	FindAll(ctx context.Context, status string) ([]*models.ReturnRequest, error)
+}
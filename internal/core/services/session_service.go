@@ -0,0 +1,248 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+
+	"richisntreal-backend/internal/core/domain/models"
+	"richisntreal-backend/internal/infrastructure/logging"
+)
+
+var ErrInvalidRefreshToken = errors.New("invalid refresh token")
+var ErrRefreshTokenReused = errors.New("refresh token reused; session revoked")
+
+// Reasons recorded when a session is revoked.
+const (
+	RevokeReasonLogout     = "logout"
+	RevokeReasonLogoutAll  = "logout_all"
+	RevokeReasonTokenReuse = "token_reuse"
+	RevokeReasonCredential = "credential_change"
+	RevokeReasonAdminEnded = "impersonator_disabled"
+)
+
+// SessionService issues short-lived access tokens paired with rotating
+// refresh tokens, and tracks which sessions are still valid.
+type SessionService struct {
+	sessionRepository SessionRepository
+	signer            TokenSigner
+	accessTTL         time.Duration
+	refreshTTL        time.Duration
+}
+
+// NewSessionService constructs a new SessionService.
+func NewSessionService(
+	sessionRepository SessionRepository,
+	signer TokenSigner,
+	accessTTL, refreshTTL time.Duration,
+) *SessionService {
+	return &SessionService{
+		sessionRepository: sessionRepository,
+		signer:            signer,
+		accessTTL:         accessTTL,
+		refreshTTL:        refreshTTL,
+	}
+}
+
+// StartSession opens a new session for a user who just proved who they are.
+func (s *SessionService) StartSession(ctx context.Context, userID int64) (*models.TokenPair, error) {
+	ctx, span := startSpan(ctx, "SessionService.StartSession")
+	defer span.End()
+
+	sessionID, err := s.sessionRepository.CreateSession(ctx, &models.Session{UserID: userID})
+	if err != nil {
+		return nil, err
+	}
+	return s.issue(ctx, userID, sessionID)
+}
+
+// StartImpersonation opens a session in which an admin acts as a user.
+// It only gets an access token, valid for ttl, that names the admin in an
+// "act" claim; there is no refresh token, so it can't be extended.
+func (s *SessionService) StartImpersonation(ctx context.Context, adminID, userID int64, ttl time.Duration) (*models.TokenPair, error) {
+	ctx, span := startSpan(ctx, "SessionService.StartImpersonation")
+	defer span.End()
+
+	expiresAt := time.Now().Add(ttl)
+	sessionID, err := s.sessionRepository.CreateSession(ctx, &models.Session{
+		UserID:         userID,
+		ImpersonatorID: &adminID,
+		ExpiresAt:      &expiresAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+	access, err := s.signer.Sign(jwt.MapClaims{
+		"sub": userID,
+		"sid": sessionID,
+		"act": map[string]interface{}{"sub": adminID},
+		"iat": time.Now().Unix(),
+		"exp": expiresAt.Unix(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &models.TokenPair{AccessToken: access, ExpiresIn: int64(ttl.Seconds())}, nil
+}
+
+// Refresh exchanges a refresh token for a new token pair. Each refresh
+// token works once: presenting one that was already exchanged means it
+// leaked, so the whole session is revoked.
+func (s *SessionService) Refresh(ctx context.Context, refreshToken string) (*models.TokenPair, error) {
+	ctx, span := startSpan(ctx, "SessionService.Refresh")
+	defer span.End()
+
+	rt, err := s.sessionRepository.FindRefreshToken(ctx, hashToken(refreshToken))
+	if err != nil {
+		return nil, err
+	}
+	if rt == nil {
+		return nil, ErrInvalidRefreshToken
+	}
+	if rt.RotatedAt != nil {
+		logging.FromContext(ctx).Warn("refresh token reused; revoking session", "session_id", rt.SessionID)
+		if err := s.sessionRepository.RevokeSession(ctx, rt.SessionID, RevokeReasonTokenReuse); err != nil {
+			return nil, err
+		}
+		return nil, ErrRefreshTokenReused
+	}
+	if time.Now().After(rt.ExpiresAt) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	session, err := s.sessionRepository.FindSession(ctx, rt.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session == nil || session.RevokedAt != nil || session.ImpersonatorID != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	// a concurrent request may have won the race for this token
+	ok, err := s.sessionRepository.MarkRefreshTokenRotated(ctx, rt.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		if err := s.sessionRepository.RevokeSession(ctx, rt.SessionID, RevokeReasonTokenReuse); err != nil {
+			return nil, err
+		}
+		return nil, ErrRefreshTokenReused
+	}
+
+	return s.issue(ctx, session.UserID, session.ID)
+}
+
+// Logout revokes a single session.
+func (s *SessionService) Logout(ctx context.Context, sessionID int64) error {
+	ctx, span := startSpan(ctx, "SessionService.Logout")
+	defer span.End()
+
+	return s.sessionRepository.RevokeSession(ctx, sessionID, RevokeReasonLogout)
+}
+
+// LogoutAll revokes every session of a user, logging out all devices.
+func (s *SessionService) LogoutAll(ctx context.Context, userID int64) error {
+	ctx, span := startSpan(ctx, "SessionService.LogoutAll")
+	defer span.End()
+
+	return s.sessionRepository.RevokeUserSessions(ctx, userID, RevokeReasonLogoutAll)
+}
+
+// EndImpersonations revokes every impersonation session an admin started,
+// as when the admin's own account is disabled.
+func (s *SessionService) EndImpersonations(ctx context.Context, adminID int64) error {
+	ctx, span := startSpan(ctx, "SessionService.EndImpersonations")
+	defer span.End()
+
+	return s.sessionRepository.RevokeImpersonatorSessions(ctx, adminID, RevokeReasonAdminEnded)
+}
+
+// LogoutOthers revokes every session of a user except the one in use, as
+// after a password change.
+func (s *SessionService) LogoutOthers(ctx context.Context, userID, keepSessionID int64) error {
+	ctx, span := startSpan(ctx, "SessionService.LogoutOthers")
+	defer span.End()
+
+	return s.sessionRepository.RevokeUserSessionsExcept(ctx, userID, keepSessionID, RevokeReasonCredential)
+}
+
+// IsSessionActive reports whether access tokens of the session are still honoured.
+func (s *SessionService) IsSessionActive(ctx context.Context, sessionID int64) (bool, error) {
+	ctx, span := startSpan(ctx, "SessionService.IsSessionActive")
+	defer span.End()
+
+	session, err := s.sessionRepository.FindSession(ctx, sessionID)
+	if err != nil {
+		return false, err
+	}
+	if session == nil || session.RevokedAt != nil {
+		return false, nil
+	}
+	return session.ExpiresAt == nil || time.Now().Before(*session.ExpiresAt), nil
+}
+
+func (s *SessionService) issue(ctx context.Context, userID, sessionID int64) (*models.TokenPair, error) {
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"sub": userID,
+		"sid": sessionID,
+		"iat": now.Unix(),
+		"exp": now.Add(s.accessTTL).Unix(),
+	}
+	access, err := s.signer.Sign(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, err
+	}
+	refresh := base64.RawURLEncoding.EncodeToString(raw)
+	_, err = s.sessionRepository.CreateRefreshToken(ctx, &models.RefreshToken{
+		SessionID: sessionID,
+		TokenHash: hashToken(refresh),
+		ExpiresAt: now.Add(s.refreshTTL),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.TokenPair{
+		AccessToken:  access,
+		RefreshToken: refresh,
+		ExpiresIn:    int64(s.accessTTL.Seconds()),
+	}, nil
+}
+
+// hashToken is how opaque tokens are stored: hex SHA-256. They carry 256
+// bits of randomness, so a fast unsalted hash is enough.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// TokenSigner signs JWT claims with the current signing key.
+type TokenSigner interface {
+	Sign(claims jwt.Claims) (string, error)
+}
+
+// SessionRepository defines persistence operations for sessions and refresh tokens.
+type SessionRepository interface {
+	CreateSession(ctx context.Context, s *models.Session) (int64, error)
+	FindSession(ctx context.Context, id int64) (*models.Session, error)
+	RevokeSession(ctx context.Context, id int64, reason string) error
+	RevokeUserSessions(ctx context.Context, userID int64, reason string) error
+	RevokeUserSessionsExcept(ctx context.Context, userID, keepSessionID int64, reason string) error
+	RevokeImpersonatorSessions(ctx context.Context, adminID int64, reason string) error
+	CreateRefreshToken(ctx context.Context, t *models.RefreshToken) (int64, error)
+	FindRefreshToken(ctx context.Context, tokenHash string) (*models.RefreshToken, error)
+	MarkRefreshTokenRotated(ctx context.Context, id int64) (bool, error)
+}
@@ -0,0 +1,250 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+
+	"richisntreal-backend/internal/core/domain/models"
+	"richisntreal-backend/internal/core/services"
+)
+
+// sessionRepo keeps sessions and refresh tokens in slices. loseRace makes
+// the next rotation fail as if a concurrent request got there first.
+type sessionRepo struct {
+	mu       sync.Mutex
+	sessions []*models.Session
+	tokens   []*models.RefreshToken
+	loseRace bool
+}
+
+func (r *sessionRepo) CreateSession(_ context.Context, s *models.Session) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s.ID = int64(len(r.sessions) + 1)
+	r.sessions = append(r.sessions, s)
+	return s.ID, nil
+}
+
+func (r *sessionRepo) FindSession(_ context.Context, id int64) (*models.Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, s := range r.sessions {
+		if s.ID == id {
+			c := *s
+			return &c, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *sessionRepo) revoke(match func(*models.Session) bool, reason string) {
+	now := time.Now()
+	for _, s := range r.sessions {
+		if s.RevokedAt == nil && match(s) {
+			s.RevokedAt = &now
+			s.RevokedReason = &reason
+		}
+	}
+}
+
+func (r *sessionRepo) RevokeSession(_ context.Context, id int64, reason string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.revoke(func(s *models.Session) bool { return s.ID == id }, reason)
+	return nil
+}
+
+func (r *sessionRepo) RevokeUserSessions(_ context.Context, userID int64, reason string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.revoke(func(s *models.Session) bool { return s.UserID == userID }, reason)
+	return nil
+}
+
+func (r *sessionRepo) RevokeUserSessionsExcept(_ context.Context, userID, keepSessionID int64, reason string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.revoke(func(s *models.Session) bool { return s.UserID == userID && s.ID != keepSessionID }, reason)
+	return nil
+}
+
+func (r *sessionRepo) RevokeImpersonatorSessions(_ context.Context, adminID int64, reason string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.revoke(func(s *models.Session) bool { return s.ImpersonatorID != nil && *s.ImpersonatorID == adminID }, reason)
+	return nil
+}
+
+func (r *sessionRepo) CreateRefreshToken(_ context.Context, t *models.RefreshToken) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t.ID = int64(len(r.tokens) + 1)
+	r.tokens = append(r.tokens, t)
+	return t.ID, nil
+}
+
+func (r *sessionRepo) FindRefreshToken(_ context.Context, tokenHash string) (*models.RefreshToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, t := range r.tokens {
+		if t.TokenHash == tokenHash {
+			c := *t
+			return &c, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *sessionRepo) MarkRefreshTokenRotated(_ context.Context, id int64) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.loseRace {
+		r.loseRace = false
+		return false, nil
+	}
+	for _, t := range r.tokens {
+		if t.ID == id && t.RotatedAt == nil {
+			now := time.Now()
+			t.RotatedAt = &now
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// plainSigner stands in for the JWT key; the tests only look at the
+// refresh tokens.
+type plainSigner struct{}
+
+func (plainSigner) Sign(jwt.Claims) (string, error) {
+	return "access", nil
+}
+
+func TestSessionRefresh(t *testing.T) {
+	tests := []struct {
+		name string
+		// present returns the refresh token to exchange, given the one
+		// the session was opened with
+		present     func(t *testing.T, svc *services.SessionService, repo *sessionRepo, token string) string
+		wantErr     error
+		wantRevoked string // why the session ends up revoked; empty if it stays active
+	}{
+		{
+			name:    "a fresh token rotates",
+			present: func(*testing.T, *services.SessionService, *sessionRepo, string) string { return "" },
+		},
+		{
+			name: "the rotated token works in turn",
+			present: func(t *testing.T, svc *services.SessionService, _ *sessionRepo, token string) string {
+				next, err := svc.Refresh(context.Background(), token)
+				if err != nil {
+					t.Fatalf("first Refresh: %v", err)
+				}
+				return next.RefreshToken
+			},
+		},
+		{
+			name: "reusing a rotated token revokes the session",
+			present: func(t *testing.T, svc *services.SessionService, _ *sessionRepo, token string) string {
+				if _, err := svc.Refresh(context.Background(), token); err != nil {
+					t.Fatalf("first Refresh: %v", err)
+				}
+				return token
+			},
+			wantErr:     services.ErrRefreshTokenReused,
+			wantRevoked: services.RevokeReasonTokenReuse,
+		},
+		{
+			name: "losing the rotation race revokes the session",
+			present: func(_ *testing.T, _ *services.SessionService, repo *sessionRepo, token string) string {
+				repo.loseRace = true
+				return token
+			},
+			wantErr:     services.ErrRefreshTokenReused,
+			wantRevoked: services.RevokeReasonTokenReuse,
+		},
+		{
+			name: "an unknown token",
+			present: func(*testing.T, *services.SessionService, *sessionRepo, string) string {
+				return "forged"
+			},
+			wantErr: services.ErrInvalidRefreshToken,
+		},
+		{
+			name: "an expired token",
+			present: func(_ *testing.T, _ *services.SessionService, repo *sessionRepo, token string) string {
+				repo.tokens[0].ExpiresAt = time.Now().Add(-time.Second)
+				return token
+			},
+			wantErr: services.ErrInvalidRefreshToken,
+		},
+		{
+			name: "a logged out session",
+			present: func(t *testing.T, svc *services.SessionService, _ *sessionRepo, token string) string {
+				if err := svc.Logout(context.Background(), 1); err != nil {
+					t.Fatal(err)
+				}
+				return token
+			},
+			wantErr:     services.ErrInvalidRefreshToken,
+			wantRevoked: services.RevokeReasonLogout,
+		},
+		{
+			name: "a session ended by logging out everywhere",
+			present: func(t *testing.T, svc *services.SessionService, _ *sessionRepo, token string) string {
+				if err := svc.LogoutAll(context.Background(), 7); err != nil {
+					t.Fatal(err)
+				}
+				return token
+			},
+			wantErr:     services.ErrInvalidRefreshToken,
+			wantRevoked: services.RevokeReasonLogoutAll,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := &sessionRepo{}
+			svc := services.NewSessionService(repo, plainSigner{}, time.Minute, time.Hour)
+			pair, err := svc.StartSession(ctx, 7)
+			if err != nil {
+				t.Fatalf("StartSession: %v", err)
+			}
+			token := pair.RefreshToken
+			if presented := tt.present(t, svc, repo, token); presented != "" {
+				token = presented
+			}
+
+			got, err := svc.Refresh(ctx, token)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Refresh error = %v, want %v", err, tt.wantErr)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("Refresh: %v", err)
+				}
+				if got.RefreshToken == "" || got.RefreshToken == token {
+					t.Errorf("Refresh handed back refresh token %q, want a new one", got.RefreshToken)
+				}
+			}
+
+			session, _ := repo.FindSession(ctx, 1)
+			var revoked string
+			if session.RevokedReason != nil {
+				revoked = *session.RevokedReason
+			}
+			if revoked != tt.wantRevoked {
+				t.Errorf("session revoked for %q, want %q", revoked, tt.wantRevoked)
+			}
+			if active, _ := svc.IsSessionActive(ctx, 1); active != (tt.wantRevoked == "") {
+				t.Errorf("IsSessionActive = %v after refresh", active)
+			}
+		})
+	}
+}
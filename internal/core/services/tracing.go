@@ -0,0 +1,16 @@
+package services
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("richisntreal-backend/internal/core/services")
+
+// startSpan opens a span named after the service method, as a child of
+// the request span in ctx. Callers must end it.
+func startSpan(ctx context.Context, name string) (context.Context, trace.Span) {
+	return tracer.Start(ctx, name)
+}
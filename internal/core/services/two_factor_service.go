@@ -0,0 +1,308 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"time"
+
+	"richisntreal-backend/internal/core/domain/models"
+)
+
+var ErrTwoFactorAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+var ErrTwoFactorNotEnabled = errors.New("two-factor authentication is not enabled")
+var ErrTwoFactorNotEnrolling = errors.New("start enrolment first")
+var ErrInvalidTwoFactorCode = errors.New("invalid two-factor code")
+var ErrInvalidChallenge = errors.New("invalid or expired login challenge")
+
+const (
+	recoveryCodeCount = 10
+	loginChallengeTTL = 5 * time.Minute
+)
+
+// TwoFactorService manages TOTP enrolment, recovery codes and the second
+// step of logging in to an account that has 2FA enabled.
+type TwoFactorService struct {
+	twoFactorRepository TwoFactorRepository
+	userRepository      UserRepository
+	userTokenRepository UserTokenRepository
+	issuer              string
+}
+
+// NewTwoFactorService constructs a new TwoFactorService. issuer is the name
+// authenticator apps show next to the account.
+func NewTwoFactorService(
+	twoFactorRepository TwoFactorRepository,
+	userRepository UserRepository,
+	userTokenRepository UserTokenRepository,
+	issuer string,
+) *TwoFactorService {
+	return &TwoFactorService{
+		twoFactorRepository: twoFactorRepository,
+		userRepository:      userRepository,
+		userTokenRepository: userTokenRepository,
+		issuer:              issuer,
+	}
+}
+
+// IsEnabled reports whether the user has confirmed a TOTP secret.
+func (s *TwoFactorService) IsEnabled(ctx context.Context, userID int64) (bool, error) {
+	ctx, span := startSpan(ctx, "TwoFactorService.IsEnabled")
+	defer span.End()
+
+	t, err := s.twoFactorRepository.FindTOTP(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	return t != nil && t.Enabled(), nil
+}
+
+// BeginEnrollment generates a fresh secret for the user to scan. It does
+// nothing to logins until confirmed with ConfirmEnrollment.
+func (s *TwoFactorService) BeginEnrollment(ctx context.Context, userID int64) (*models.TOTPEnrollment, error) {
+	ctx, span := startSpan(ctx, "TwoFactorService.BeginEnrollment")
+	defer span.End()
+
+	user, err := s.userRepository.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	enabled, err := s.IsEnabled(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if enabled {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	secret, err := newTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.twoFactorRepository.SavePendingTOTP(ctx, userID, secret); err != nil {
+		return nil, err
+	}
+	return &models.TOTPEnrollment{
+		Secret: secret,
+		URI:    totpURI(s.issuer, user.Email, secret),
+	}, nil
+}
+
+// ConfirmEnrollment turns 2FA on once the user proves their app produces
+// valid codes, and returns the recovery codes. They are shown only now.
+func (s *TwoFactorService) ConfirmEnrollment(ctx context.Context, userID int64, code string) ([]string, error) {
+	ctx, span := startSpan(ctx, "TwoFactorService.ConfirmEnrollment")
+	defer span.End()
+
+	t, err := s.twoFactorRepository.FindTOTP(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if t == nil {
+		return nil, ErrTwoFactorNotEnrolling
+	}
+	if t.Enabled() {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+	if err := s.checkTOTP(ctx, t, code); err != nil {
+		return nil, err
+	}
+
+	codes, err := s.newRecoveryCodes(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.twoFactorRepository.EnableTOTP(ctx, userID); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// RegenerateRecoveryCodes replaces every recovery code of the user. A
+// current TOTP code is required.
+func (s *TwoFactorService) RegenerateRecoveryCodes(ctx context.Context, userID int64, code string) ([]string, error) {
+	ctx, span := startSpan(ctx, "TwoFactorService.RegenerateRecoveryCodes")
+	defer span.End()
+
+	t, err := s.enabledTOTP(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkTOTP(ctx, t, code); err != nil {
+		return nil, err
+	}
+	return s.newRecoveryCodes(ctx, userID)
+}
+
+// Disable turns 2FA off. It takes either a TOTP code or a recovery code.
+func (s *TwoFactorService) Disable(ctx context.Context, userID int64, code string) error {
+	ctx, span := startSpan(ctx, "TwoFactorService.Disable")
+	defer span.End()
+
+	t, err := s.enabledTOTP(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := s.checkCode(ctx, t, code); err != nil {
+		return err
+	}
+	return s.twoFactorRepository.Delete(ctx, userID)
+}
+
+// Challenge hands out a short-lived login challenge when the user has 2FA
+// enabled. It returns an empty token when they don't.
+func (s *TwoFactorService) Challenge(ctx context.Context, userID int64) (string, time.Duration, error) {
+	ctx, span := startSpan(ctx, "TwoFactorService.Challenge")
+	defer span.End()
+
+	enabled, err := s.IsEnabled(ctx, userID)
+	if err != nil || !enabled {
+		return "", 0, err
+	}
+	if err := s.userTokenRepository.InvalidateOutstanding(ctx, userID, models.TokenPurposeLoginChallenge); err != nil {
+		return "", 0, err
+	}
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", 0, err
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+	_, err = s.userTokenRepository.Create(ctx, &models.UserToken{
+		UserID:    userID,
+		Purpose:   models.TokenPurposeLoginChallenge,
+		TokenHash: hashToken(token),
+		ExpiresAt: time.Now().Add(loginChallengeTTL),
+	})
+	if err != nil {
+		return "", 0, err
+	}
+	return token, loginChallengeTTL, nil
+}
+
+// VerifyChallenge completes a login challenge with a TOTP or recovery code
+// and returns the user it was issued to. A wrong code leaves the challenge
+// usable until it expires; a right one consumes it.
+func (s *TwoFactorService) VerifyChallenge(ctx context.Context, challengeToken, code string) (int64, error) {
+	ctx, span := startSpan(ctx, "TwoFactorService.VerifyChallenge")
+	defer span.End()
+
+	ut, err := s.openChallenge(ctx, challengeToken)
+	if err != nil {
+		return 0, err
+	}
+	t, err := s.enabledTOTP(ctx, ut.UserID)
+	if err != nil {
+		return 0, err
+	}
+	if err := s.checkCode(ctx, t, code); err != nil {
+		return 0, err
+	}
+	ok, err := s.userTokenRepository.MarkUsed(ctx, ut.ID)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		return 0, ErrInvalidChallenge
+	}
+	return ut.UserID, nil
+}
+
+// ChallengedUser returns the user an open login challenge was issued to,
+// so the caller can throttle and lock by account before checking a code.
+func (s *TwoFactorService) ChallengedUser(ctx context.Context, challengeToken string) (int64, error) {
+	ctx, span := startSpan(ctx, "TwoFactorService.ChallengedUser")
+	defer span.End()
+
+	ut, err := s.openChallenge(ctx, challengeToken)
+	if err != nil {
+		return 0, err
+	}
+	return ut.UserID, nil
+}
+
+func (s *TwoFactorService) openChallenge(ctx context.Context, challengeToken string) (*models.UserToken, error) {
+	ut, err := s.userTokenRepository.FindByHash(ctx, models.TokenPurposeLoginChallenge, hashToken(challengeToken))
+	if err != nil {
+		return nil, err
+	}
+	if ut == nil || ut.UsedAt != nil || time.Now().After(ut.ExpiresAt) {
+		return nil, ErrInvalidChallenge
+	}
+	return ut, nil
+}
+
+func (s *TwoFactorService) enabledTOTP(ctx context.Context, userID int64) (*models.TOTPSecret, error) {
+	t, err := s.twoFactorRepository.FindTOTP(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if t == nil || !t.Enabled() {
+		return nil, ErrTwoFactorNotEnabled
+	}
+	return t, nil
+}
+
+// checkTOTP accepts a code once: the step it belongs to is recorded so the
+// same code can't be replayed within its validity window.
+func (s *TwoFactorService) checkTOTP(ctx context.Context, t *models.TOTPSecret, code string) error {
+	step, ok := matchTOTP(t.Secret, code, time.Now())
+	if !ok {
+		return ErrInvalidTwoFactorCode
+	}
+	fresh, err := s.twoFactorRepository.AdvanceTOTPStep(ctx, t.UserID, step)
+	if err != nil {
+		return err
+	}
+	if !fresh {
+		return ErrInvalidTwoFactorCode
+	}
+	return nil
+}
+
+// checkCode accepts a TOTP code or, failing that, burns a recovery code.
+func (s *TwoFactorService) checkCode(ctx context.Context, t *models.TOTPSecret, code string) error {
+	err := s.checkTOTP(ctx, t, code)
+	if !errors.Is(err, ErrInvalidTwoFactorCode) {
+		return err
+	}
+	ok, err := s.twoFactorRepository.UseRecoveryCode(ctx, t.UserID, hashToken(normalizeRecoveryCode(code)))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrInvalidTwoFactorCode
+	}
+	return nil
+}
+
+func (s *TwoFactorService) newRecoveryCodes(ctx context.Context, userID int64) ([]string, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		code, err := newRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes[i] = code
+		hashes[i] = hashToken(normalizeRecoveryCode(code))
+	}
+	if err := s.twoFactorRepository.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// TwoFactorRepository defines persistence operations for TOTP secrets and recovery codes.
+type TwoFactorRepository interface {
+	FindTOTP(ctx context.Context, userID int64) (*models.TOTPSecret, error)
+	SavePendingTOTP(ctx context.Context, userID int64, secret string) error
+	EnableTOTP(ctx context.Context, userID int64) error
+	AdvanceTOTPStep(ctx context.Context, userID, step int64) (bool, error)
+	Delete(ctx context.Context, userID int64) error
+	ReplaceRecoveryCodes(ctx context.Context, userID int64, codeHashes []string) error
+	UseRecoveryCode(ctx context.Context, userID int64, codeHash string) (bool, error)
+}
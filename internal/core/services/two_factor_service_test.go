@@ -0,0 +1,371 @@
+package services_test
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"richisntreal-backend/internal/core/domain/models"
+	"richisntreal-backend/internal/core/services"
+	"richisntreal-backend/internal/infrastructure/memory"
+)
+
+// twoFactorRepo keeps TOTP secrets and recovery codes in maps.
+type twoFactorRepo struct {
+	mu       sync.Mutex
+	totp     map[int64]*models.TOTPSecret
+	recovery map[int64]map[string]bool // code hash -> used
+}
+
+func (r *twoFactorRepo) FindTOTP(_ context.Context, userID int64) (*models.TOTPSecret, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if t, ok := r.totp[userID]; ok {
+		c := *t
+		return &c, nil
+	}
+	return nil, nil
+}
+
+func (r *twoFactorRepo) SavePendingTOTP(_ context.Context, userID int64, secret string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.totp == nil {
+		r.totp = make(map[int64]*models.TOTPSecret)
+	}
+	r.totp[userID] = &models.TOTPSecret{UserID: userID, Secret: secret}
+	return nil
+}
+
+func (r *twoFactorRepo) EnableTOTP(_ context.Context, userID int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	r.totp[userID].EnabledAt = &now
+	return nil
+}
+
+func (r *twoFactorRepo) AdvanceTOTPStep(_ context.Context, userID, step int64) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t := r.totp[userID]
+	if t == nil || t.LastUsedStep >= step {
+		return false, nil
+	}
+	t.LastUsedStep = step
+	return true, nil
+}
+
+func (r *twoFactorRepo) Delete(_ context.Context, userID int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.totp, userID)
+	delete(r.recovery, userID)
+	return nil
+}
+
+func (r *twoFactorRepo) ReplaceRecoveryCodes(_ context.Context, userID int64, codeHashes []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.recovery == nil {
+		r.recovery = make(map[int64]map[string]bool)
+	}
+	r.recovery[userID] = make(map[string]bool)
+	for _, h := range codeHashes {
+		r.recovery[userID][h] = false
+	}
+	return nil
+}
+
+func (r *twoFactorRepo) UseRecoveryCode(_ context.Context, userID int64, codeHash string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	used, ok := r.recovery[userID][codeHash]
+	if !ok || used {
+		return false, nil
+	}
+	r.recovery[userID][codeHash] = true
+	return true, nil
+}
+
+// userTokenRepo keeps one-time tokens in a slice.
+type userTokenRepo struct {
+	mu     sync.Mutex
+	tokens []*models.UserToken
+}
+
+func (r *userTokenRepo) Create(_ context.Context, t *models.UserToken) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t.ID = int64(len(r.tokens) + 1)
+	r.tokens = append(r.tokens, t)
+	return t.ID, nil
+}
+
+func (r *userTokenRepo) FindByHash(_ context.Context, purpose, tokenHash string) (*models.UserToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, t := range r.tokens {
+		if t.Purpose == purpose && t.TokenHash == tokenHash {
+			c := *t
+			return &c, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *userTokenRepo) MarkUsed(_ context.Context, id int64) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, t := range r.tokens {
+		if t.ID == id && t.UsedAt == nil {
+			now := time.Now()
+			t.UsedAt = &now
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *userTokenRepo) InvalidateOutstanding(_ context.Context, userID int64, purpose string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	for _, t := range r.tokens {
+		if t.UserID == userID && t.Purpose == purpose && t.UsedAt == nil {
+			t.UsedAt = &now
+		}
+	}
+	return nil
+}
+
+// totpAt is what an authenticator app shows for secret at step offset
+// from now (RFC 6238 with SHA-1, six digits, 30 second steps).
+func totpAt(t *testing.T, secret string, offset int64) string {
+	t.Helper()
+	return totpForStep(t, secret, time.Now().Unix()/30+offset)
+}
+
+func totpForStep(t *testing.T, secret string, step int64) string {
+	t.Helper()
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(step))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	return fmt.Sprintf("%06d", (binary.BigEndian.Uint32(sum[offset:])&0x7fffffff)%1000000)
+}
+
+type twoFactorFixture struct {
+	svc      *services.TwoFactorService
+	totp     *twoFactorRepo
+	tokens   *userTokenRepo
+	userID   int64
+	secret   string
+	recovery []string
+}
+
+// newTwoFactorFixture enrols a user, confirming with the code of the
+// previous step so that the current and next ones are still unused.
+func newTwoFactorFixture(t *testing.T) *twoFactorFixture {
+	t.Helper()
+	ctx := context.Background()
+	users := memory.NewUserRepository(memory.NewStore())
+	f := &twoFactorFixture{totp: &twoFactorRepo{}, tokens: &userTokenRepo{}}
+	f.svc = services.NewTwoFactorService(f.totp, users, f.tokens, "Shop")
+
+	id, err := users.Create(ctx, &models.User{Username: "ada", Email: "ada@example.com", Role: models.RoleCustomer})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.userID = id
+	enrollment, err := f.svc.BeginEnrollment(ctx, id)
+	if err != nil {
+		t.Fatalf("BeginEnrollment: %v", err)
+	}
+	f.secret = enrollment.Secret
+	if f.recovery, err = f.svc.ConfirmEnrollment(ctx, id, totpAt(t, f.secret, -1)); err != nil {
+		t.Fatalf("ConfirmEnrollment: %v", err)
+	}
+	return f
+}
+
+func TestTOTPHelperMatchesRFC6238(t *testing.T) {
+	// the SHA-1 vector of RFC 6238 appendix B, T = 59s, cut to six digits
+	secret := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+	if got := totpForStep(t, secret, 59/30); got != "287082" {
+		t.Fatalf("totpForStep = %s, want 287082", got)
+	}
+}
+
+func TestTwoFactorVerifyChallenge(t *testing.T) {
+	tests := []struct {
+		name string
+		// answer runs after enrolment and returns the code to answer
+		// the challenge with
+		answer  func(t *testing.T, f *twoFactorFixture) string
+		expired bool // answer after the challenge ran out
+		forged  bool // answer a challenge that was never issued
+		wantErr error
+	}{
+		{
+			name:   "the current code",
+			answer: func(t *testing.T, f *twoFactorFixture) string { return totpAt(t, f.secret, 0) },
+		},
+		{
+			name:   "the next code, for a clock running slow",
+			answer: func(t *testing.T, f *twoFactorFixture) string { return totpAt(t, f.secret, 1) },
+		},
+		{
+			name: "spaces are ignored",
+			answer: func(t *testing.T, f *twoFactorFixture) string {
+				c := totpAt(t, f.secret, 0)
+				return c[:3] + " " + c[3:]
+			},
+		},
+		{
+			name:    "a code too far ahead",
+			answer:  func(t *testing.T, f *twoFactorFixture) string { return totpAt(t, f.secret, 3) },
+			wantErr: services.ErrInvalidTwoFactorCode,
+		},
+		{
+			name:    "the code enrolment used",
+			answer:  func(t *testing.T, f *twoFactorFixture) string { return totpAt(t, f.secret, -1) },
+			wantErr: services.ErrInvalidTwoFactorCode,
+		},
+		{
+			name: "a code replayed within its step",
+			answer: func(t *testing.T, f *twoFactorFixture) string {
+				code := totpAt(t, f.secret, 0)
+				if _, err := f.svc.RegenerateRecoveryCodes(context.Background(), f.userID, code); err != nil {
+					t.Fatalf("RegenerateRecoveryCodes: %v", err)
+				}
+				return code
+			},
+			wantErr: services.ErrInvalidTwoFactorCode,
+		},
+		{
+			name: "an older step once a newer one was used",
+			answer: func(t *testing.T, f *twoFactorFixture) string {
+				if _, err := f.svc.RegenerateRecoveryCodes(context.Background(), f.userID, totpAt(t, f.secret, 1)); err != nil {
+					t.Fatalf("RegenerateRecoveryCodes: %v", err)
+				}
+				return totpAt(t, f.secret, 0)
+			},
+			wantErr: services.ErrInvalidTwoFactorCode,
+		},
+		{
+			name:   "a recovery code",
+			answer: func(_ *testing.T, f *twoFactorFixture) string { return f.recovery[0] },
+		},
+		{
+			name: "a recovery code typed loosely",
+			answer: func(_ *testing.T, f *twoFactorFixture) string {
+				return strings.ToLower(strings.ReplaceAll(f.recovery[0], "-", " "))
+			},
+		},
+		{
+			name: "a recovery code used before",
+			answer: func(t *testing.T, f *twoFactorFixture) string {
+				ctx := context.Background()
+				challenge, _, err := f.svc.Challenge(ctx, f.userID)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if _, err := f.svc.VerifyChallenge(ctx, challenge, f.recovery[0]); err != nil {
+					t.Fatalf("first use of the recovery code: %v", err)
+				}
+				return f.recovery[0]
+			},
+			wantErr: services.ErrInvalidTwoFactorCode,
+		},
+		{
+			name:    "an expired challenge",
+			answer:  func(t *testing.T, f *twoFactorFixture) string { return totpAt(t, f.secret, 0) },
+			expired: true,
+			wantErr: services.ErrInvalidChallenge,
+		},
+		{
+			name:    "a challenge never issued",
+			answer:  func(t *testing.T, f *twoFactorFixture) string { return totpAt(t, f.secret, 0) },
+			forged:  true,
+			wantErr: services.ErrInvalidChallenge,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			f := newTwoFactorFixture(t)
+			code := tt.answer(t, f)
+			challenge, _, err := f.svc.Challenge(ctx, f.userID)
+			if err != nil || challenge == "" {
+				t.Fatalf("Challenge = %q, %v", challenge, err)
+			}
+			if tt.expired {
+				for _, tok := range f.tokens.tokens {
+					tok.ExpiresAt = time.Now().Add(-time.Second)
+				}
+			}
+			if tt.forged {
+				challenge = "forged"
+			}
+
+			userID, err := f.svc.VerifyChallenge(ctx, challenge, code)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("VerifyChallenge error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("VerifyChallenge: %v", err)
+			}
+			if userID != f.userID {
+				t.Errorf("VerifyChallenge = user %d, want %d", userID, f.userID)
+			}
+			// a challenge logs in once
+			if _, err := f.svc.VerifyChallenge(ctx, challenge, f.recovery[1]); !errors.Is(err, services.ErrInvalidChallenge) {
+				t.Errorf("second VerifyChallenge error = %v, want ErrInvalidChallenge", err)
+			}
+		})
+	}
+}
+
+func TestTwoFactorWrongCodeKeepsChallenge(t *testing.T) {
+	ctx := context.Background()
+	f := newTwoFactorFixture(t)
+	challenge, _, err := f.svc.Challenge(ctx, f.userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.svc.VerifyChallenge(ctx, challenge, "000000"); !errors.Is(err, services.ErrInvalidTwoFactorCode) {
+		t.Fatalf("VerifyChallenge(wrong code) error = %v", err)
+	}
+	if id, err := f.svc.ChallengedUser(ctx, challenge); err != nil || id != f.userID {
+		t.Fatalf("ChallengedUser = %d, %v; want %d", id, err, f.userID)
+	}
+	if _, err := f.svc.VerifyChallenge(ctx, challenge, totpAt(t, f.secret, 0)); err != nil {
+		t.Fatalf("VerifyChallenge after a wrong code: %v", err)
+	}
+	// a fresh challenge invalidates the ones before it
+	first, _, _ := f.svc.Challenge(ctx, f.userID)
+	if _, _, err := f.svc.Challenge(ctx, f.userID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.svc.ChallengedUser(ctx, first); !errors.Is(err, services.ErrInvalidChallenge) {
+		t.Errorf("ChallengedUser(superseded) error = %v, want ErrInvalidChallenge", err)
+	}
+}
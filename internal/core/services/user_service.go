@@ -1,31 +1,84 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"richisntreal-backend/internal/core/domain/models"
 	"time"
 
-	"github.com/golang-jwt/jwt/v4"
 	"golang.org/x/crypto/bcrypt"
 )
 
 // UserService is the default implementation of UserService.
 type UserService struct {
-	userRepository UserRepository
-	jwtSecret      string
+	userRepository       UserRepository
+	tokenIssuer          TokenIssuer
+	secondFactor         SecondFactor
+	requireVerifiedEmail bool
 }
 
-// NewUserService constructs a new UserService.
-func NewUserService(userRepository UserRepository, jwtSecret string) *UserService {
-	return &UserService{userRepository: userRepository, jwtSecret: jwtSecret}
+// NewUserService constructs a new UserService. With requireVerifiedEmail
+// set, users can't log in until they have confirmed their email address.
+func NewUserService(
+	userRepository UserRepository,
+	tokenIssuer TokenIssuer,
+	secondFactor SecondFactor,
+	requireVerifiedEmail bool,
+) *UserService {
+	return &UserService{
+		userRepository:       userRepository,
+		tokenIssuer:          tokenIssuer,
+		secondFactor:         secondFactor,
+		requireVerifiedEmail: requireVerifiedEmail,
+	}
 }
 
 func (s *UserService) CreateUser(
+	ctx context.Context,
 	username, email, password, firstName, lastName, country string,
 	dateOfBirth *time.Time,
 ) (*models.User, error) {
+	ctx, span := startSpan(ctx, "UserService.CreateUser")
+	defer span.End()
+
+	return s.createUser(ctx, &models.User{
+		Username:    username,
+		Email:       email,
+		FirstName:   firstName,
+		LastName:    lastName,
+		Country:     country,
+		DateOfBirth: dateOfBirth,
+		Role:        models.RoleCustomer,
+	}, password)
+}
+
+// CreateAdmin creates an administrator account. Its email address counts
+// as verified: whoever runs this on the server vouches for it.
+func (s *UserService) CreateAdmin(ctx context.Context, username, email, password string) (*models.User, error) {
+	ctx, span := startSpan(ctx, "UserService.CreateAdmin")
+	defer span.End()
+
+	if len(password) < MinPasswordLength {
+		return nil, ErrWeakPassword
+	}
+	user, err := s.createUser(ctx, &models.User{
+		Username: username,
+		Email:    email,
+		Role:     models.RoleAdmin,
+	}, password)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.userRepository.MarkEmailVerified(ctx, user.ID); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// createUser stores user with password hashed.
+func (s *UserService) createUser(ctx context.Context, user *models.User, password string) (*models.User, error) {
 	// 1) check for duplicate email
-	exists, err := s.userRepository.ExistsByEmail(email)
+	exists, err := s.userRepository.ExistsByEmail(ctx, user.Email)
 	if err != nil {
 		return nil, err
 	}
@@ -38,22 +91,13 @@ func (s *UserService) CreateUser(
 	if err != nil {
 		return nil, err
 	}
-
 	now := time.Now()
-	user := &models.User{
-		Username:    username,
-		Email:       email,
-		Password:    string(hash),
-		FirstName:   firstName,
-		LastName:    lastName,
-		Country:     country,
-		DateOfBirth: dateOfBirth,
-		CreatedAt:   now,
-		UpdatedAt:   now,
-	}
+	user.Password = string(hash)
+	user.CreatedAt = now
+	user.UpdatedAt = now
 
 	// 3) persist
-	id, err := s.userRepository.Create(user)
+	id, err := s.userRepository.Create(ctx, user)
 	if err != nil {
 		return nil, err
 	}
@@ -65,31 +109,101 @@ func (s *UserService) CreateUser(
 	return user, nil
 }
 
-// Authenticate verifies credentials and returns a JWT on success.
-func (s *UserService) Authenticate(email, password string) (string, error) {
-	user, err := s.userRepository.FindByEmail(email)
+// Authenticate verifies credentials and starts a login; see StartLogin.
+func (s *UserService) Authenticate(ctx context.Context, email, password string) (*models.LoginResult, error) {
+	ctx, span := startSpan(ctx, "UserService.Authenticate")
+	defer span.End()
+
+	user, err := s.userRepository.FindByEmail(ctx, email)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	if user == nil {
-		return "", ErrInvalidCredentials
+		return nil, ErrInvalidCredentials
 	}
 
 	if err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
-		return "", ErrInvalidCredentials
+		return nil, ErrInvalidCredentials
+	}
+	return s.StartLogin(ctx, user)
+}
+
+// StartLogin logs in a user whose identity has been established, by
+// password or by an identity provider. Users with 2FA get a challenge
+// to complete with CompleteTwoFactorLogin instead of a session.
+func (s *UserService) StartLogin(ctx context.Context, user *models.User) (*models.LoginResult, error) {
+	ctx, span := startSpan(ctx, "UserService.StartLogin")
+	defer span.End()
+
+	if user.IsDisabled() {
+		return nil, ErrAccountDisabled
+	}
+	if s.requireVerifiedEmail && user.EmailVerifiedAt == nil {
+		return nil, ErrEmailNotVerified
+	}
+	user.Password = ""
+
+	challenge, ttl, err := s.secondFactor.Challenge(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if challenge != "" {
+		return &models.LoginResult{
+			User:               user,
+			ChallengeToken:     challenge,
+			ChallengeExpiresIn: int64(ttl.Seconds()),
+		}, nil
+	}
+
+	tokens, err := s.tokenIssuer.StartSession(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.LoginResult{User: user, Tokens: tokens}, nil
+}
+
+// CompleteTwoFactorLogin finishes a login that was answered with a challenge.
+func (s *UserService) CompleteTwoFactorLogin(ctx context.Context, challengeToken, code string) (*models.LoginResult, error) {
+	ctx, span := startSpan(ctx, "UserService.CompleteTwoFactorLogin")
+	defer span.End()
+
+	userID, err := s.secondFactor.VerifyChallenge(ctx, challengeToken, code)
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	// the account may have been disabled while the challenge was open
+	if user.IsDisabled() {
+		return nil, ErrAccountDisabled
+	}
+	tokens, err := s.tokenIssuer.StartSession(ctx, user.ID)
+	if err != nil {
+		return nil, err
 	}
+	return &models.LoginResult{User: user, Tokens: tokens}, nil
+}
 
-	claims := jwt.MapClaims{
-		"sub": user.ID,
-		"exp": time.Now().Add(72 * time.Hour).Unix(),
+// ChallengedUser returns the user an open login challenge belongs to.
+func (s *UserService) ChallengedUser(ctx context.Context, challengeToken string) (*models.User, error) {
+	ctx, span := startSpan(ctx, "UserService.ChallengedUser")
+	defer span.End()
+
+	userID, err := s.secondFactor.ChallengedUser(ctx, challengeToken)
+	if err != nil {
+		return nil, err
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(s.jwtSecret))
+	return s.GetByID(ctx, userID)
 }
 
 // GetByID looks up a user by ID (stripping out their password).
-func (s *UserService) GetByID(id int64) (*models.User, error) {
-	user, err := s.userRepository.FindByID(id)
+func (s *UserService) GetByID(ctx context.Context, id int64) (*models.User, error) {
+	ctx, span := startSpan(ctx, "UserService.GetByID")
+	defer span.End()
+
+	user, err := s.userRepository.FindByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -100,8 +214,11 @@ func (s *UserService) GetByID(id int64) (*models.User, error) {
 	return user, nil
 }
 
-func (s *UserService) GetByEmail(email string) (*models.User, error) {
-	user, err := s.userRepository.FindByEmail(email)
+func (s *UserService) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+	ctx, span := startSpan(ctx, "UserService.GetByEmail")
+	defer span.End()
+
+	user, err := s.userRepository.FindByEmail(ctx, email)
 	if err != nil {
 		return nil, err
 	}
@@ -113,14 +230,93 @@ func (s *UserService) GetByEmail(email string) (*models.User, error) {
 	return user, nil
 }
 
+// UpdateProfile applies the given profile changes and returns the user.
+func (s *UserService) UpdateProfile(ctx context.Context, id int64, upd models.ProfileUpdate) (*models.User, error) {
+	ctx, span := startSpan(ctx, "UserService.UpdateProfile")
+	defer span.End()
+
+	user, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if upd.FirstName != nil {
+		user.FirstName = *upd.FirstName
+	}
+	if upd.LastName != nil {
+		user.LastName = *upd.LastName
+	}
+	if upd.Country != nil {
+		user.Country = *upd.Country
+	}
+	if upd.DateOfBirth != nil {
+		user.DateOfBirth = upd.DateOfBirth
+	}
+	if upd.ClearDateOfBirth {
+		user.DateOfBirth = nil
+	}
+	if err := s.userRepository.UpdateProfile(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// IsAdmin reports whether the user holds the admin role.
+func (s *UserService) IsAdmin(ctx context.Context, userID int64) (bool, error) {
+	ctx, span := startSpan(ctx, "UserService.IsAdmin")
+	defer span.End()
+
+	user, err := s.userRepository.FindByID(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	if user == nil {
+		return false, ErrUserNotFound
+	}
+	return user.IsAdmin(), nil
+}
+
+// IsAccountEnabled reports whether the user may still use the API; deleted
+// and disabled accounts may not.
+func (s *UserService) IsAccountEnabled(ctx context.Context, userID int64) (bool, error) {
+	ctx, span := startSpan(ctx, "UserService.IsAccountEnabled")
+	defer span.End()
+
+	user, err := s.userRepository.FindByID(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	return user != nil && user.DeletedAt == nil && !user.IsDisabled(), nil
+}
+
 var ErrUserNotFound = errors.New("user not found")
 var ErrUserExists = errors.New("user already exists")
 var ErrInvalidCredentials = errors.New("invalid credentials")
+var ErrEmailNotVerified = errors.New("email address not verified")
+var ErrAccountDisabled = errors.New("account disabled")
+
+// TokenIssuer starts a session for an authenticated user; SessionService implements it.
+type TokenIssuer interface {
+	StartSession(ctx context.Context, userID int64) (*models.TokenPair, error)
+}
+
+// SecondFactor gates logins of accounts with 2FA; TwoFactorService implements it.
+type SecondFactor interface {
+	Challenge(ctx context.Context, userID int64) (string, time.Duration, error)
+	VerifyChallenge(ctx context.Context, challengeToken, code string) (int64, error)
+	ChallengedUser(ctx context.Context, challengeToken string) (int64, error)
+}
 
 // UserRepository defines persistence operations for users.
 type UserRepository interface {
-	ExistsByEmail(email string) (bool, error)
-	Create(user *models.User) (int64, error)
-	FindByEmail(email string) (*models.User, error)
-	FindByID(id int64) (*models.User, error)
+	ExistsByEmail(ctx context.Context, email string) (bool, error)
+	Create(ctx context.Context, user *models.User) (int64, error)
+	FindByEmail(ctx context.Context, email string) (*models.User, error)
+	FindByID(ctx context.Context, id int64) (*models.User, error)
+	UpdatePassword(ctx context.Context, id int64, passwordHash string) error
+	MarkEmailVerified(ctx context.Context, id int64) error
+	UpdateProfile(ctx context.Context, user *models.User) error
+	UpdateEmail(ctx context.Context, id int64, email string) error
+	Anonymise(ctx context.Context, id int64) error
+	SetDisabled(ctx context.Context, id int64, disabled bool) error
+	Search(ctx context.Context, filter models.UserFilter) ([]*models.User, int, error)
 }
@@ -3,6 +3,8 @@ package http
 import (
 	"net/http"
 	"sync"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 )
 
 var (
@@ -10,9 +12,13 @@ var (
 	httpclientOnce      = &sync.Once{}
 )
 
+// NewHTTPClient returns the shared client for calls to third parties. Each
+// request gets a client span and carries the trace context in W3C headers.
 func NewHTTPClient() *http.Client {
 	httpclientOnce.Do(func() {
-		httpclientSingleton = &http.Client{}
+		httpclientSingleton = &http.Client{
+			Transport: otelhttp.NewTransport(http.DefaultTransport),
+		}
 	})
 
 	return httpclientSingleton
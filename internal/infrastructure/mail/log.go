@@ -0,0 +1,20 @@
+package mail
+
+import (
+	"log/slog"
+
+	"richisntreal-backend/internal/core/domain/models"
+)
+
+// LogMailer writes mail to the application log instead of sending it.
+// Handy in development, where the links in the log can be followed by hand.
+type LogMailer struct{}
+
+func NewLogMailer() *LogMailer {
+	return &LogMailer{}
+}
+
+func (m *LogMailer) Send(msg models.EmailMessage) error {
+	slog.Info("mail", "to", msg.To, "subject", msg.Subject, "body", msg.Body)
+	return nil
+}
@@ -0,0 +1,38 @@
+package mail
+
+import (
+	"sync"
+
+	"richisntreal-backend/internal/core/domain/models"
+)
+
+// MemoryMailer keeps sent mail in memory so tests can inspect it.
+type MemoryMailer struct {
+	mu   sync.Mutex
+	sent []models.EmailMessage
+}
+
+func NewMemoryMailer() *MemoryMailer {
+	return &MemoryMailer{}
+}
+
+func (m *MemoryMailer) Send(msg models.EmailMessage) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+// Sent returns a copy of every message sent so far, oldest first.
+func (m *MemoryMailer) Sent() []models.EmailMessage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]models.EmailMessage(nil), m.sent...)
+}
+
+// Reset forgets all sent messages.
+func (m *MemoryMailer) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent = nil
+}
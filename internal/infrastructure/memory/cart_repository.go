@@ -0,0 +1,122 @@
+package memory
+
+import (
+	"context"
+
+	"richisntreal-backend/internal/core/domain/models"
+)
+
+// CartRepository keeps carts in a Store.
+type CartRepository struct {
+	s *Store
+}
+
+func NewCartRepository(s *Store) *CartRepository {
+	return &CartRepository{s: s}
+}
+
+func (r *CartRepository) FindByUserID(_ context.Context, userID int64) (*models.Cart, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, c := range rows(r.s.carts) {
+		if c.UserID != userID {
+			continue
+		}
+		cart := clone(c)
+		for _, it := range rows(r.s.cartItems) {
+			if it.CartID == cart.ID {
+				cart.Items = append(cart.Items, *it)
+			}
+		}
+		return cart, nil
+	}
+	return nil, nil
+}
+
+func (r *CartRepository) CreateCart(_ context.Context, cart *models.Cart) (int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if r.s.users[cart.UserID] == nil {
+		return 0, ErrForeignKey
+	}
+	t := now()
+	c := &models.Cart{ID: r.s.nextID("carts"), UserID: cart.UserID, CreatedAt: t, UpdatedAt: t}
+	r.s.carts[c.ID] = c
+	return c.ID, nil
+}
+
+func (r *CartRepository) FindItem(_ context.Context, itemID int64) (*models.CartItem, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if it := r.s.cartItems[itemID]; it != nil {
+		return clone(it), nil
+	}
+	return nil, nil
+}
+
+func (r *CartRepository) FindItemByCartAndProduct(_ context.Context, cartID, productID int64) (*models.CartItem, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, it := range rows(r.s.cartItems) {
+		if it.CartID == cartID && it.ProductID == productID {
+			return clone(it), nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *CartRepository) CreateItem(_ context.Context, item *models.CartItem) (int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if r.s.carts[item.CartID] == nil {
+		return 0, ErrForeignKey
+	}
+	t := now()
+	it := &models.CartItem{
+		ID:        r.s.nextID("cart_items"),
+		CartID:    item.CartID,
+		ProductID: item.ProductID,
+		Quantity:  item.Quantity,
+		UnitPrice: item.UnitPrice,
+		CreatedAt: t,
+		UpdatedAt: t,
+	}
+	r.s.cartItems[it.ID] = it
+	return it.ID, nil
+}
+
+func (r *CartRepository) UpdateItem(_ context.Context, item *models.CartItem) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if it := r.s.cartItems[item.ID]; it != nil {
+		it.Quantity = item.Quantity
+		it.UpdatedAt = now()
+	}
+	return nil
+}
+
+func (r *CartRepository) DeleteItem(_ context.Context, itemID int64) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	delete(r.s.cartItems, itemID)
+	return nil
+}
+
+func (r *CartRepository) DeleteItemsByCartID(_ context.Context, cartID int64) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for id, it := range r.s.cartItems {
+		if it.CartID == cartID {
+			delete(r.s.cartItems, id)
+		}
+	}
+	return nil
+}
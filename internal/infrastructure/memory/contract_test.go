@@ -0,0 +1,23 @@
+package memory_test
+
+import (
+	"context"
+	"testing"
+
+	"richisntreal-backend/internal/infrastructure/memory"
+	"richisntreal-backend/internal/infrastructure/repotest"
+)
+
+func TestRepositoryContract(t *testing.T) {
+	store := memory.NewStore()
+	err := repotest.Check(context.Background(), repotest.Repositories{
+		Users:    memory.NewUserRepository(store),
+		Carts:    memory.NewCartRepository(store),
+		Products: memory.NewProductRepository(store),
+		Orders:   memory.NewOrderRepository(store),
+		Payments: memory.NewPaymentRepository(store),
+	})
+	if err != nil {
+		t.Error(err)
+	}
+}
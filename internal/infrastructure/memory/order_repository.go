@@ -0,0 +1,284 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"richisntreal-backend/internal/core/domain/models"
+)
+
+// OrderRepository keeps orders, their lines, addresses and notes in a Store.
+type OrderRepository struct {
+	s *Store
+}
+
+func NewOrderRepository(s *Store) *OrderRepository {
+	return &OrderRepository{s: s}
+}
+
+func (r *OrderRepository) CreateOrder(_ context.Context, o *models.Order) (int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if r.s.users[o.UserID] == nil {
+		return 0, ErrForeignKey
+	}
+	for _, ord := range r.s.orders {
+		if ord.Reference == o.Reference {
+			return 0, ErrDuplicate
+		}
+	}
+	t := now()
+	row := &models.Order{
+		ID:        r.s.nextID("orders"),
+		Reference: o.Reference,
+		UserID:    o.UserID,
+		Total:     o.Total,
+		Status:    o.Status,
+		CreatedAt: t,
+		UpdatedAt: t,
+	}
+	r.s.orders[row.ID] = row
+	return row.ID, nil
+}
+
+func (r *OrderRepository) CreateOrderItem(_ context.Context, item *models.OrderItem) (int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if r.s.orders[item.OrderID] == nil || r.s.products[item.ProductID] == nil {
+		return 0, ErrForeignKey
+	}
+	t := now()
+	row := &models.OrderItem{
+		ID:        r.s.nextID("order_items"),
+		OrderID:   item.OrderID,
+		ProductID: item.ProductID,
+		Quantity:  item.Quantity,
+		UnitPrice: item.UnitPrice,
+		CreatedAt: t,
+		UpdatedAt: t,
+	}
+	r.s.orderItems[row.ID] = row
+	return row.ID, nil
+}
+
+func (r *OrderRepository) CreateAddress(_ context.Context, addr *models.Address) (int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if r.s.orders[addr.OrderID] == nil {
+		return 0, ErrForeignKey
+	}
+	if r.findAddress(addr.OrderID, addr.Kind) != nil {
+		return 0, ErrDuplicate
+	}
+	t := now()
+	row := clone(addr)
+	row.ID = r.s.nextID("order_addresses")
+	row.CreatedAt = t
+	row.UpdatedAt = t
+	r.s.addresses[row.ID] = row
+	return row.ID, nil
+}
+
+func (r *OrderRepository) FindOrdersByUser(_ context.Context, userID int64) ([]*models.Order, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var orders []*models.Order
+	for _, ord := range rows(r.s.orders) {
+		if ord.UserID == userID {
+			orders = append(orders, r.load(ord))
+		}
+	}
+	return orders, nil
+}
+
+func (r *OrderRepository) FindOrderByID(_ context.Context, orderID int64) (*models.Order, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if ord := r.s.orders[orderID]; ord != nil {
+		return r.load(ord), nil
+	}
+	return nil, nil
+}
+
+// FindOrderByReference looks an order up by its public reference.
+func (r *OrderRepository) FindOrderByReference(_ context.Context, reference string) (*models.Order, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, ord := range r.s.orders {
+		if ord.Reference == reference {
+			return r.load(ord), nil
+		}
+	}
+	return nil, nil
+}
+
+// FindOrderByReferenceAndEmail only finds the order when the email matches
+// the one of the customer who placed it.
+func (r *OrderRepository) FindOrderByReferenceAndEmail(_ context.Context, reference, email string) (*models.Order, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, ord := range r.s.orders {
+		if ord.Reference != reference {
+			continue
+		}
+		if u := r.s.users[ord.UserID]; u != nil && strings.EqualFold(u.Email, email) {
+			return r.load(ord), nil
+		}
+	}
+	return nil, nil
+}
+
+// SearchOrders returns one page of orders matching the filter, newest
+// first, together with the total number of matches.
+func (r *OrderRepository) SearchOrders(_ context.Context, f models.OrderFilter) ([]*models.Order, int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var matches []*models.Order
+	for _, ord := range r.s.orders {
+		if f.Status != "" && ord.Status != f.Status ||
+			f.UserID != 0 && ord.UserID != f.UserID ||
+			f.From != nil && ord.CreatedAt.Before(*f.From) ||
+			f.To != nil && !ord.CreatedAt.Before(*f.To) ||
+			f.MinTotal != nil && ord.Total < *f.MinTotal ||
+			f.MaxTotal != nil && ord.Total > *f.MaxTotal {
+			continue
+		}
+		matches = append(matches, ord)
+	}
+	slices.SortFunc(matches, func(a, b *models.Order) int {
+		if c := b.CreatedAt.Compare(a.CreatedAt); c != 0 {
+			return c
+		}
+		return cmp.Compare(b.ID, a.ID)
+	})
+
+	total := len(matches)
+	if f.SkipCount {
+		total = 0
+	}
+	if f.Limit > 0 {
+		matches = page(matches, f.Limit, f.Offset)
+	}
+	var orders []*models.Order
+	for _, ord := range matches {
+		orders = append(orders, r.load(ord))
+	}
+	return orders, total, nil
+}
+
+func (r *OrderRepository) CreateNote(_ context.Context, note *models.OrderNote) (int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if r.s.orders[note.OrderID] == nil || r.s.users[note.AuthorID] == nil {
+		return 0, ErrForeignKey
+	}
+	row := &models.OrderNote{
+		ID:        r.s.nextID("order_notes"),
+		OrderID:   note.OrderID,
+		AuthorID:  note.AuthorID,
+		Body:      note.Body,
+		CreatedAt: now(),
+	}
+	r.s.notes[row.ID] = row
+	return row.ID, nil
+}
+
+func (r *OrderRepository) FindNotes(_ context.Context, orderID int64) ([]*models.OrderNote, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	notes := []*models.OrderNote{}
+	for _, n := range rows(r.s.notes) {
+		if n.OrderID == orderID {
+			notes = append(notes, clone(n))
+		}
+	}
+	return notes, nil
+}
+
+// UpdateStatus moves an order from one status to another. It reports false
+// when the order was not in the expected status.
+func (r *OrderRepository) UpdateStatus(_ context.Context, orderID int64, from, to string) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	ord := r.s.orders[orderID]
+	if ord == nil || ord.Status != from {
+		return false, nil
+	}
+	ord.Status = to
+	ord.UpdatedAt = now()
+	return true, nil
+}
+
+// CancelOrder marks an unfulfilled order as cancelled. It reports false when
+// the order was already past the point of cancellation.
+func (r *OrderRepository) CancelOrder(_ context.Context, orderID int64, reason string) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	ord := r.s.orders[orderID]
+	if ord == nil || !ord.Cancellable() {
+		return false, nil
+	}
+	t := now()
+	ord.Status = models.OrderStatusCancelled
+	ord.CancellationReason = &reason
+	ord.CancelledAt = &t
+	ord.UpdatedAt = t
+	return true, nil
+}
+
+// load copies an order together with its lines and billing address.
+func (r *OrderRepository) load(ord *models.Order) *models.Order {
+	o := clone(ord)
+	o.CancellationReason = copyString(ord.CancellationReason)
+	o.CancelledAt = copyTime(ord.CancelledAt)
+	o.Items = nil
+	for _, it := range rows(r.s.orderItems) {
+		if it.OrderID == ord.ID {
+			o.Items = append(o.Items, *it)
+		}
+	}
+	if addr := r.findAddress(ord.ID, models.AddressKindBilling); addr != nil {
+		o.BillingAddress = clone(addr)
+	}
+	return o
+}
+
+func (r *OrderRepository) findAddress(orderID int64, kind string) *models.Address {
+	for _, addr := range r.s.addresses {
+		if addr.OrderID == orderID && addr.Kind == kind {
+			return addr
+		}
+	}
+	return nil
+}
+
+// page applies LIMIT and OFFSET.
+func page[T any](s []T, limit, offset int) []T {
+	if offset >= len(s) {
+		return nil
+	}
+	return s[offset:min(offset+limit, len(s))]
+}
+
+func copyTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	v := *t
+	return &v
+}
@@ -0,0 +1,142 @@
+package memory
+
+import (
+	"context"
+	"math"
+
+	"richisntreal-backend/internal/core/domain/models"
+)
+
+// PaymentRepository keeps payment transactions in a Store.
+type PaymentRepository struct {
+	s *Store
+}
+
+func NewPaymentRepository(s *Store) *PaymentRepository {
+	return &PaymentRepository{s: s}
+}
+
+func (r *PaymentRepository) Create(_ context.Context, tx *models.PaymentTransaction) (int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if r.s.orders[tx.OrderID] == nil {
+		return 0, ErrForeignKey
+	}
+	t := now()
+	row := &models.PaymentTransaction{
+		ID:        r.s.nextID("payment_transactions"),
+		OrderID:   tx.OrderID,
+		Amount:    tx.Amount,
+		Currency:  tx.Currency,
+		Provider:  tx.Provider,
+		Token:     tx.Token,
+		Status:    tx.Status,
+		CreatedAt: t,
+		UpdatedAt: t,
+	}
+	r.s.payments[row.ID] = row
+	return row.ID, nil
+}
+
+func (r *PaymentRepository) UpdateStatus(_ context.Context, id int64, status string, failureMessage *string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if row := r.s.payments[id]; row != nil {
+		row.Status = status
+		row.FailureMessage = copyString(failureMessage)
+		row.UpdatedAt = now()
+	}
+	return nil
+}
+
+func (r *PaymentRepository) UpdateProviderTxID(_ context.Context, id int64, providerTxID string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if row := r.s.payments[id]; row != nil {
+		row.ProviderTxID = &providerTxID
+		row.UpdatedAt = now()
+	}
+	return nil
+}
+
+// AddRefund records that amount more of a charge was given back, marking
+// it refunded once nothing is left.
+func (r *PaymentRepository) AddRefund(_ context.Context, id int64, amount float64) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if row := r.s.payments[id]; row != nil {
+		row.Status = models.PaymentStatusPartiallyRefunded
+		if cents(row.RefundedAmount+amount) >= cents(row.Amount) {
+			row.Status = models.PaymentStatusRefunded
+		}
+		row.RefundedAmount = cents(row.RefundedAmount+amount) / 100
+		row.UpdatedAt = now()
+	}
+	return nil
+}
+
+// cents rounds an amount to whole cents the way a DECIMAL(10,2) column does.
+func cents(amount float64) float64 {
+	return math.Round(amount * 100)
+}
+
+// FindByOrder returns the most recent transaction for an order, or nil if
+// the order has never been paid for.
+func (r *PaymentRepository) FindByOrder(_ context.Context, orderID int64) (*models.PaymentTransaction, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var latest *models.PaymentTransaction
+	for _, row := range rows(r.s.payments) {
+		if row.OrderID == orderID {
+			latest = row
+		}
+	}
+	if latest == nil {
+		return nil, nil
+	}
+	return clonePayment(latest), nil
+}
+
+// FindUnsettledCancellations returns the latest transaction of every
+// cancelled order that was not voided or refunded.
+func (r *PaymentRepository) FindUnsettledCancellations(_ context.Context) ([]*models.PaymentTransaction, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	latest := make(map[int64]*models.PaymentTransaction)
+	for _, row := range rows(r.s.payments) {
+		latest[row.OrderID] = row
+	}
+	var txs []*models.PaymentTransaction
+	for _, row := range rows(r.s.payments) {
+		ord := r.s.orders[row.OrderID]
+		if latest[row.OrderID] != row || ord == nil || ord.Status != models.OrderStatusCancelled {
+			continue
+		}
+		if row.Status == models.PaymentStatusPending || row.Status == models.PaymentStatusSucceeded {
+			txs = append(txs, clonePayment(row))
+		}
+	}
+	return txs, nil
+}
+
+// clonePayment copies a transaction including its optional fields.
+func clonePayment(row *models.PaymentTransaction) *models.PaymentTransaction {
+	tx := clone(row)
+	tx.ProviderTxID = copyString(row.ProviderTxID)
+	tx.FailureMessage = copyString(row.FailureMessage)
+	return tx
+}
+
+func copyString(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	v := *s
+	return &v
+}
@@ -0,0 +1,151 @@
+package memory
+
+import (
+	"context"
+
+	"richisntreal-backend/internal/core/domain/models"
+)
+
+// ProductRepository keeps the catalog in a Store.
+type ProductRepository struct {
+	s *Store
+}
+
+func NewProductRepository(s *Store) *ProductRepository {
+	return &ProductRepository{s: s}
+}
+
+func (r *ProductRepository) FindAll(_ context.Context) ([]*models.Product, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var prods []*models.Product
+	for _, p := range rows(r.s.products) {
+		prods = append(prods, cloneProduct(p))
+	}
+	return prods, nil
+}
+
+func (r *ProductRepository) FindByID(_ context.Context, id int64) (*models.Product, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if p := r.s.products[id]; p != nil {
+		return cloneProduct(p), nil
+	}
+	return nil, nil
+}
+
+func (r *ProductRepository) Create(_ context.Context, p *models.Product) (int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if r.skuTaken(p.SKU, 0) {
+		return 0, ErrDuplicate
+	}
+	t := now()
+	row := &models.Product{
+		ID:          r.s.nextID("products"),
+		Name:        p.Name,
+		Description: p.Description,
+		Price:       p.Price,
+		SKU:         p.SKU,
+		Stock:       copyInt(p.Stock),
+		CreatedAt:   t,
+		UpdatedAt:   t,
+	}
+	r.s.products[row.ID] = row
+	return row.ID, nil
+}
+
+func (r *ProductRepository) Update(_ context.Context, p *models.Product) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	row := r.s.products[p.ID]
+	if row == nil {
+		return nil
+	}
+	if r.skuTaken(p.SKU, p.ID) {
+		return ErrDuplicate
+	}
+	row.Name = p.Name
+	row.Description = p.Description
+	row.Price = p.Price
+	row.SKU = p.SKU
+	row.Stock = copyInt(p.Stock)
+	row.UpdatedAt = now()
+	return nil
+}
+
+func (r *ProductRepository) Delete(_ context.Context, id int64) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, it := range r.s.orderItems {
+		if it.ProductID == id {
+			return ErrForeignKey
+		}
+	}
+	delete(r.s.products, id)
+	return nil
+}
+
+// ReserveStock takes qty units out of stock. It reports false when the
+// product does not exist or does not have enough units left; products
+// without tracked stock always succeed.
+func (r *ProductRepository) ReserveStock(_ context.Context, productID int64, qty int) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	p := r.s.products[productID]
+	switch {
+	case p == nil:
+		return false, nil
+	case p.Stock == nil:
+		return true, nil
+	case *p.Stock < qty:
+		return false, nil
+	}
+	*p.Stock -= qty
+	p.UpdatedAt = now()
+	return true, nil
+}
+
+// ReleaseStock puts qty previously reserved units back into stock.
+func (r *ProductRepository) ReleaseStock(_ context.Context, productID int64, qty int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if p := r.s.products[productID]; p != nil && p.Stock != nil {
+		*p.Stock += qty
+		p.UpdatedAt = now()
+	}
+	return nil
+}
+
+// skuTaken reports whether another product already uses sku. Like the
+// UNIQUE index, it counts the empty string as a value.
+func (r *ProductRepository) skuTaken(sku string, self int64) bool {
+	for _, p := range r.s.products {
+		if p.SKU == sku && p.ID != self {
+			return true
+		}
+	}
+	return false
+}
+
+// cloneProduct copies a product including its stock counter.
+func cloneProduct(p *models.Product) *models.Product {
+	c := clone(p)
+	c.Stock = copyInt(p.Stock)
+	return c
+}
+
+func copyInt(n *int) *int {
+	if n == nil {
+		return nil
+	}
+	v := *n
+	return &v
+}
@@ -0,0 +1,79 @@
+package memory
+
+import (
+	"errors"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"richisntreal-backend/internal/core/domain/models"
+)
+
+// Errors standing in for the constraint violations MySQL reports.
+var (
+	ErrDuplicate  = errors.New("memory: duplicate entry")
+	ErrForeignKey = errors.New("memory: foreign key constraint fails")
+)
+
+// Store holds the tables the in-memory repositories share, so they can
+// check each other's foreign keys and join like the MySQL ones do. It is
+// safe for concurrent use; every repository call takes the lock once, so
+// calls are atomic the way single statements are.
+type Store struct {
+	mu  sync.Mutex
+	seq map[string]int64 // AUTO_INCREMENT per table
+
+	users      map[int64]*models.User
+	carts      map[int64]*models.Cart
+	cartItems  map[int64]*models.CartItem
+	products   map[int64]*models.Product
+	orders     map[int64]*models.Order
+	orderItems map[int64]*models.OrderItem
+	addresses  map[int64]*models.Address
+	notes      map[int64]*models.OrderNote
+	payments   map[int64]*models.PaymentTransaction
+}
+
+// NewStore returns an empty store.
+func NewStore() *Store {
+	return &Store{
+		seq:        make(map[string]int64),
+		users:      make(map[int64]*models.User),
+		carts:      make(map[int64]*models.Cart),
+		cartItems:  make(map[int64]*models.CartItem),
+		products:   make(map[int64]*models.Product),
+		orders:     make(map[int64]*models.Order),
+		orderItems: make(map[int64]*models.OrderItem),
+		addresses:  make(map[int64]*models.Address),
+		notes:      make(map[int64]*models.OrderNote),
+		payments:   make(map[int64]*models.PaymentTransaction),
+	}
+}
+
+func (s *Store) nextID(table string) int64 {
+	s.seq[table]++
+	return s.seq[table]
+}
+
+// now is NOW(): MySQL TIMESTAMP columns keep whole seconds.
+func now() time.Time {
+	return time.Now().Truncate(time.Second)
+}
+
+// rows returns a table's rows in primary key order, the order InnoDB hands
+// them back in when a query has no ORDER BY.
+func rows[T any](table map[int64]*T) []*T {
+	out := make([]*T, 0, len(table))
+	for _, id := range slices.Sorted(maps.Keys(table)) {
+		out = append(out, table[id])
+	}
+	return out
+}
+
+// clone copies a row so callers can't change the store behind its back,
+// just as a scanned row is the caller's own.
+func clone[T any](row *T) *T {
+	c := *row
+	return &c
+}
@@ -0,0 +1,264 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"richisntreal-backend/internal/core/domain/models"
+)
+
+// UserRepository keeps users in a Store. Emails compare case-insensitively,
+// as they do under MySQL's default collation.
+type UserRepository struct {
+	s *Store
+}
+
+func NewUserRepository(s *Store) *UserRepository {
+	return &UserRepository{s: s}
+}
+
+func (r *UserRepository) ExistsByEmail(_ context.Context, email string) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	return r.findByEmail(email) != nil, nil
+}
+
+func (r *UserRepository) Create(_ context.Context, user *models.User) (int64, error) {
+	now := time.Now()
+	user.CreatedAt = now
+	user.UpdatedAt = now
+
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if r.findByEmail(user.Email) != nil {
+		return 0, fmt.Errorf("UserRepository.Create: %w", ErrDuplicate)
+	}
+	row := cloneUser(user)
+	row.ID = r.s.nextID("users")
+	row.EmailVerifiedAt = nil
+	row.DisabledAt = nil
+	row.DeletedAt = nil
+	row.CreatedAt = now.Truncate(time.Second)
+	row.UpdatedAt = row.CreatedAt
+	r.s.users[row.ID] = row
+	return row.ID, nil
+}
+
+func (r *UserRepository) FindByEmail(_ context.Context, email string) (*models.User, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if u := r.findByEmail(email); u != nil {
+		return cloneUser(u), nil
+	}
+	return nil, nil
+}
+
+func (r *UserRepository) FindByID(_ context.Context, id int64) (*models.User, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if u := r.s.users[id]; u != nil {
+		return cloneUser(u), nil
+	}
+	return nil, nil
+}
+
+func (r *UserRepository) UpdatePassword(_ context.Context, id int64, passwordHash string) error {
+	r.update(id, func(u *models.User, _ time.Time) {
+		u.Password = passwordHash
+	})
+	return nil
+}
+
+func (r *UserRepository) MarkEmailVerified(_ context.Context, id int64) error {
+	r.update(id, func(u *models.User, t time.Time) {
+		if u.EmailVerifiedAt == nil {
+			u.EmailVerifiedAt = &t
+		}
+	})
+	return nil
+}
+
+func (r *UserRepository) UpdateProfile(_ context.Context, user *models.User) error {
+	r.update(user.ID, func(u *models.User, _ time.Time) {
+		u.FirstName = user.FirstName
+		u.LastName = user.LastName
+		u.Country = user.Country
+		u.DateOfBirth = copyTime(user.DateOfBirth)
+	})
+	return nil
+}
+
+// UpdateEmail switches a user to an address they have just verified.
+func (r *UserRepository) UpdateEmail(_ context.Context, id int64, email string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	u := r.s.users[id]
+	if u == nil {
+		return nil
+	}
+	if other := r.findByEmail(email); other != nil && other.ID != id {
+		return fmt.Errorf("UserRepository.UpdateEmail: %w", ErrDuplicate)
+	}
+	t := now()
+	u.Email = email
+	u.EmailVerifiedAt = &t
+	u.UpdatedAt = t
+	return nil
+}
+
+// SetDisabled disables or re-enables an account.
+func (r *UserRepository) SetDisabled(_ context.Context, id int64, disabled bool) error {
+	r.update(id, func(u *models.User, t time.Time) {
+		switch {
+		case !disabled:
+			u.DisabledAt = nil
+		case u.DisabledAt == nil:
+			u.DisabledAt = &t
+		}
+	})
+	return nil
+}
+
+// Search pages through users matching the filter, newest first, and
+// reports how many match in total. Deleted accounts are left out.
+func (r *UserRepository) Search(_ context.Context, f models.UserFilter) ([]*models.User, int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	q := strings.ToLower(f.Query)
+	var matches []*models.User
+	for _, u := range r.s.users {
+		if u.DeletedAt != nil ||
+			f.Role != "" && u.Role != f.Role ||
+			f.Disabled != nil && u.IsDisabled() != *f.Disabled {
+			continue
+		}
+		if q != "" &&
+			!strings.Contains(strings.ToLower(u.Email), q) &&
+			!strings.Contains(strings.ToLower(u.Username), q) &&
+			!strings.Contains(strings.ToLower(u.FirstName+" "+u.LastName), q) {
+			continue
+		}
+		matches = append(matches, u)
+	}
+	slices.SortFunc(matches, func(a, b *models.User) int {
+		if c := b.CreatedAt.Compare(a.CreatedAt); c != 0 {
+			return c
+		}
+		return cmp.Compare(b.ID, a.ID)
+	})
+
+	total := len(matches)
+	if f.Limit > 0 {
+		matches = page(matches, f.Limit, f.Offset)
+	}
+	var users []*models.User
+	for _, u := range matches {
+		users = append(users, cloneUser(u))
+	}
+	return users, total, nil
+}
+
+// Anonymise deletes an account without deleting its row, scrubbing the
+// tables this store holds the way the MySQL PII registry does: the cart
+// goes, orders and payments are kept for accounting with their free text
+// and tokens erased, and the profile is blanked.
+func (r *UserRepository) Anonymise(_ context.Context, id int64) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	u := r.s.users[id]
+	if u == nil {
+		return nil
+	}
+	t := now()
+
+	owned := make(map[int64]bool)
+	for _, ord := range r.s.orders {
+		if ord.UserID == id {
+			owned[ord.ID] = true
+			ord.CancellationReason = nil
+		}
+	}
+	for _, addr := range r.s.addresses {
+		if owned[addr.OrderID] {
+			addr.Name = "Erased customer"
+			addr.Line1 = ""
+			addr.Line2 = ""
+		}
+	}
+	for cartID, c := range r.s.carts {
+		if c.UserID != id {
+			continue
+		}
+		for itemID, it := range r.s.cartItems {
+			if it.CartID == cartID {
+				delete(r.s.cartItems, itemID)
+			}
+		}
+		delete(r.s.carts, cartID)
+	}
+	for _, p := range r.s.payments {
+		if owned[p.OrderID] {
+			p.Token = "****" + p.Token[max(len(p.Token)-4, 0):]
+			p.FailureMessage = nil
+		}
+	}
+	for _, n := range r.s.notes {
+		if owned[n.OrderID] {
+			n.Body = "[erased]"
+		}
+	}
+
+	u.Username = "deleted user"
+	u.Email = fmt.Sprintf("deleted-%d@invalid", u.ID)
+	u.Password = "!"
+	u.FirstName = ""
+	u.LastName = ""
+	u.Country = ""
+	u.DateOfBirth = nil
+	u.EmailVerifiedAt = nil
+	u.DeletedAt = &t
+	u.UpdatedAt = t
+	return nil
+}
+
+// update applies fn to a user's row, if there is one, and stamps it.
+func (r *UserRepository) update(id int64, fn func(u *models.User, now time.Time)) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if u := r.s.users[id]; u != nil {
+		t := now()
+		fn(u, t)
+		u.UpdatedAt = t
+	}
+}
+
+func (r *UserRepository) findByEmail(email string) *models.User {
+	for _, u := range rows(r.s.users) {
+		if strings.EqualFold(u.Email, email) {
+			return u
+		}
+	}
+	return nil
+}
+
+// cloneUser copies a user including its timestamps.
+func cloneUser(u *models.User) *models.User {
+	c := clone(u)
+	c.DateOfBirth = copyTime(u.DateOfBirth)
+	c.EmailVerifiedAt = copyTime(u.EmailVerifiedAt)
+	c.DisabledAt = copyTime(u.DisabledAt)
+	c.DeletedAt = copyTime(u.DeletedAt)
+	return c
+}
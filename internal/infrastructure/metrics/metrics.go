@@ -0,0 +1,108 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "richisntreal"
+
+// Metrics holds the application's Prometheus collectors on a registry of
+// its own, so nothing registered by libraries on the global one leaks in.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpRequests *prometheus.CounterVec
+	httpDuration *prometheus.HistogramVec
+
+	ordersCreated prometheus.Counter
+	payments      *prometheus.CounterVec
+	cartsCreated  prometheus.Counter
+}
+
+// New registers the HTTP, business, runtime and, when db is not nil,
+// connection pool collectors.
+func New(db *sql.DB) *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "HTTP requests by method, route pattern and status code.",
+		}, []string{"method", "route", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP request latency by method and route pattern.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route"}),
+		ordersCreated: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "orders_created_total",
+			Help:      "Orders placed.",
+		}),
+		payments: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "payments_total",
+			Help:      "Payment attempts by provider and outcome (succeeded or failed).",
+		}, []string{"provider", "outcome"}),
+		cartsCreated: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "carts_created_total",
+			Help:      "Shopping carts created.",
+		}),
+	}
+
+	m.registry.MustRegister(
+		m.httpRequests,
+		m.httpDuration,
+		m.ordersCreated,
+		m.payments,
+		m.cartsCreated,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	if db != nil {
+		// pool stats are read from db.Stats() at scrape time, nothing runs in between
+		m.registry.MustRegister(collectors.NewDBStatsCollector(db, "mysql"))
+	}
+	return m
+}
+
+// Handler serves the registry in the Prometheus exposition format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// ObserveHTTP counts a finished request. route is the chi route pattern,
+// not the raw path, to keep the number of series bounded.
+func (m *Metrics) ObserveHTTP(method, route string, status int, d time.Duration) {
+	m.httpRequests.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+	m.httpDuration.WithLabelValues(method, route).Observe(d.Seconds())
+}
+
+// OrderCreated counts a placed order.
+func (m *Metrics) OrderCreated() {
+	m.ordersCreated.Inc()
+}
+
+// PaymentSucceeded counts a successful charge.
+func (m *Metrics) PaymentSucceeded(provider string) {
+	m.payments.WithLabelValues(provider, "succeeded").Inc()
+}
+
+// PaymentFailed counts a charge the provider refused or could not process.
+func (m *Metrics) PaymentFailed(provider string) {
+	m.payments.WithLabelValues(provider, "failed").Inc()
+}
+
+// CartCreated counts a new shopping cart.
+func (m *Metrics) CartCreated() {
+	m.cartsCreated.Inc()
+}
@@ -0,0 +1,111 @@
+package metrics_test
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	_ "github.com/go-sql-driver/mysql"
+
+	"richisntreal-backend/internal/api/middleware"
+	"richisntreal-backend/internal/api/routes"
+	"richisntreal-backend/internal/core/domain/models"
+	"richisntreal-backend/internal/core/services"
+	"richisntreal-backend/internal/infrastructure/memory"
+	"richisntreal-backend/internal/infrastructure/metrics"
+)
+
+func TestScrape(t *testing.T) {
+	// the pool collector only reads db.Stats(), so the database need not exist
+	db, err := sql.Open("mysql", "user:pass@tcp(127.0.0.1:1)/none")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	m := metrics.New(db)
+
+	r := chi.NewRouter()
+	r.Use(middleware.Metrics(m))
+	r.Get("/products/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+	routes.RegisterMetricsRoutes(r, m.Handler())
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	// 1) traffic: a routed request and one no route matches
+	for _, path := range []string{"/products/42", "/products/43", "/nope"} {
+		resp, err := http.Get(srv.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+
+	// 2) business events, through the services that report them
+	ctx := context.Background()
+	store := memory.NewStore()
+	users := memory.NewUserRepository(store)
+	products := memory.NewProductRepository(store)
+	carts := services.NewCartService(memory.NewCartRepository(store), m)
+	orders := services.NewOrderService(memory.NewOrderRepository(store), memory.NewCartRepository(store), products, nil, m)
+
+	userID, err := users.Create(ctx, &models.User{Username: "scrape", Email: "scrape@example.com", Role: models.RoleCustomer})
+	if err != nil {
+		t.Fatal(err)
+	}
+	productID, err := products.Create(ctx, &models.Product{Name: "Mug", Price: 9.5, SKU: "MUG-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := carts.AddItem(ctx, userID, productID, 2, 9.5); err != nil {
+		t.Fatalf("AddItem: %v", err)
+	}
+	if _, err := orders.CreateOrder(ctx, userID, nil); err != nil {
+		t.Fatalf("CreateOrder: %v", err)
+	}
+	m.PaymentSucceeded("stripe")
+	m.PaymentFailed("stripe")
+	m.PaymentFailed("stripe")
+
+	// 3) scrape
+	resp, err := http.Get(srv.URL + "/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET /metrics = %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := string(body)
+
+	for _, want := range []string{
+		// HTTP, labelled by route pattern rather than path
+		`richisntreal_http_requests_total{method="GET",route="/products/{id}",status="418"} 2`,
+		`richisntreal_http_requests_total{method="GET",route="unmatched",status="404"} 1`,
+		`richisntreal_http_request_duration_seconds_count{method="GET",route="/products/{id}"} 2`,
+		// DB pool
+		`go_sql_max_open_connections{db_name="mysql"}`,
+		`go_sql_open_connections{db_name="mysql"}`,
+		// business
+		`richisntreal_carts_created_total 1`,
+		`richisntreal_orders_created_total 1`,
+		`richisntreal_payments_total{outcome="succeeded",provider="stripe"} 1`,
+		`richisntreal_payments_total{outcome="failed",provider="stripe"} 2`,
+		// runtime
+		`go_goroutines`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("scrape is missing %s", want)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package mysql
+
+import (
+	"context"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"richisntreal-backend/internal/core/domain/models"
+)
+
+// AdminAuditRepository implements persistence for the admin audit log.
+type AdminAuditRepository struct {
+	db *sqlx.DB
+}
+
+func NewAdminAuditRepository(db *sqlx.DB) *AdminAuditRepository {
+	return &AdminAuditRepository{db: db}
+}
+
+func (r *AdminAuditRepository) Record(ctx context.Context, e *models.AuditEntry) error {
+	_, err := r.db.ExecContext(ctx, `
+        INSERT INTO admin_audit_log (admin_id, user_id, action, detail, created_at)
+        VALUES (?, ?, ?, ?, NOW())
+    `, e.AdminID, e.UserID, e.Action, e.Detail)
+	return err
+}
+
+// Search pages through audit entries, newest first, and reports how many
+// match in total.
+func (r *AdminAuditRepository) Search(ctx context.Context, f models.AuditFilter) ([]*models.AuditEntry, int, error) {
+	var where []string
+	var args []interface{}
+	if f.AdminID != 0 {
+		where = append(where, "admin_id = ?")
+		args = append(args, f.AdminID)
+	}
+	if f.UserID != 0 {
+		where = append(where, "user_id = ?")
+		args = append(args, f.UserID)
+	}
+	cond := ""
+	if len(where) > 0 {
+		cond = "WHERE " + strings.Join(where, " AND ")
+	}
+
+	var total int
+	if err := r.db.GetContext(ctx, &total, `SELECT COUNT(1) FROM admin_audit_log `+cond, args...); err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+        SELECT id, admin_id, user_id, action, detail, created_at
+        FROM admin_audit_log ` + cond + `
+        ORDER BY created_at DESC, id DESC`
+	if f.Limit > 0 {
+		query += ` LIMIT ? OFFSET ?`
+		args = append(args, f.Limit, f.Offset)
+	}
+	var entries []*models.AuditEntry
+	if err := r.db.SelectContext(ctx, &entries, query, args...); err != nil {
+		return nil, 0, err
+	}
+	return entries, total, nil
+}
@@ -0,0 +1,106 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"richisntreal-backend/internal/core/domain/models"
+)
+
+// APIKeyRepository implements persistence for API keys.
+type APIKeyRepository struct {
+	db *sqlx.DB
+}
+
+func NewAPIKeyRepository(db *sqlx.DB) *APIKeyRepository {
+	return &APIKeyRepository{db: db}
+}
+
+// apiKeyRow carries the scopes column, which APIKey exposes as a slice.
+type apiKeyRow struct {
+	models.APIKey
+	ScopeList string `db:"scopes"`
+}
+
+func (row *apiKeyRow) key() *models.APIKey {
+	k := row.APIKey
+	k.Scopes = strings.Fields(row.ScopeList)
+	return &k
+}
+
+const apiKeyColumns = `id, user_id, name, prefix, key_hash, scopes, expires_at, last_used_at, revoked_at, created_at`
+
+func (r *APIKeyRepository) Create(ctx context.Context, k *models.APIKey) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `
+    INSERT INTO api_keys (user_id, name, prefix, key_hash, scopes, expires_at, created_at)
+    VALUES (?, ?, ?, ?, ?, ?, NOW())`,
+		k.UserID, k.Name, k.Prefix, k.KeyHash, strings.Join(k.Scopes, " "), k.ExpiresAt)
+	if err != nil {
+		return 0, fmt.Errorf("APIKeyRepository.Create: %w", err)
+	}
+	return res.LastInsertId()
+}
+
+func (r *APIKeyRepository) FindByID(ctx context.Context, id int64) (*models.APIKey, error) {
+	var row apiKeyRow
+	err := r.db.GetContext(ctx, &row, `SELECT `+apiKeyColumns+` FROM api_keys WHERE id = ?`, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("APIKeyRepository.FindByID: %w", err)
+	}
+	return row.key(), nil
+}
+
+func (r *APIKeyRepository) FindByPrefix(ctx context.Context, prefix string) (*models.APIKey, error) {
+	var row apiKeyRow
+	err := r.db.GetContext(ctx, &row, `SELECT `+apiKeyColumns+` FROM api_keys WHERE prefix = ?`, prefix)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("APIKeyRepository.FindByPrefix: %w", err)
+	}
+	return row.key(), nil
+}
+
+func (r *APIKeyRepository) FindByUser(ctx context.Context, userID int64) ([]*models.APIKey, error) {
+	var rows []apiKeyRow
+	err := r.db.SelectContext(ctx, &rows, `SELECT `+apiKeyColumns+` FROM api_keys WHERE user_id = ? ORDER BY id`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("APIKeyRepository.FindByUser: %w", err)
+	}
+	keys := make([]*models.APIKey, len(rows))
+	for i := range rows {
+		keys[i] = rows[i].key()
+	}
+	return keys, nil
+}
+
+func (r *APIKeyRepository) Revoke(ctx context.Context, id int64) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE api_keys SET revoked_at = NOW() WHERE id = ? AND revoked_at IS NULL`, id)
+	if err != nil {
+		return fmt.Errorf("APIKeyRepository.Revoke: %w", err)
+	}
+	return nil
+}
+
+// TouchLastUsed records a use of the key, at most once a minute so busy
+// integrations don't turn every request into a write.
+func (r *APIKeyRepository) TouchLastUsed(ctx context.Context, id int64) error {
+	_, err := r.db.ExecContext(ctx, `
+    UPDATE api_keys
+       SET last_used_at = NOW()
+     WHERE id = ? AND (last_used_at IS NULL OR last_used_at < NOW() - INTERVAL ? SECOND)`,
+		id, int(time.Minute.Seconds()))
+	if err != nil {
+		return fmt.Errorf("APIKeyRepository.TouchLastUsed: %w", err)
+	}
+	return nil
+}
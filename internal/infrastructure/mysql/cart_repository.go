@@ -1,6 +1,7 @@
 package mysql
 
 import (
+	"context"
 	"database/sql"
 
 	"github.com/jmoiron/sqlx"
@@ -15,9 +16,9 @@ func NewCartRepository(db *sqlx.DB) *CartRepository {
 	return &CartRepository{db: db}
 }
 
-func (r *CartRepository) FindByUserID(userID int64) (*models.Cart, error) {
+func (r *CartRepository) FindByUserID(ctx context.Context, userID int64) (*models.Cart, error) {
 	var cart models.Cart
-	err := r.db.Get(&cart, `
+	err := r.db.GetContext(ctx, &cart, `
         SELECT id, user_id, created_at, updated_at
           FROM carts
          WHERE user_id = ?`, userID)
@@ -27,15 +28,15 @@ func (r *CartRepository) FindByUserID(userID int64) (*models.Cart, error) {
 		}
 		return nil, err
 	}
-	err = r.db.Select(&cart.Items, `
+	err = r.db.SelectContext(ctx, &cart.Items, `
         SELECT id, cart_id, product_id, quantity, unit_price, created_at, updated_at
           FROM cart_items
          WHERE cart_id = ?`, cart.ID)
 	return &cart, err
 }
 
-func (r *CartRepository) CreateCart(cart *models.Cart) (int64, error) {
-	res, err := r.db.Exec(`
+func (r *CartRepository) CreateCart(ctx context.Context, cart *models.Cart) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `
         INSERT INTO carts (user_id, created_at, updated_at)
              VALUES (?, NOW(), NOW())`, cart.UserID)
 	if err != nil {
@@ -44,9 +45,9 @@ func (r *CartRepository) CreateCart(cart *models.Cart) (int64, error) {
 	return res.LastInsertId()
 }
 
-func (r *CartRepository) FindItem(itemID int64) (*models.CartItem, error) {
+func (r *CartRepository) FindItem(ctx context.Context, itemID int64) (*models.CartItem, error) {
 	var it models.CartItem
-	err := r.db.Get(&it, `
+	err := r.db.GetContext(ctx, &it, `
         SELECT id, cart_id, product_id, quantity, unit_price, created_at, updated_at
           FROM cart_items
          WHERE id = ?`, itemID)
@@ -59,9 +60,9 @@ func (r *CartRepository) FindItem(itemID int64) (*models.CartItem, error) {
 	return &it, nil
 }
 
-func (r *CartRepository) FindItemByCartAndProduct(cartID, productID int64) (*models.CartItem, error) {
+func (r *CartRepository) FindItemByCartAndProduct(ctx context.Context, cartID, productID int64) (*models.CartItem, error) {
 	var it models.CartItem
-	err := r.db.Get(&it, `
+	err := r.db.GetContext(ctx, &it, `
         SELECT id, cart_id, product_id, quantity, unit_price, created_at, updated_at
           FROM cart_items
          WHERE cart_id = ? AND product_id = ?`, cartID, productID)
@@ -74,8 +75,8 @@ func (r *CartRepository) FindItemByCartAndProduct(cartID, productID int64) (*mod
 	return &it, nil
 }
 
-func (r *CartRepository) CreateItem(item *models.CartItem) (int64, error) {
-	res, err := r.db.Exec(`
+func (r *CartRepository) CreateItem(ctx context.Context, item *models.CartItem) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `
         INSERT INTO cart_items (cart_id, product_id, quantity, unit_price, created_at, updated_at)
              VALUES (?, ?, ?, ?, NOW(), NOW())`,
 		item.CartID, item.ProductID, item.Quantity, item.UnitPrice,
@@ -86,8 +87,8 @@ func (r *CartRepository) CreateItem(item *models.CartItem) (int64, error) {
 	return res.LastInsertId()
 }
 
-func (r *CartRepository) UpdateItem(item *models.CartItem) error {
-	_, err := r.db.Exec(`
+func (r *CartRepository) UpdateItem(ctx context.Context, item *models.CartItem) error {
+	_, err := r.db.ExecContext(ctx, `
         UPDATE cart_items
            SET quantity = ?, updated_at = NOW()
          WHERE id = ?`,
@@ -96,12 +97,12 @@ func (r *CartRepository) UpdateItem(item *models.CartItem) error {
 	return err
 }
 
-func (r *CartRepository) DeleteItem(itemID int64) error {
-	_, err := r.db.Exec(`DELETE FROM cart_items WHERE id = ?`, itemID)
+func (r *CartRepository) DeleteItem(ctx context.Context, itemID int64) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM cart_items WHERE id = ?`, itemID)
 	return err
 }
 
-func (r *CartRepository) DeleteItemsByCartID(cartID int64) error {
-	_, err := r.db.Exec(`DELETE FROM cart_items WHERE cart_id = ?`, cartID)
+func (r *CartRepository) DeleteItemsByCartID(ctx context.Context, cartID int64) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM cart_items WHERE cart_id = ?`, cartID)
 	return err
 }
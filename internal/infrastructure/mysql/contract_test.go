@@ -0,0 +1,40 @@
+//go:build integration
+
+package mysql_test
+
+import (
+	"context"
+	"testing"
+
+	"richisntreal-backend/cmd/config"
+	"richisntreal-backend/internal/infrastructure/mysql"
+	"richisntreal-backend/internal/infrastructure/repotest"
+)
+
+// TestRepositoryContract runs the repository contract against the database
+// the RICHISNTREAL_MYSQL_* variables point at, which must be migrated:
+//
+//	go test -tags integration ./internal/infrastructure/mysql/
+//
+// The checks only add rows, but use a scratch database all the same.
+func TestRepositoryContract(t *testing.T) {
+	if err := config.Load(); err != nil {
+		t.Fatal(err)
+	}
+	client, err := mysql.NewMySQL(config.Get().MySQL)
+	if err != nil {
+		t.Fatalf("connecting to MySQL: %v", err)
+	}
+	defer client.DB.Close()
+
+	err = repotest.Check(context.Background(), repotest.Repositories{
+		Users:    mysql.NewUserRepository(client.DB),
+		Carts:    mysql.NewCartRepository(client.DB),
+		Products: mysql.NewProductRepository(client.DB),
+		Orders:   mysql.NewOrderRepository(client.DB),
+		Payments: mysql.NewPaymentRepository(client.DB),
+	})
+	if err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,60 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Name identifies the database in readiness reports.
+func (m *MySQL) Name() string {
+	return "mysql"
+}
+
+// Check pings the database.
+func (m *MySQL) Check(ctx context.Context) error {
+	return m.DB.PingContext(ctx)
+}
+
+// MigrationCheck reports whether the schema is at least at the version
+// this build migrated it to, and not left dirty by a failed migration.
+type MigrationCheck struct {
+	db   *sqlx.DB
+	want uint
+}
+
+// NewMigrationCheck checks the schema against version want.
+func NewMigrationCheck(db *sqlx.DB, want uint) *MigrationCheck {
+	return &MigrationCheck{db: db, want: want}
+}
+
+// Name identifies the check in readiness reports.
+func (c *MigrationCheck) Name() string {
+	return "migrations"
+}
+
+// Check reads golang-migrate's bookkeeping table. A newer version is fine:
+// another instance of a later release may have migrated already.
+func (c *MigrationCheck) Check(ctx context.Context) error {
+	var row struct {
+		Version uint `db:"version"`
+		Dirty   bool `db:"dirty"`
+	}
+	err := c.db.GetContext(ctx, &row, `SELECT version, dirty FROM schema_migrations LIMIT 1`)
+	if errors.Is(err, sql.ErrNoRows) {
+		return errors.New("no migrations applied")
+	}
+	if err != nil {
+		return err
+	}
+	if row.Dirty {
+		return fmt.Errorf("migration %d failed half-way", row.Version)
+	}
+	if row.Version < c.want {
+		return fmt.Errorf("schema at version %d, want %d", row.Version, c.want)
+	}
+	return nil
+}
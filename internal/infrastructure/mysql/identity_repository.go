@@ -0,0 +1,97 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"richisntreal-backend/internal/core/domain/models"
+)
+
+// IdentityRepository implements persistence for external identities and
+// OIDC logins in flight.
+type IdentityRepository struct {
+	db *sqlx.DB
+}
+
+func NewIdentityRepository(db *sqlx.DB) *IdentityRepository {
+	return &IdentityRepository{db: db}
+}
+
+func (r *IdentityRepository) FindIdentity(ctx context.Context, provider, subject string) (*models.UserIdentity, error) {
+	var id models.UserIdentity
+	err := r.db.GetContext(ctx, &id, `
+    SELECT id, user_id, provider, subject, email, created_at
+      FROM user_identities
+     WHERE provider = ? AND subject = ?`, provider, subject)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("IdentityRepository.FindIdentity: %w", err)
+	}
+	return &id, nil
+}
+
+func (r *IdentityRepository) FindIdentitiesByUser(ctx context.Context, userID int64) ([]*models.UserIdentity, error) {
+	var ids []*models.UserIdentity
+	err := r.db.SelectContext(ctx, &ids, `
+    SELECT id, user_id, provider, subject, email, created_at
+      FROM user_identities
+     WHERE user_id = ?
+     ORDER BY id`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("IdentityRepository.FindIdentitiesByUser: %w", err)
+	}
+	return ids, nil
+}
+
+func (r *IdentityRepository) CreateIdentity(ctx context.Context, id *models.UserIdentity) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `
+    INSERT INTO user_identities (user_id, provider, subject, email, created_at)
+    VALUES (?, ?, ?, ?, NOW())`, id.UserID, id.Provider, id.Subject, id.Email)
+	if err != nil {
+		return 0, fmt.Errorf("IdentityRepository.CreateIdentity: %w", err)
+	}
+	return res.LastInsertId()
+}
+
+func (r *IdentityRepository) CreateLoginState(ctx context.Context, s *models.OIDCLoginState) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `
+    INSERT INTO oidc_login_states (provider, state_hash, nonce, code_verifier, expires_at, created_at)
+    VALUES (?, ?, ?, ?, ?, NOW())`, s.Provider, s.StateHash, s.Nonce, s.CodeVerifier, s.ExpiresAt)
+	if err != nil {
+		return 0, fmt.Errorf("IdentityRepository.CreateLoginState: %w", err)
+	}
+	return res.LastInsertId()
+}
+
+func (r *IdentityRepository) FindLoginState(ctx context.Context, stateHash string) (*models.OIDCLoginState, error) {
+	var s models.OIDCLoginState
+	err := r.db.GetContext(ctx, &s, `
+    SELECT id, provider, state_hash, nonce, code_verifier, expires_at, used_at, created_at
+      FROM oidc_login_states
+     WHERE state_hash = ?`, stateHash)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("IdentityRepository.FindLoginState: %w", err)
+	}
+	return &s, nil
+}
+
+// MarkLoginStateUsed redeems a state. It reports false when it was already used.
+func (r *IdentityRepository) MarkLoginStateUsed(ctx context.Context, id int64) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `
+    UPDATE oidc_login_states
+       SET used_at = NOW()
+     WHERE id = ? AND used_at IS NULL`, id)
+	if err != nil {
+		return false, fmt.Errorf("IdentityRepository.MarkLoginStateUsed: %w", err)
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
@@ -0,0 +1,78 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+	"richisntreal-backend/internal/core/domain/models"
+)
+
+// InvoiceRepository implements persistence for invoices.
+type InvoiceRepository struct {
+	db *sqlx.DB
+}
+
+func NewInvoiceRepository(db *sqlx.DB) *InvoiceRepository {
+	return &InvoiceRepository{db: db}
+}
+
+// Allocate issues the invoice for an order, handing out the next number.
+// The counter row stays locked until the invoice is stored, so a failed
+// insert never burns a number. Allocating twice returns the first invoice.
+func (r *InvoiceRepository) Allocate(ctx context.Context, orderID int64) (*models.Invoice, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var last int64
+	if err := tx.GetContext(ctx, &last, `SELECT last_number FROM invoice_sequence WHERE id = 1 FOR UPDATE`); err != nil {
+		return nil, err
+	}
+
+	var inv models.Invoice
+	err = tx.GetContext(ctx, &inv, `
+        SELECT id, order_id, number, issued_at, created_at, updated_at
+          FROM invoices
+         WHERE order_id = ?
+    `, orderID)
+	if err == nil {
+		return &inv, tx.Commit()
+	}
+	if err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	number := last + 1
+	if _, err := tx.ExecContext(ctx, `
+        INSERT INTO invoices (order_id, number, issued_at, created_at, updated_at)
+        VALUES (?, ?, NOW(), NOW(), NOW())
+    `, orderID, number); err != nil {
+		return nil, err
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE invoice_sequence SET last_number = ? WHERE id = 1`, number); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return r.FindByOrder(ctx, orderID)
+}
+
+func (r *InvoiceRepository) FindByOrder(ctx context.Context, orderID int64) (*models.Invoice, error) {
+	var inv models.Invoice
+	err := r.db.GetContext(ctx, &inv, `
+        SELECT id, order_id, number, issued_at, created_at, updated_at
+          FROM invoices
+         WHERE order_id = ?
+    `, orderID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &inv, nil
+}
@@ -0,0 +1,56 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"richisntreal-backend/internal/core/domain/models"
+)
+
+// LoginAttemptRepository implements persistence for the login audit trail.
+type LoginAttemptRepository struct {
+	db *sqlx.DB
+}
+
+func NewLoginAttemptRepository(db *sqlx.DB) *LoginAttemptRepository {
+	return &LoginAttemptRepository{db: db}
+}
+
+func (r *LoginAttemptRepository) Create(ctx context.Context, a *models.LoginAttempt) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `
+    INSERT INTO login_attempts (user_id, email, ip, success, failure_reason, created_at)
+    VALUES (?, ?, ?, ?, ?, NOW())`, a.UserID, a.Email, a.IP, a.Success, a.FailureReason)
+	if err != nil {
+		return 0, fmt.Errorf("LoginAttemptRepository.Create: %w", err)
+	}
+	return res.LastInsertId()
+}
+
+// RecentFailures counts wrong-password and wrong-code attempts on email within window
+// that came after the last successful login, and how long ago the latest
+// of them was. Ages are worked out by MySQL so they don't depend on the
+// server and application agreeing on a time zone.
+func (r *LoginAttemptRepository) RecentFailures(ctx context.Context, email string, window time.Duration) (int, time.Duration, error) {
+	var row struct {
+		Count int           `db:"count"`
+		Age   sql.NullInt64 `db:"age"`
+	}
+	err := r.db.GetContext(ctx, &row, `
+    SELECT COUNT(*) AS count, TIMESTAMPDIFF(SECOND, MAX(created_at), NOW()) AS age
+      FROM login_attempts
+     WHERE email = ?
+       AND success = FALSE
+       AND failure_reason IN (?, ?)
+       AND created_at >= NOW() - INTERVAL ? SECOND
+       AND created_at > COALESCE(
+             (SELECT MAX(created_at) FROM login_attempts WHERE email = ? AND success = TRUE),
+             '1970-01-01')`,
+		email, models.LoginFailureInvalidCredentials, models.LoginFailureInvalidSecondFactor, int64(window.Seconds()), email)
+	if err != nil {
+		return 0, 0, fmt.Errorf("LoginAttemptRepository.RecentFailures: %w", err)
+	}
+	return row.Count, time.Duration(row.Age.Int64) * time.Second, nil
+}
@@ -0,0 +1,147 @@
+package mysql
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+
+	"github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/mysql"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+
+	"richisntreal-backend/cmd/config"
+)
+
+// migrationFiles is the schema, built into the binary so it runs the same
+// from any working directory and inside the container.
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// Migrator moves the database schema between the embedded migrations.
+type Migrator struct {
+	m *migrate.Migrate
+}
+
+// MigrationStatus is where the schema stands against this build.
+type MigrationStatus struct {
+	Version uint   // 0 when nothing has been applied
+	Dirty   bool   // a migration failed half-way and needs fixing by hand
+	Latest  uint   // newest migration this build carries
+	Pending []uint // migrations not applied yet, in order
+}
+
+// NewMigrator connects to the database in cfg.
+func NewMigrator(cfg config.MySQL) (*Migrator, error) {
+	src, err := iofs.New(migrationFiles, "migrations")
+	if err != nil {
+		return nil, fmt.Errorf("migrations: failed to read embedded files: %w", err)
+	}
+	dbURL := fmt.Sprintf(
+		"mysql://%s:%s@tcp(%s:%s)/%s?multiStatements=true",
+		cfg.Username,
+		cfg.Password,
+		cfg.Host,
+		cfg.Port,
+		cfg.Database,
+	)
+	m, err := migrate.NewWithSourceInstance("iofs", src, dbURL)
+	if err != nil {
+		return nil, fmt.Errorf("migrations: failed to initialize: %w", err)
+	}
+	return &Migrator{m: m}, nil
+}
+
+// Up applies every pending migration.
+func (m *Migrator) Up() error {
+	if err := m.m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return fmt.Errorf("migrations: failed to run up: %w", err)
+	}
+	return nil
+}
+
+// Down reverts the most recently applied migration.
+func (m *Migrator) Down() error {
+	if err := m.m.Steps(-1); err != nil {
+		return fmt.Errorf("migrations: failed to run down: %w", err)
+	}
+	return nil
+}
+
+// To migrates up or down to exactly version.
+func (m *Migrator) To(version uint) error {
+	if err := m.m.Migrate(version); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return fmt.Errorf("migrations: failed to migrate to %d: %w", version, err)
+	}
+	return nil
+}
+
+// Force records version as applied and clears the dirty flag without
+// running anything, once a failed migration has been repaired by hand.
+func (m *Migrator) Force(version uint) error {
+	if err := m.m.Force(int(version)); err != nil {
+		return fmt.Errorf("migrations: failed to force %d: %w", version, err)
+	}
+	return nil
+}
+
+// Status reports the applied version and what is left to apply.
+func (m *Migrator) Status() (MigrationStatus, error) {
+	var st MigrationStatus
+	version, dirty, err := m.m.Version()
+	if err != nil && !errors.Is(err, migrate.ErrNilVersion) {
+		return st, fmt.Errorf("migrations: failed to read version: %w", err)
+	}
+	st.Version, st.Dirty = version, dirty
+
+	versions, err := migrationVersions()
+	if err != nil {
+		return st, err
+	}
+	for _, v := range versions {
+		if v > st.Version {
+			st.Pending = append(st.Pending, v)
+		}
+	}
+	if len(versions) > 0 {
+		st.Latest = versions[len(versions)-1]
+	}
+	return st, nil
+}
+
+// Close releases the database connection.
+func (m *Migrator) Close() error {
+	srcErr, dbErr := m.m.Close()
+	return errors.Join(srcErr, dbErr)
+}
+
+// LatestMigration is the newest migration this build carries, the schema
+// version it expects to run against.
+func LatestMigration() (uint, error) {
+	versions, err := migrationVersions()
+	if err != nil || len(versions) == 0 {
+		return 0, err
+	}
+	return versions[len(versions)-1], nil
+}
+
+// migrationVersions lists the embedded migrations in order.
+func migrationVersions() ([]uint, error) {
+	src, err := iofs.New(migrationFiles, "migrations")
+	if err != nil {
+		return nil, fmt.Errorf("migrations: failed to read embedded files: %w", err)
+	}
+	defer src.Close()
+
+	var versions []uint
+	v, err := src.First()
+	for err == nil {
+		versions = append(versions, v)
+		v, err = src.Next(v)
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("migrations: failed to list embedded files: %w", err)
+	}
+	return versions, nil
+}
@@ -0,0 +1,6 @@
+ALTER TABLE products
+    DROP COLUMN stock;
+
+ALTER TABLE orders
+    DROP COLUMN cancelled_at,
+    DROP COLUMN cancellation_reason;
@@ -0,0 +1,7 @@
+ALTER TABLE orders
+    ADD COLUMN cancellation_reason TEXT DEFAULT NULL,
+    ADD COLUMN cancelled_at        TIMESTAMP NULL DEFAULT NULL;
+
+-- NULL stock means the product's inventory is not tracked
+ALTER TABLE products
+    ADD COLUMN stock INT DEFAULT NULL;
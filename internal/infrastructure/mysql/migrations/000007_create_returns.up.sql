@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS return_requests (
+    id             BIGINT AUTO_INCREMENT PRIMARY KEY,
+    order_id       BIGINT NOT NULL,
+    user_id        BIGINT NOT NULL,
+    status         VARCHAR(50) NOT NULL DEFAULT 'requested',
+    reason         TEXT NOT NULL,
+    resolution     VARCHAR(20) DEFAULT NULL,     -- "refund" or "restock"
+    admin_note     TEXT,
+    refund_amount  DECIMAL(10,2) DEFAULT NULL,   -- set once the refund is issued
+    created_at     TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at     TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (order_id) REFERENCES orders(id),
+    FOREIGN KEY (user_id)  REFERENCES users(id)
+);
+
+CREATE TABLE IF NOT EXISTS return_items (
+    id             BIGINT AUTO_INCREMENT PRIMARY KEY,
+    return_id      BIGINT NOT NULL,
+    order_item_id  BIGINT NOT NULL,
+    quantity       INT NOT NULL,
+    created_at     TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at     TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (return_id)     REFERENCES return_requests(id) ON DELETE CASCADE,
+    FOREIGN KEY (order_item_id) REFERENCES order_items(id)
+);
@@ -0,0 +1,32 @@
+CREATE TABLE IF NOT EXISTS order_addresses (
+    id           BIGINT AUTO_INCREMENT PRIMARY KEY,
+    order_id     BIGINT NOT NULL,
+    kind         VARCHAR(20) NOT NULL,          -- e.g. "billing"
+    name         VARCHAR(255) NOT NULL,
+    line1        VARCHAR(255) NOT NULL,
+    line2        VARCHAR(255) NOT NULL DEFAULT '',
+    city         VARCHAR(100) NOT NULL,
+    postal_code  VARCHAR(20) NOT NULL,
+    country      VARCHAR(100) NOT NULL,
+    created_at   TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at   TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE KEY uq_order_addresses_kind (order_id, kind),
+    FOREIGN KEY (order_id) REFERENCES orders(id)
+);
+
+-- single-row counter; locked while a number is handed out so numbers never skip
+CREATE TABLE IF NOT EXISTS invoice_sequence (
+    id           TINYINT PRIMARY KEY,
+    last_number  BIGINT NOT NULL
+);
+INSERT INTO invoice_sequence (id, last_number) VALUES (1, 0);
+
+CREATE TABLE IF NOT EXISTS invoices (
+    id          BIGINT AUTO_INCREMENT PRIMARY KEY,
+    order_id    BIGINT NOT NULL UNIQUE,
+    number      BIGINT NOT NULL UNIQUE,
+    issued_at   TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_at  TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at  TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (order_id) REFERENCES orders(id)
+);
@@ -0,0 +1,17 @@
+ALTER TABLE orders
+    ADD COLUMN reference VARCHAR(20) DEFAULT NULL AFTER id;
+
+-- backfill existing orders with random references in the same shape, drawn
+-- from the alphabet new references use (services.referenceAlphabet)
+SET @alphabet := '23456789ABCDEFGHJKMNPQRSTUVWXYZ';
+UPDATE orders
+   SET reference = CONCAT('RNR-',
+                          SUBSTRING(@alphabet, 1 + FLOOR(RAND() * 31), 1), SUBSTRING(@alphabet, 1 + FLOOR(RAND() * 31), 1),
+                          SUBSTRING(@alphabet, 1 + FLOOR(RAND() * 31), 1), SUBSTRING(@alphabet, 1 + FLOOR(RAND() * 31), 1), '-',
+                          SUBSTRING(@alphabet, 1 + FLOOR(RAND() * 31), 1), SUBSTRING(@alphabet, 1 + FLOOR(RAND() * 31), 1),
+                          SUBSTRING(@alphabet, 1 + FLOOR(RAND() * 31), 1), SUBSTRING(@alphabet, 1 + FLOOR(RAND() * 31), 1))
+ WHERE reference IS NULL;
+
+ALTER TABLE orders
+    MODIFY COLUMN reference VARCHAR(20) NOT NULL,
+    ADD UNIQUE INDEX uq_orders_reference (reference);
@@ -0,0 +1,4 @@
+DROP INDEX idx_orders_created ON orders;
+DROP INDEX idx_orders_status_created ON orders;
+
+DROP TABLE IF EXISTS order_notes;
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS order_notes (
+    id          BIGINT AUTO_INCREMENT PRIMARY KEY,
+    order_id    BIGINT NOT NULL,
+    author_id   BIGINT NOT NULL,
+    body        TEXT NOT NULL,
+    created_at  TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (order_id)  REFERENCES orders(id),
+    FOREIGN KEY (author_id) REFERENCES users(id)
+);
+
+-- back-office searches filter on these
+CREATE INDEX idx_orders_status_created ON orders (status, created_at);
+CREATE INDEX idx_orders_created ON orders (created_at);
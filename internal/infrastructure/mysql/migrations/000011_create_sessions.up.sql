@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS sessions (
+    id              BIGINT AUTO_INCREMENT PRIMARY KEY,
+    user_id         BIGINT NOT NULL,
+    revoked_at      TIMESTAMP NULL DEFAULT NULL,
+    revoked_reason  VARCHAR(50) DEFAULT NULL,     -- e.g. "logout", "logout_all", "token_reuse"
+    created_at      TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at      TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    INDEX idx_sessions_user (user_id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id          BIGINT AUTO_INCREMENT PRIMARY KEY,
+    session_id  BIGINT NOT NULL,
+    token_hash  CHAR(64) NOT NULL UNIQUE,         -- hex SHA-256 of the token
+    expires_at  TIMESTAMP NOT NULL,
+    rotated_at  TIMESTAMP NULL DEFAULT NULL,      -- set once exchanged; reuse after that is theft
+    created_at  TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (session_id) REFERENCES sessions(id) ON DELETE CASCADE
+);
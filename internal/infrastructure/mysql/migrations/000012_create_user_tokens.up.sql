@@ -0,0 +1,14 @@
+ALTER TABLE users
+    ADD COLUMN email_verified_at TIMESTAMP NULL DEFAULT NULL;
+
+CREATE TABLE IF NOT EXISTS user_tokens (
+    id          BIGINT AUTO_INCREMENT PRIMARY KEY,
+    user_id     BIGINT NOT NULL,
+    purpose     VARCHAR(30) NOT NULL,             -- e.g. "password_reset", "email_verification"
+    token_hash  CHAR(64) NOT NULL UNIQUE,         -- hex SHA-256 of the mailed token
+    expires_at  TIMESTAMP NOT NULL,
+    used_at     TIMESTAMP NULL DEFAULT NULL,
+    created_at  TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_user_tokens_user_purpose (user_id, purpose),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
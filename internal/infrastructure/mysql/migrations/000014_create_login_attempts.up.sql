@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS login_attempts (
+    id              BIGINT AUTO_INCREMENT PRIMARY KEY,
+    user_id         BIGINT NULL,                      -- NULL when the email matched no account
+    email           VARCHAR(255) NOT NULL,
+    ip              VARCHAR(45) NOT NULL,
+    success         BOOLEAN NOT NULL,
+    failure_reason  VARCHAR(30) NULL,                 -- e.g. "invalid_credentials", "locked"
+    created_at      TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_login_attempts_email_created (email, created_at),
+    INDEX idx_login_attempts_ip_created (ip, created_at)
+);
@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS user_identities (
+    id          BIGINT AUTO_INCREMENT PRIMARY KEY,
+    user_id     BIGINT NOT NULL,
+    provider    VARCHAR(50) NOT NULL,             -- provider name from the OIDC config
+    subject     VARCHAR(255) NOT NULL,            -- the provider's "sub" claim
+    email       VARCHAR(255) NOT NULL DEFAULT '',
+    created_at  TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY uq_user_identities_provider_subject (provider, subject),
+    INDEX idx_user_identities_user (user_id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS oidc_login_states (
+    id             BIGINT AUTO_INCREMENT PRIMARY KEY,
+    provider       VARCHAR(50) NOT NULL,
+    state_hash     CHAR(64) NOT NULL UNIQUE,      -- hex SHA-256 of the state parameter
+    nonce          VARCHAR(64) NOT NULL,
+    code_verifier  VARCHAR(128) NOT NULL,         -- PKCE verifier, never leaves the server
+    expires_at     TIMESTAMP NOT NULL,
+    used_at        TIMESTAMP NULL DEFAULT NULL,
+    created_at     TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
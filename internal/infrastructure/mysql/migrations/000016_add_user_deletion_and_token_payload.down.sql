@@ -0,0 +1,5 @@
+ALTER TABLE user_tokens
+    DROP COLUMN payload;
+
+ALTER TABLE users
+    DROP COLUMN deleted_at;
@@ -0,0 +1,5 @@
+ALTER TABLE users
+    ADD COLUMN deleted_at TIMESTAMP NULL DEFAULT NULL;
+
+ALTER TABLE user_tokens
+    ADD COLUMN payload VARCHAR(255) NULL DEFAULT NULL;
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS api_keys (
+    id            BIGINT AUTO_INCREMENT PRIMARY KEY,
+    user_id       BIGINT NOT NULL,
+    name          VARCHAR(100) NOT NULL,
+    prefix        VARCHAR(16) NOT NULL UNIQUE,      -- public part of the key, used for lookup
+    key_hash      CHAR(64) NOT NULL,                -- hex SHA-256 of the whole key
+    scopes        VARCHAR(255) NOT NULL,            -- space-separated, e.g. "orders:read orders:write"
+    expires_at    TIMESTAMP NULL DEFAULT NULL,
+    last_used_at  TIMESTAMP NULL DEFAULT NULL,
+    revoked_at    TIMESTAMP NULL DEFAULT NULL,
+    created_at    TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_api_keys_user (user_id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS admin_audit_log;
+
+ALTER TABLE sessions
+    DROP COLUMN expires_at,
+    DROP COLUMN impersonator_id;
+
+ALTER TABLE users
+    DROP COLUMN disabled_at;
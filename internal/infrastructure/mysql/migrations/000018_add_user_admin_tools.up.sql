@@ -0,0 +1,18 @@
+ALTER TABLE users
+    ADD COLUMN disabled_at TIMESTAMP NULL DEFAULT NULL;
+
+-- impersonation sessions: started by an admin, no refresh token, hard expiry
+ALTER TABLE sessions
+    ADD COLUMN impersonator_id BIGINT NULL DEFAULT NULL,
+    ADD COLUMN expires_at TIMESTAMP NULL DEFAULT NULL;
+
+CREATE TABLE IF NOT EXISTS admin_audit_log (
+    id          BIGINT AUTO_INCREMENT PRIMARY KEY,
+    admin_id    BIGINT NOT NULL,
+    user_id     BIGINT NULL,
+    action      VARCHAR(50) NOT NULL,
+    detail      VARCHAR(255) NOT NULL DEFAULT '',
+    created_at  TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_admin_audit_admin (admin_id, created_at),
+    INDEX idx_admin_audit_user (user_id, created_at)
+);
@@ -0,0 +1,2 @@
+ALTER TABLE payment_transactions
+    DROP COLUMN refunded_amount;
@@ -0,0 +1,16 @@
+-- how much of a charge has been given back, so partial refunds can't exceed it
+ALTER TABLE payment_transactions
+    ADD COLUMN refunded_amount DECIMAL(10,2) NOT NULL DEFAULT 0 AFTER amount;
+
+UPDATE payment_transactions
+   SET refunded_amount = amount
+ WHERE status = 'refunded';
+
+-- partial refunds so far were only ever issued for returns
+UPDATE payment_transactions p
+   SET p.refunded_amount = LEAST(p.amount, (
+           SELECT COALESCE(SUM(rr.refund_amount), 0)
+             FROM return_requests rr
+            WHERE rr.order_id = p.order_id
+              AND rr.status = 'refunded'))
+ WHERE p.status = 'partially_refunded';
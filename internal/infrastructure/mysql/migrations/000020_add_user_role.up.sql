@@ -0,0 +1,12 @@
+-- databases migrated before this had the column added by 000007
+SET @missing := (SELECT COUNT(*) = 0
+                   FROM information_schema.columns
+                  WHERE table_schema = DATABASE()
+                    AND table_name = 'users'
+                    AND column_name = 'role');
+SET @ddl := IF(@missing,
+               'ALTER TABLE users ADD COLUMN role VARCHAR(20) NOT NULL DEFAULT ''customer''',
+               'DO 0');
+PREPARE add_role FROM @ddl;
+EXECUTE add_role;
+DEALLOCATE PREPARE add_role;
@@ -3,8 +3,10 @@ package mysql
 import (
 	"fmt"
 
+	"github.com/XSAM/otelsql"
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/jmoiron/sqlx"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
 
 	"richisntreal-backend/cmd/config"
 )
@@ -22,9 +24,22 @@ func NewMySQL(cfg config.MySQL) (*MySQL, error) {
 		cfg.Port,
 		cfg.Database,
 	)
-	db, err := sqlx.Connect("mysql", dsn)
+	// every query gets a span; rows and session resets would only add noise
+	sqlDB, err := otelsql.Open("mysql", dsn,
+		otelsql.WithAttributes(semconv.DBSystemMySQL),
+		otelsql.WithSpanOptions(otelsql.SpanOptions{
+			DisableErrSkip:       true,
+			OmitConnResetSession: true,
+			OmitRows:             true,
+		}),
+	)
 	if err != nil {
 		return nil, err
 	}
+	db := sqlx.NewDb(sqlDB, "mysql")
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
 	return &MySQL{DB: db}, nil
 }
@@ -1,7 +1,9 @@
 package mysql
 
 import (
+	"context"
 	"database/sql"
+	"strings"
 
 	"github.com/jmoiron/sqlx"
 	"richisntreal-backend/internal/core/domain/models"
@@ -15,19 +17,19 @@ func NewOrderRepository(db *sqlx.DB) *OrderRepository {
 	return &OrderRepository{db: db}
 }
 
-func (r *OrderRepository) CreateOrder(o *models.Order) (int64, error) {
-	res, err := r.db.Exec(`
-        INSERT INTO orders (user_id, total, status, created_at, updated_at)
-        VALUES (?, ?, ?, NOW(), NOW())
-    `, o.UserID, o.Total, o.Status)
+func (r *OrderRepository) CreateOrder(ctx context.Context, o *models.Order) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `
+        INSERT INTO orders (reference, user_id, total, status, created_at, updated_at)
+        VALUES (?, ?, ?, ?, NOW(), NOW())
+    `, o.Reference, o.UserID, o.Total, o.Status)
 	if err != nil {
 		return 0, err
 	}
 	return res.LastInsertId()
 }
 
-func (r *OrderRepository) CreateOrderItem(item *models.OrderItem) (int64, error) {
-	res, err := r.db.Exec(`
+func (r *OrderRepository) CreateOrderItem(ctx context.Context, item *models.OrderItem) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `
         INSERT INTO order_items (order_id, product_id, quantity, unit_price, created_at, updated_at)
         VALUES (?, ?, ?, ?, NOW(), NOW())
     `, item.OrderID, item.ProductID, item.Quantity, item.UnitPrice)
@@ -37,30 +39,35 @@ func (r *OrderRepository) CreateOrderItem(item *models.OrderItem) (int64, error)
 	return res.LastInsertId()
 }
 
-func (r *OrderRepository) FindOrdersByUser(userID int64) ([]*models.Order, error) {
+func (r *OrderRepository) CreateAddress(ctx context.Context, addr *models.Address) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `
+        INSERT INTO order_addresses (order_id, kind, name, line1, line2, city, postal_code, country, created_at, updated_at)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?, NOW(), NOW())
+    `, addr.OrderID, addr.Kind, addr.Name, addr.Line1, addr.Line2, addr.City, addr.PostalCode, addr.Country)
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+func (r *OrderRepository) FindOrdersByUser(ctx context.Context, userID int64) ([]*models.Order, error) {
 	var orders []*models.Order
-	if err := r.db.Select(&orders, `
-        SELECT id, user_id, total, status, created_at, updated_at
+	if err := r.db.SelectContext(ctx, &orders, `
+        SELECT id, reference, user_id, total, status, cancellation_reason, cancelled_at, created_at, updated_at
         FROM orders WHERE user_id = ?
     `, userID); err != nil {
 		return nil, err
 	}
-	// load items per order
-	for _, ord := range orders {
-		var items []models.OrderItem
-		r.db.Select(&items, `
-            SELECT id, order_id, product_id, quantity, unit_price, created_at, updated_at
-            FROM order_items WHERE order_id = ?
-        `, ord.ID)
-		ord.Items = items
+	if err := r.loadDetails(ctx, orders...); err != nil {
+		return nil, err
 	}
 	return orders, nil
 }
 
-func (r *OrderRepository) FindOrderByID(orderID int64) (*models.Order, error) {
+func (r *OrderRepository) FindOrderByID(ctx context.Context, orderID int64) (*models.Order, error) {
 	var ord models.Order
-	if err := r.db.Get(&ord, `
-        SELECT id, user_id, total, status, created_at, updated_at
+	if err := r.db.GetContext(ctx, &ord, `
+        SELECT id, reference, user_id, total, status, cancellation_reason, cancelled_at, created_at, updated_at
         FROM orders WHERE id = ?
     `, orderID); err != nil {
 		if err == sql.ErrNoRows {
@@ -68,11 +75,198 @@ func (r *OrderRepository) FindOrderByID(orderID int64) (*models.Order, error) {
 		}
 		return nil, err
 	}
-	var items []models.OrderItem
-	r.db.Select(&items, `
-        SELECT id, order_id, product_id, quantity, unit_price, created_at, updated_at
-        FROM order_items WHERE order_id = ?
-    `, ord.ID)
-	ord.Items = items
+	if err := r.loadDetails(ctx, &ord); err != nil {
+		return nil, err
+	}
 	return &ord, nil
 }
+
+// loadDetails fills in the items and billing addresses of orders, with one
+// query for each however many orders there are.
+func (r *OrderRepository) loadDetails(ctx context.Context, orders ...*models.Order) error {
+	if len(orders) == 0 {
+		return nil
+	}
+	ids := make([]int64, len(orders))
+	byID := make(map[int64]*models.Order, len(orders))
+	for i, ord := range orders {
+		ids[i] = ord.ID
+		byID[ord.ID] = ord
+		ord.Items = nil
+		ord.BillingAddress = nil
+	}
+
+	query, args, err := sqlx.In(`
+        SELECT id, order_id, product_id, quantity, unit_price, created_at, updated_at
+        FROM order_items WHERE order_id IN (?)
+        ORDER BY id
+    `, ids)
+	if err != nil {
+		return err
+	}
+	var items []models.OrderItem
+	if err := r.db.SelectContext(ctx, &items, r.db.Rebind(query), args...); err != nil {
+		return err
+	}
+	for _, it := range items {
+		ord := byID[it.OrderID]
+		ord.Items = append(ord.Items, it)
+	}
+
+	query, args, err = sqlx.In(`
+        SELECT id, order_id, kind, name, line1, line2, city, postal_code, country, created_at, updated_at
+        FROM order_addresses WHERE order_id IN (?) AND kind = ?
+    `, ids, models.AddressKindBilling)
+	if err != nil {
+		return err
+	}
+	var addrs []*models.Address
+	if err := r.db.SelectContext(ctx, &addrs, r.db.Rebind(query), args...); err != nil {
+		return err
+	}
+	for _, addr := range addrs {
+		byID[addr.OrderID].BillingAddress = addr
+	}
+	return nil
+}
+
+// FindOrderByReference looks an order up by its public reference.
+func (r *OrderRepository) FindOrderByReference(ctx context.Context, reference string) (*models.Order, error) {
+	var id int64
+	if err := r.db.GetContext(ctx, &id, `SELECT id FROM orders WHERE reference = ?`, reference); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return r.FindOrderByID(ctx, id)
+}
+
+// FindOrderByReferenceAndEmail only finds the order when the email matches
+// the one of the customer who placed it.
+func (r *OrderRepository) FindOrderByReferenceAndEmail(ctx context.Context, reference, email string) (*models.Order, error) {
+	var id int64
+	if err := r.db.GetContext(ctx, &id, `
+        SELECT o.id
+          FROM orders o
+          JOIN users u ON u.id = o.user_id
+         WHERE o.reference = ? AND LOWER(u.email) = LOWER(?)
+    `, reference, email); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return r.FindOrderByID(ctx, id)
+}
+
+// SearchOrders returns one page of orders matching the filter, newest
+// first, together with the total number of matches unless the filter asks
+// to skip counting them.
+func (r *OrderRepository) SearchOrders(ctx context.Context, f models.OrderFilter) ([]*models.Order, int, error) {
+	var where []string
+	var args []interface{}
+	if f.Status != "" {
+		where = append(where, "status = ?")
+		args = append(args, f.Status)
+	}
+	if f.UserID != 0 {
+		where = append(where, "user_id = ?")
+		args = append(args, f.UserID)
+	}
+	if f.From != nil {
+		where = append(where, "created_at >= ?")
+		args = append(args, *f.From)
+	}
+	if f.To != nil {
+		where = append(where, "created_at < ?")
+		args = append(args, *f.To)
+	}
+	if f.MinTotal != nil {
+		where = append(where, "total >= ?")
+		args = append(args, *f.MinTotal)
+	}
+	if f.MaxTotal != nil {
+		where = append(where, "total <= ?")
+		args = append(args, *f.MaxTotal)
+	}
+	cond := ""
+	if len(where) > 0 {
+		cond = "WHERE " + strings.Join(where, " AND ")
+	}
+
+	var total int
+	if !f.SkipCount {
+		if err := r.db.GetContext(ctx, &total, `SELECT COUNT(1) FROM orders `+cond, args...); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	query := `
+        SELECT id, reference, user_id, total, status, cancellation_reason, cancelled_at, created_at, updated_at
+        FROM orders ` + cond + `
+        ORDER BY created_at DESC, id DESC`
+	if f.Limit > 0 {
+		query += ` LIMIT ? OFFSET ?`
+		args = append(args, f.Limit, f.Offset)
+	}
+	var orders []*models.Order
+	if err := r.db.SelectContext(ctx, &orders, query, args...); err != nil {
+		return nil, 0, err
+	}
+	if err := r.loadDetails(ctx, orders...); err != nil {
+		return nil, 0, err
+	}
+	return orders, total, nil
+}
+
+func (r *OrderRepository) CreateNote(ctx context.Context, note *models.OrderNote) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `
+        INSERT INTO order_notes (order_id, author_id, body, created_at)
+        VALUES (?, ?, ?, NOW())
+    `, note.OrderID, note.AuthorID, note.Body)
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+func (r *OrderRepository) FindNotes(ctx context.Context, orderID int64) ([]*models.OrderNote, error) {
+	notes := []*models.OrderNote{}
+	err := r.db.SelectContext(ctx, &notes, `
+        SELECT id, order_id, author_id, body, created_at
+        FROM order_notes WHERE order_id = ?
+        ORDER BY id
+    `, orderID)
+	return notes, err
+}
+
+// UpdateStatus moves an order from one status to another. It reports false
+// when the order was not in the expected status.
+func (r *OrderRepository) UpdateStatus(ctx context.Context, orderID int64, from, to string) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `
+        UPDATE orders
+           SET status = ?, updated_at = NOW()
+         WHERE id = ? AND status = ?
+    `, to, orderID, from)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// CancelOrder marks an unfulfilled order as cancelled. It reports false when
+// the order was already past the point of cancellation.
+func (r *OrderRepository) CancelOrder(ctx context.Context, orderID int64, reason string) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `
+        UPDATE orders
+           SET status = ?, cancellation_reason = ?, cancelled_at = NOW(), updated_at = NOW()
+         WHERE id = ? AND status IN (?, ?)
+    `, models.OrderStatusCancelled, reason, orderID, models.OrderStatusPending, models.OrderStatusPaid)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
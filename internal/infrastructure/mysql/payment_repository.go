@@ -1,6 +1,9 @@
 package mysql
 
 import (
+	"context"
+	"database/sql"
+
 	"github.com/jmoiron/sqlx"
 	"richisntreal-backend/internal/core/domain/models"
 )
@@ -13,8 +16,8 @@ func NewPaymentRepository(db *sqlx.DB) *PaymentRepository {
 	return &PaymentRepository{db: db}
 }
 
-func (r *PaymentRepository) Create(tx *models.PaymentTransaction) (int64, error) {
-	res, err := r.db.Exec(`
+func (r *PaymentRepository) Create(ctx context.Context, tx *models.PaymentTransaction) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `
         INSERT INTO payment_transactions
             (order_id, amount, currency, provider, token, status, created_at, updated_at)
         VALUES
@@ -28,8 +31,8 @@ func (r *PaymentRepository) Create(tx *models.PaymentTransaction) (int64, error)
 	return res.LastInsertId()
 }
 
-func (r *PaymentRepository) UpdateStatus(id int64, status string, failureMessage *string) error {
-	_, err := r.db.Exec(`
+func (r *PaymentRepository) UpdateStatus(ctx context.Context, id int64, status string, failureMessage *string) error {
+	_, err := r.db.ExecContext(ctx, `
         UPDATE payment_transactions
            SET status = ?, failure_message = ?, updated_at = NOW()
          WHERE id = ?
@@ -39,15 +42,65 @@ func (r *PaymentRepository) UpdateStatus(id int64, status string, failureMessage
 	return err
 }
 
-func (r *PaymentRepository) FindByOrder(orderID int64) (*models.PaymentTransaction, error) {
+func (r *PaymentRepository) UpdateProviderTxID(ctx context.Context, id int64, providerTxID string) error {
+	_, err := r.db.ExecContext(ctx, `
+        UPDATE payment_transactions
+           SET provider_tx_id = ?, updated_at = NOW()
+         WHERE id = ?
+    `,
+		providerTxID, id,
+	)
+	return err
+}
+
+// AddRefund records that amount more of a charge was given back, marking
+// it refunded once nothing is left. The status is worked out from the
+// refunded amount before this update; MySQL assigns left to right.
+func (r *PaymentRepository) AddRefund(ctx context.Context, id int64, amount float64) error {
+	_, err := r.db.ExecContext(ctx, `
+        UPDATE payment_transactions
+           SET status = IF(ROUND((refunded_amount + ?) * 100) >= ROUND(amount * 100), ?, ?),
+               refunded_amount = refunded_amount + ?,
+               updated_at = NOW()
+         WHERE id = ?
+    `,
+		amount, models.PaymentStatusRefunded, models.PaymentStatusPartiallyRefunded, amount, id,
+	)
+	return err
+}
+
+// FindByOrder returns the most recent transaction for an order, or nil if
+// the order has never been paid for.
+func (r *PaymentRepository) FindByOrder(ctx context.Context, orderID int64) (*models.PaymentTransaction, error) {
 	var tx models.PaymentTransaction
-	err := r.db.Get(&tx, `
-        SELECT id, order_id, amount, currency, provider, provider_tx_id, token, status, failure_message, created_at, updated_at
+	err := r.db.GetContext(ctx, &tx, `
+        SELECT id, order_id, amount, refunded_amount, currency, provider, provider_tx_id, token, status, failure_message, created_at, updated_at
           FROM payment_transactions
          WHERE order_id = ?
+         ORDER BY id DESC
+         LIMIT 1
     `, orderID)
 	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
 		return nil, err
 	}
 	return &tx, nil
 }
+
+// FindUnsettledCancellations returns the latest transaction of every
+// cancelled order that was not voided or refunded.
+func (r *PaymentRepository) FindUnsettledCancellations(ctx context.Context) ([]*models.PaymentTransaction, error) {
+	var txs []*models.PaymentTransaction
+	err := r.db.SelectContext(ctx, &txs, `
+        SELECT p.id, p.order_id, p.amount, p.refunded_amount, p.currency, p.provider, p.provider_tx_id, p.token, p.status, p.failure_message, p.created_at, p.updated_at
+          FROM payment_transactions p
+          JOIN orders o ON o.id = p.order_id
+         WHERE o.status = ?
+           AND p.status IN (?, ?)
+           AND p.id = (SELECT MAX(id) FROM payment_transactions WHERE order_id = p.order_id)
+         ORDER BY p.id
+    `, models.OrderStatusCancelled, models.PaymentStatusPending, models.PaymentStatusSucceeded)
+	return txs, err
+}
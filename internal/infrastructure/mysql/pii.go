@@ -0,0 +1,282 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"richisntreal-backend/internal/infrastructure/logging"
+)
+
+// piiColumn is a column of a table in the PII registry.
+type piiColumn struct {
+	name   string
+	secret bool                // never exported, e.g. password hashes
+	mask   func(string) string // applied to the value on export
+	erase  string              // SQL expression written over the column on erasure; "" keeps it
+}
+
+// piiTable says where a user's data lives in one table, what of it is
+// handed over on export and what happens to it on erasure.
+type piiTable struct {
+	section    string // name in the export; "" leaves the table out
+	table      string
+	owner      string // WHERE clause picking the user's rows; every ? is the user id
+	columns    []piiColumn
+	deleteRows bool // erase by deleting the rows instead of overwriting columns
+}
+
+const (
+	ownOrders = `order_id IN (SELECT id FROM orders WHERE user_id = ?)`
+	erased    = `'[erased]'`
+)
+
+// piiRegistry lists every table holding personal data. Export and erasure
+// both walk it, so a new table with personal data only needs an entry here.
+//
+// Orders, order lines, payments and invoices are financial records that
+// must be retained, so erasure keeps their amounts and dates and only
+// scrubs free text and payment tokens. Billing addresses keep the city,
+// postal code and country the tax treatment depended on.
+//
+// Tables are erased in order; users comes last because earlier owner
+// clauses look up the user's email.
+var piiRegistry = []piiTable{
+	{
+		section: "login_attempts",
+		table:   "login_attempts",
+		owner:   `user_id = ? OR email = (SELECT email FROM users WHERE id = ?)`,
+		columns: []piiColumn{
+			{name: "ip", erase: `''`},
+			{name: "email", erase: `''`},
+			{name: "success"},
+			{name: "failure_reason"},
+			{name: "created_at"},
+		},
+	},
+	{
+		section: "addresses",
+		table:   "order_addresses",
+		owner:   ownOrders,
+		columns: []piiColumn{
+			{name: "order_id"},
+			{name: "kind"},
+			{name: "name", erase: `'Erased customer'`},
+			{name: "line1", erase: `''`},
+			{name: "line2", erase: `''`},
+			{name: "city"},
+			{name: "postal_code"},
+			{name: "country"},
+		},
+	},
+	{
+		section: "cart",
+		table:   "cart_items",
+		owner:   `cart_id IN (SELECT id FROM carts WHERE user_id = ?)`,
+		columns: []piiColumn{
+			{name: "product_id"},
+			{name: "quantity"},
+			{name: "unit_price"},
+			{name: "created_at"},
+		},
+		deleteRows: true,
+	},
+	{
+		table:      "carts",
+		owner:      `user_id = ?`,
+		deleteRows: true,
+	},
+	{
+		section: "orders",
+		table:   "orders",
+		owner:   `user_id = ?`,
+		columns: []piiColumn{
+			{name: "id"},
+			{name: "reference"},
+			{name: "status"},
+			{name: "total"},
+			{name: "cancellation_reason", erase: `NULL`},
+			{name: "cancelled_at"},
+			{name: "created_at"},
+		},
+	},
+	{
+		section: "order_items",
+		table:   "order_items",
+		owner:   ownOrders,
+		columns: []piiColumn{
+			{name: "order_id"},
+			{name: "product_id"},
+			{name: "quantity"},
+			{name: "unit_price"},
+		},
+	},
+	{
+		section: "payments",
+		table:   "payment_transactions",
+		owner:   ownOrders,
+		columns: []piiColumn{
+			{name: "order_id"},
+			{name: "amount"},
+			{name: "refunded_amount"},
+			{name: "currency"},
+			{name: "provider"},
+			{name: "provider_tx_id"},
+			{name: "token", mask: maskToken, erase: `CONCAT('****', RIGHT(token, 4))`},
+			{name: "status"},
+			{name: "failure_message", erase: `NULL`},
+			{name: "created_at"},
+		},
+	},
+	{
+		section: "invoices",
+		table:   "invoices",
+		owner:   ownOrders,
+		columns: []piiColumn{
+			{name: "order_id"},
+			{name: "number"},
+			{name: "issued_at"},
+		},
+	},
+	{
+		section: "returns",
+		table:   "return_requests",
+		owner:   `user_id = ?`,
+		columns: []piiColumn{
+			{name: "order_id"},
+			{name: "status"},
+			{name: "reason", erase: erased},
+			{name: "resolution"},
+			{name: "refund_amount"},
+			{name: "admin_note", secret: true, erase: `NULL`},
+			{name: "created_at"},
+		},
+	},
+	{
+		// staff notes may quote the customer; they are internal, so not exported
+		table: "order_notes",
+		owner: ownOrders,
+		columns: []piiColumn{
+			{name: "body", secret: true, erase: erased},
+		},
+	},
+	{
+		section: "sessions",
+		table:   "sessions",
+		owner:   `user_id = ?`,
+		columns: []piiColumn{
+			{name: "id"},
+			{name: "revoked_at"},
+			{name: "revoked_reason"},
+			{name: "created_at"},
+		},
+		deleteRows: true,
+	},
+	{
+		section: "linked_accounts",
+		table:   "user_identities",
+		owner:   `user_id = ?`,
+		columns: []piiColumn{
+			{name: "provider"},
+			{name: "email"},
+			{name: "created_at"},
+		},
+		deleteRows: true,
+	},
+	{
+		section: "api_keys",
+		table:   "api_keys",
+		owner:   `user_id = ?`,
+		columns: []piiColumn{
+			{name: "name"},
+			{name: "prefix"},
+			{name: "scopes"},
+			{name: "last_used_at"},
+			{name: "revoked_at"},
+			{name: "created_at"},
+		},
+		deleteRows: true,
+	},
+	{
+		// staff access to the account is kept as an audit trail
+		section: "support_access",
+		table:   "admin_audit_log",
+		owner:   `user_id = ?`,
+		columns: []piiColumn{
+			{name: "action"},
+			{name: "detail"},
+			{name: "created_at"},
+		},
+	},
+	{table: "user_tokens", owner: `user_id = ?`, deleteRows: true},
+	{table: "user_recovery_codes", owner: `user_id = ?`, deleteRows: true},
+	{table: "user_totp", owner: `user_id = ?`, deleteRows: true},
+	{
+		section: "profile",
+		table:   "users",
+		owner:   `id = ?`,
+		columns: []piiColumn{
+			{name: "id"},
+			{name: "username", erase: `'deleted user'`},
+			{name: "email", erase: `CONCAT('deleted-', id, '@invalid')`},
+			{name: "password", secret: true, erase: `'!'`},
+			{name: "first_name", erase: `''`},
+			{name: "last_name", erase: `''`},
+			{name: "country", erase: `''`},
+			{name: "date_of_birth", erase: `NULL`},
+			{name: "role"},
+			{name: "email_verified_at", erase: `NULL`},
+			{name: "disabled_at"},
+			{name: "deleted_at", secret: true, erase: `NOW()`},
+			{name: "created_at"},
+			{name: "updated_at"},
+		},
+	},
+}
+
+// ownerArgs repeats the user id once per placeholder in the owner clause.
+func (t piiTable) ownerArgs(userID int64) []interface{} {
+	args := make([]interface{}, strings.Count(t.owner, "?"))
+	for i := range args {
+		args[i] = userID
+	}
+	return args
+}
+
+// erasePII scrubs a user's personal data from every registered table.
+func erasePII(ctx context.Context, tx *sqlx.Tx, userID int64) error {
+	for _, t := range piiRegistry {
+		var query string
+		if t.deleteRows {
+			query = fmt.Sprintf(`DELETE FROM %s WHERE %s`, t.table, t.owner)
+		} else {
+			var sets []string
+			for _, c := range t.columns {
+				if c.erase != "" {
+					sets = append(sets, c.name+" = "+c.erase)
+				}
+			}
+			if len(sets) == 0 {
+				continue
+			}
+			query = fmt.Sprintf(`UPDATE %s SET %s WHERE %s`, t.table, strings.Join(sets, ", "), t.owner)
+		}
+		res, err := tx.ExecContext(ctx, query, t.ownerArgs(userID)...)
+		if err != nil {
+			return fmt.Errorf("erasing %s: %w", t.table, err)
+		}
+		if n, err := res.RowsAffected(); err == nil {
+			logging.FromContext(ctx).Debug("erased personal data", "table", t.table, "rows", n)
+		}
+	}
+	return nil
+}
+
+// maskToken keeps the last four characters of a payment token.
+func maskToken(token string) string {
+	if len(token) <= 4 {
+		return "****"
+	}
+	return "****" + token[len(token)-4:]
+}
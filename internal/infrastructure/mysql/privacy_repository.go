@@ -0,0 +1,85 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"richisntreal-backend/internal/core/domain/models"
+)
+
+// PrivacyRepository reads and erases a user's personal data as described
+// by the PII registry.
+type PrivacyRepository struct {
+	db *sqlx.DB
+}
+
+func NewPrivacyRepository(db *sqlx.DB) *PrivacyRepository {
+	return &PrivacyRepository{db: db}
+}
+
+// Export collects every exportable section of the registry for a user.
+func (r *PrivacyRepository) Export(ctx context.Context, userID int64) ([]models.DataExportSection, error) {
+	var sections []models.DataExportSection
+	for _, t := range piiRegistry {
+		if t.section == "" {
+			continue
+		}
+		var cols []string
+		for _, c := range t.columns {
+			if !c.secret {
+				cols = append(cols, c.name)
+			}
+		}
+		query := fmt.Sprintf(`SELECT %s FROM %s WHERE %s`, strings.Join(cols, ", "), t.table, t.owner)
+		rows, err := r.db.QueryxContext(ctx, query, t.ownerArgs(userID)...)
+		if err != nil {
+			return nil, fmt.Errorf("PrivacyRepository.Export %s: %w", t.table, err)
+		}
+
+		section := models.DataExportSection{Name: t.section, Rows: []map[string]interface{}{}}
+		for rows.Next() {
+			row := map[string]interface{}{}
+			if err := rows.MapScan(row); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("PrivacyRepository.Export %s: %w", t.table, err)
+			}
+			for _, c := range t.columns {
+				v, ok := row[c.name]
+				if !ok {
+					continue
+				}
+				// the driver hands back text and decimals as bytes
+				if b, isBytes := v.([]byte); isBytes {
+					v = string(b)
+				}
+				if s, isString := v.(string); isString && c.mask != nil {
+					v = c.mask(s)
+				}
+				row[c.name] = v
+			}
+			section.Rows = append(section.Rows, row)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("PrivacyRepository.Export %s: %w", t.table, err)
+		}
+		sections = append(sections, section)
+	}
+	return sections, nil
+}
+
+// Erase scrubs or deletes a user's personal data in one transaction.
+func (r *PrivacyRepository) Erase(ctx context.Context, userID int64) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := erasePII(ctx, tx, userID); err != nil {
+		return fmt.Errorf("PrivacyRepository.Erase: %w", err)
+	}
+	return tx.Commit()
+}
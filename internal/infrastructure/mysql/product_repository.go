@@ -1,6 +1,7 @@
 package mysql
 
 import (
+	"context"
 	"database/sql"
 
 	"github.com/jmoiron/sqlx"
@@ -16,19 +17,19 @@ func NewProductRepository(db *sqlx.DB) *ProductRepository {
 	return &ProductRepository{db: db}
 }
 
-func (r *ProductRepository) FindAll() ([]*models.Product, error) {
+func (r *ProductRepository) FindAll(ctx context.Context) ([]*models.Product, error) {
 	var prods []*models.Product
-	err := r.db.Select(&prods, `
-        SELECT id, name, description, price, sku, created_at, updated_at
+	err := r.db.SelectContext(ctx, &prods, `
+        SELECT id, name, description, price, sku, stock, created_at, updated_at
           FROM products
     `)
 	return prods, err
 }
 
-func (r *ProductRepository) FindByID(id int64) (*models.Product, error) {
+func (r *ProductRepository) FindByID(ctx context.Context, id int64) (*models.Product, error) {
 	var p models.Product
-	err := r.db.Get(&p, `
-        SELECT id, name, description, price, sku, created_at, updated_at
+	err := r.db.GetContext(ctx, &p, `
+        SELECT id, name, description, price, sku, stock, created_at, updated_at
           FROM products
          WHERE id = ?
     `, id)
@@ -41,27 +42,66 @@ func (r *ProductRepository) FindByID(id int64) (*models.Product, error) {
 	return &p, nil
 }
 
-func (r *ProductRepository) Create(p *models.Product) (int64, error) {
-	res, err := r.db.Exec(`
-        INSERT INTO products (name, description, price, sku, created_at, updated_at)
-        VALUES (?, ?, ?, ?, NOW(), NOW())
-    `, p.Name, p.Description, p.Price, p.SKU)
+func (r *ProductRepository) Create(ctx context.Context, p *models.Product) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `
+        INSERT INTO products (name, description, price, sku, stock, created_at, updated_at)
+        VALUES (?, ?, ?, ?, ?, NOW(), NOW())
+    `, p.Name, p.Description, p.Price, p.SKU, p.Stock)
 	if err != nil {
 		return 0, err
 	}
 	return res.LastInsertId()
 }
 
-func (r *ProductRepository) Update(p *models.Product) error {
-	_, err := r.db.Exec(`
+func (r *ProductRepository) Update(ctx context.Context, p *models.Product) error {
+	_, err := r.db.ExecContext(ctx, `
         UPDATE products
-           SET name = ?, description = ?, price = ?, sku = ?, updated_at = NOW()
+           SET name = ?, description = ?, price = ?, sku = ?, stock = ?, updated_at = NOW()
          WHERE id = ?
-    `, p.Name, p.Description, p.Price, p.SKU, p.ID)
+    `, p.Name, p.Description, p.Price, p.SKU, p.Stock, p.ID)
 	return err
 }
 
-func (r *ProductRepository) Delete(id int64) error {
-	_, err := r.db.Exec(`DELETE FROM products WHERE id = ?`, id)
+func (r *ProductRepository) Delete(ctx context.Context, id int64) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM products WHERE id = ?`, id)
+	return err
+}
+
+// ReserveStock atomically takes qty units out of stock. It reports false when
+// the product does not exist or does not have enough units left; products
+// without tracked stock always succeed.
+func (r *ProductRepository) ReserveStock(ctx context.Context, productID int64, qty int) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `
+        UPDATE products
+           SET stock = stock - ?, updated_at = NOW()
+         WHERE id = ? AND stock >= ?
+    `, qty, productID, qty)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil || n > 0 {
+		return n > 0, err
+	}
+
+	// nothing reserved: either short on stock, missing, or untracked
+	var stock sql.NullInt64
+	err = r.db.GetContext(ctx, &stock, `SELECT stock FROM products WHERE id = ?`, productID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, err
+	}
+	return !stock.Valid, nil
+}
+
+// ReleaseStock puts qty previously reserved units back into stock.
+func (r *ProductRepository) ReleaseStock(ctx context.Context, productID int64, qty int) error {
+	_, err := r.db.ExecContext(ctx, `
+        UPDATE products
+           SET stock = stock + ?, updated_at = NOW()
+         WHERE id = ? AND stock IS NOT NULL
+    `, qty, productID)
 	return err
 }
@@ -0,0 +1,118 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+	"richisntreal-backend/internal/core/domain/models"
+)
+
+// ReturnRepository implements persistence for return requests.
+type ReturnRepository struct {
+	db *sqlx.DB
+}
+
+func NewReturnRepository(db *sqlx.DB) *ReturnRepository {
+	return &ReturnRepository{db: db}
+}
+
+func (r *ReturnRepository) Create(ctx context.Context, rr *models.ReturnRequest) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `
+        INSERT INTO return_requests (order_id, user_id, status, reason, created_at, updated_at)
+        VALUES (?, ?, ?, ?, NOW(), NOW())
+    `, rr.OrderID, rr.UserID, rr.Status, rr.Reason)
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+func (r *ReturnRepository) CreateItem(ctx context.Context, item *models.ReturnItem) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `
+        INSERT INTO return_items (return_id, order_item_id, quantity, created_at, updated_at)
+        VALUES (?, ?, ?, NOW(), NOW())
+    `, item.ReturnID, item.OrderItemID, item.Quantity)
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+// Update stores a return that was read in status from. It reports false,
+// changing nothing, when the return has moved on since.
+func (r *ReturnRepository) Update(ctx context.Context, rr *models.ReturnRequest, from string) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `
+        UPDATE return_requests
+           SET status = ?, resolution = ?, admin_note = ?, refund_amount = ?, updated_at = NOW()
+         WHERE id = ? AND status = ?
+    `, rr.Status, rr.Resolution, rr.AdminNote, rr.RefundAmount, rr.ID, from)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+func (r *ReturnRepository) FindByID(ctx context.Context, id int64) (*models.ReturnRequest, error) {
+	var rr models.ReturnRequest
+	if err := r.db.GetContext(ctx, &rr, `
+        SELECT id, order_id, user_id, status, reason, resolution, admin_note, refund_amount, created_at, updated_at
+          FROM return_requests
+         WHERE id = ?
+    `, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if err := r.loadItems(ctx, &rr); err != nil {
+		return nil, err
+	}
+	return &rr, nil
+}
+
+func (r *ReturnRepository) FindByOrder(ctx context.Context, orderID int64) ([]*models.ReturnRequest, error) {
+	var returns []*models.ReturnRequest
+	if err := r.db.SelectContext(ctx, &returns, `
+        SELECT id, order_id, user_id, status, reason, resolution, admin_note, refund_amount, created_at, updated_at
+          FROM return_requests
+         WHERE order_id = ?
+         ORDER BY id
+    `, orderID); err != nil {
+		return nil, err
+	}
+	for _, rr := range returns {
+		if err := r.loadItems(ctx, rr); err != nil {
+			return nil, err
+		}
+	}
+	return returns, nil
+}
+
+// FindAll lists return requests, newest first, optionally narrowed to one status.
+func (r *ReturnRepository) FindAll(ctx context.Context, status string) ([]*models.ReturnRequest, error) {
+	var returns []*models.ReturnRequest
+	if err := r.db.SelectContext(ctx, &returns, `
+        SELECT id, order_id, user_id, status, reason, resolution, admin_note, refund_amount, created_at, updated_at
+          FROM return_requests
+         WHERE ? = '' OR status = ?
+         ORDER BY id DESC
+    `, status, status); err != nil {
+		return nil, err
+	}
+	for _, rr := range returns {
+		if err := r.loadItems(ctx, rr); err != nil {
+			return nil, err
+		}
+	}
+	return returns, nil
+}
+
+func (r *ReturnRepository) loadItems(ctx context.Context, rr *models.ReturnRequest) error {
+	return r.db.SelectContext(ctx, &rr.Items, `
+        SELECT id, return_id, order_item_id, quantity, created_at, updated_at
+          FROM return_items
+         WHERE return_id = ?
+    `, rr.ID)
+}
@@ -0,0 +1,126 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+	"richisntreal-backend/internal/core/domain/models"
+)
+
+// SessionRepository implements persistence for login sessions and their refresh tokens.
+type SessionRepository struct {
+	db *sqlx.DB
+}
+
+func NewSessionRepository(db *sqlx.DB) *SessionRepository {
+	return &SessionRepository{db: db}
+}
+
+func (r *SessionRepository) CreateSession(ctx context.Context, s *models.Session) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `
+        INSERT INTO sessions (user_id, impersonator_id, expires_at, created_at, updated_at)
+        VALUES (?, ?, ?, NOW(), NOW())
+    `, s.UserID, s.ImpersonatorID, s.ExpiresAt)
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+func (r *SessionRepository) FindSession(ctx context.Context, id int64) (*models.Session, error) {
+	var s models.Session
+	err := r.db.GetContext(ctx, &s, `
+        SELECT id, user_id, impersonator_id, expires_at, revoked_at, revoked_reason, created_at, updated_at
+          FROM sessions
+         WHERE id = ?
+    `, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *SessionRepository) RevokeSession(ctx context.Context, id int64, reason string) error {
+	_, err := r.db.ExecContext(ctx, `
+        UPDATE sessions
+           SET revoked_at = NOW(), revoked_reason = ?, updated_at = NOW()
+         WHERE id = ? AND revoked_at IS NULL
+    `, reason, id)
+	return err
+}
+
+func (r *SessionRepository) RevokeUserSessions(ctx context.Context, userID int64, reason string) error {
+	_, err := r.db.ExecContext(ctx, `
+        UPDATE sessions
+           SET revoked_at = NOW(), revoked_reason = ?, updated_at = NOW()
+         WHERE user_id = ? AND revoked_at IS NULL
+    `, reason, userID)
+	return err
+}
+
+// RevokeUserSessionsExcept revokes every session of a user but one.
+func (r *SessionRepository) RevokeUserSessionsExcept(ctx context.Context, userID, keepSessionID int64, reason string) error {
+	_, err := r.db.ExecContext(ctx, `
+        UPDATE sessions
+           SET revoked_at = NOW(), revoked_reason = ?, updated_at = NOW()
+         WHERE user_id = ? AND id <> ? AND revoked_at IS NULL
+    `, reason, userID, keepSessionID)
+	return err
+}
+
+// RevokeImpersonatorSessions revokes every session an admin opened as
+// another user.
+func (r *SessionRepository) RevokeImpersonatorSessions(ctx context.Context, adminID int64, reason string) error {
+	_, err := r.db.ExecContext(ctx, `
+        UPDATE sessions
+           SET revoked_at = NOW(), revoked_reason = ?, updated_at = NOW()
+         WHERE impersonator_id = ? AND revoked_at IS NULL
+    `, reason, adminID)
+	return err
+}
+
+func (r *SessionRepository) CreateRefreshToken(ctx context.Context, t *models.RefreshToken) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `
+        INSERT INTO refresh_tokens (session_id, token_hash, expires_at, created_at)
+        VALUES (?, ?, ?, NOW())
+    `, t.SessionID, t.TokenHash, t.ExpiresAt)
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+func (r *SessionRepository) FindRefreshToken(ctx context.Context, tokenHash string) (*models.RefreshToken, error) {
+	var t models.RefreshToken
+	err := r.db.GetContext(ctx, &t, `
+        SELECT id, session_id, token_hash, expires_at, rotated_at, created_at
+          FROM refresh_tokens
+         WHERE token_hash = ?
+    `, tokenHash)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &t, nil
+}
+
+// MarkRefreshTokenRotated flags a token as exchanged. It reports false when
+// another request already rotated it.
+func (r *SessionRepository) MarkRefreshTokenRotated(ctx context.Context, id int64) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `
+        UPDATE refresh_tokens
+           SET rotated_at = NOW()
+         WHERE id = ? AND rotated_at IS NULL
+    `, id)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
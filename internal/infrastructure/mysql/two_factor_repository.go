@@ -0,0 +1,126 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"richisntreal-backend/internal/core/domain/models"
+)
+
+// TwoFactorRepository implements persistence for TOTP secrets and recovery codes.
+type TwoFactorRepository struct {
+	db *sqlx.DB
+}
+
+func NewTwoFactorRepository(db *sqlx.DB) *TwoFactorRepository {
+	return &TwoFactorRepository{db: db}
+}
+
+func (r *TwoFactorRepository) FindTOTP(ctx context.Context, userID int64) (*models.TOTPSecret, error) {
+	var t models.TOTPSecret
+	err := r.db.GetContext(ctx, &t, `
+    SELECT user_id, secret, last_used_step, enabled_at, created_at, updated_at
+      FROM user_totp
+     WHERE user_id = ?`, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("TwoFactorRepository.FindTOTP: %w", err)
+	}
+	return &t, nil
+}
+
+// SavePendingTOTP stores a new, not yet confirmed secret, replacing any
+// earlier pending one.
+func (r *TwoFactorRepository) SavePendingTOTP(ctx context.Context, userID int64, secret string) error {
+	_, err := r.db.ExecContext(ctx, `
+    INSERT INTO user_totp (user_id, secret, last_used_step, enabled_at, created_at, updated_at)
+    VALUES (?, ?, 0, NULL, NOW(), NOW())
+    ON DUPLICATE KEY UPDATE secret = VALUES(secret), last_used_step = 0, enabled_at = NULL, updated_at = NOW()`,
+		userID, secret)
+	if err != nil {
+		return fmt.Errorf("TwoFactorRepository.SavePendingTOTP: %w", err)
+	}
+	return nil
+}
+
+func (r *TwoFactorRepository) EnableTOTP(ctx context.Context, userID int64) error {
+	_, err := r.db.ExecContext(ctx, `
+    UPDATE user_totp
+       SET enabled_at = NOW(), updated_at = NOW()
+     WHERE user_id = ?`, userID)
+	if err != nil {
+		return fmt.Errorf("TwoFactorRepository.EnableTOTP: %w", err)
+	}
+	return nil
+}
+
+// AdvanceTOTPStep records that the code of a time step was used. It
+// reports false when that step, or a later one, was used already.
+func (r *TwoFactorRepository) AdvanceTOTPStep(ctx context.Context, userID, step int64) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `
+    UPDATE user_totp
+       SET last_used_step = ?, updated_at = NOW()
+     WHERE user_id = ? AND last_used_step < ?`, step, userID, step)
+	if err != nil {
+		return false, fmt.Errorf("TwoFactorRepository.AdvanceTOTPStep: %w", err)
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// Delete removes the TOTP secret and every recovery code of a user.
+func (r *TwoFactorRepository) Delete(ctx context.Context, userID int64) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_recovery_codes WHERE user_id = ?`, userID); err != nil {
+		return fmt.Errorf("TwoFactorRepository.Delete: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_totp WHERE user_id = ?`, userID); err != nil {
+		return fmt.Errorf("TwoFactorRepository.Delete: %w", err)
+	}
+	return tx.Commit()
+}
+
+// ReplaceRecoveryCodes swaps every recovery code of a user for a new set.
+func (r *TwoFactorRepository) ReplaceRecoveryCodes(ctx context.Context, userID int64, codeHashes []string) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_recovery_codes WHERE user_id = ?`, userID); err != nil {
+		return fmt.Errorf("TwoFactorRepository.ReplaceRecoveryCodes: %w", err)
+	}
+	for _, h := range codeHashes {
+		if _, err := tx.ExecContext(ctx, `
+        INSERT INTO user_recovery_codes (user_id, code_hash, created_at)
+        VALUES (?, ?, NOW())`, userID, h); err != nil {
+			return fmt.Errorf("TwoFactorRepository.ReplaceRecoveryCodes: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// UseRecoveryCode burns a recovery code. It reports false when the code is
+// unknown or was used already.
+func (r *TwoFactorRepository) UseRecoveryCode(ctx context.Context, userID int64, codeHash string) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `
+    UPDATE user_recovery_codes
+       SET used_at = NOW()
+     WHERE user_id = ? AND code_hash = ? AND used_at IS NULL`, userID, codeHash)
+	if err != nil {
+		return false, fmt.Errorf("TwoFactorRepository.UseRecoveryCode: %w", err)
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
@@ -2,10 +2,12 @@
 package mysql
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
 	"richisntreal-backend/internal/core/domain/models"
+	"strings"
 	"time"
 
 	"github.com/jmoiron/sqlx"
@@ -20,23 +22,23 @@ func NewUserRepository(db *sqlx.DB) *UserRepository {
 	return &UserRepository{db: db}
 }
 
-func (r *UserRepository) ExistsByEmail(email string) (bool, error) {
+func (r *UserRepository) ExistsByEmail(ctx context.Context, email string) (bool, error) {
 	var count int
-	err := r.db.Get(&count, `SELECT COUNT(1) FROM users WHERE email = ?`, email)
+	err := r.db.GetContext(ctx, &count, `SELECT COUNT(1) FROM users WHERE email = ?`, email)
 	return count > 0, err
 }
 
-func (r *UserRepository) Create(user *models.User) (int64, error) {
+func (r *UserRepository) Create(ctx context.Context, user *models.User) (int64, error) {
 	now := time.Now()
 	user.CreatedAt = now
 	user.UpdatedAt = now
 
 	query := `
     INSERT INTO users
-        (username, email, password, first_name, last_name, country, date_of_birth, created_at, updated_at)
+        (username, email, password, first_name, last_name, country, date_of_birth, role, created_at, updated_at)
     VALUES
-        (?, ?, ?, ?, ?, ?, ?, ?, ?)`
-	res, err := r.db.Exec(
+        (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	res, err := r.db.ExecContext(ctx,
 		query,
 		user.Username,
 		user.Email,
@@ -45,6 +47,7 @@ func (r *UserRepository) Create(user *models.User) (int64, error) {
 		user.LastName,
 		user.Country,
 		user.DateOfBirth,
+		user.Role,
 		user.CreatedAt,
 		user.UpdatedAt,
 	)
@@ -54,16 +57,16 @@ func (r *UserRepository) Create(user *models.User) (int64, error) {
 	return res.LastInsertId()
 }
 
-func (r *UserRepository) FindByEmail(email string) (*models.User, error) {
+func (r *UserRepository) FindByEmail(ctx context.Context, email string) (*models.User, error) {
 	var u models.User
 	query := `
     SELECT id, username, email, password,
            first_name, last_name, country, date_of_birth,
-           created_at, updated_at
+           role, email_verified_at, disabled_at, deleted_at, created_at, updated_at
       FROM users
      WHERE email = ?
      LIMIT 1`
-	err := r.db.Get(&u, query, email)
+	err := r.db.GetContext(ctx, &u, query, email)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil
@@ -73,15 +76,15 @@ func (r *UserRepository) FindByEmail(email string) (*models.User, error) {
 	return &u, nil
 }
 
-func (r *UserRepository) FindByID(id int64) (*models.User, error) {
+func (r *UserRepository) FindByID(ctx context.Context, id int64) (*models.User, error) {
 	var u models.User
 	query := `
     SELECT id, username, email, password,
            first_name, last_name, country, date_of_birth,
-           created_at, updated_at
+           role, email_verified_at, disabled_at, deleted_at, created_at, updated_at
       FROM users
      WHERE id = ?`
-	err := r.db.Get(&u, query, id)
+	err := r.db.GetContext(ctx, &u, query, id)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil
@@ -90,3 +93,126 @@ func (r *UserRepository) FindByID(id int64) (*models.User, error) {
 	}
 	return &u, nil
 }
+
+func (r *UserRepository) UpdatePassword(ctx context.Context, id int64, passwordHash string) error {
+	_, err := r.db.ExecContext(ctx, `
+    UPDATE users
+       SET password = ?, updated_at = NOW()
+     WHERE id = ?`, passwordHash, id)
+	if err != nil {
+		return fmt.Errorf("UserRepository.UpdatePassword: %w", err)
+	}
+	return nil
+}
+
+func (r *UserRepository) MarkEmailVerified(ctx context.Context, id int64) error {
+	_, err := r.db.ExecContext(ctx, `
+    UPDATE users
+       SET email_verified_at = COALESCE(email_verified_at, NOW()), updated_at = NOW()
+     WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("UserRepository.MarkEmailVerified: %w", err)
+	}
+	return nil
+}
+
+func (r *UserRepository) UpdateProfile(ctx context.Context, user *models.User) error {
+	_, err := r.db.ExecContext(ctx, `
+    UPDATE users
+       SET first_name = ?, last_name = ?, country = ?, date_of_birth = ?, updated_at = NOW()
+     WHERE id = ?`, user.FirstName, user.LastName, user.Country, user.DateOfBirth, user.ID)
+	if err != nil {
+		return fmt.Errorf("UserRepository.UpdateProfile: %w", err)
+	}
+	return nil
+}
+
+// UpdateEmail switches a user to an address they have just verified.
+func (r *UserRepository) UpdateEmail(ctx context.Context, id int64, email string) error {
+	_, err := r.db.ExecContext(ctx, `
+    UPDATE users
+       SET email = ?, email_verified_at = NOW(), updated_at = NOW()
+     WHERE id = ?`, email, id)
+	if err != nil {
+		return fmt.Errorf("UserRepository.UpdateEmail: %w", err)
+	}
+	return nil
+}
+
+// SetDisabled disables or re-enables an account.
+func (r *UserRepository) SetDisabled(ctx context.Context, id int64, disabled bool) error {
+	_, err := r.db.ExecContext(ctx, `
+    UPDATE users
+       SET disabled_at = IF(?, COALESCE(disabled_at, NOW()), NULL), updated_at = NOW()
+     WHERE id = ?`, disabled, id)
+	if err != nil {
+		return fmt.Errorf("UserRepository.SetDisabled: %w", err)
+	}
+	return nil
+}
+
+// Search pages through users matching the filter, newest first, and
+// reports how many match in total. Deleted accounts are left out.
+func (r *UserRepository) Search(ctx context.Context, f models.UserFilter) ([]*models.User, int, error) {
+	where := []string{"deleted_at IS NULL"}
+	var args []interface{}
+	if f.Query != "" {
+		like := "%" + escapeLike(f.Query) + "%"
+		where = append(where, "(email LIKE ? OR username LIKE ? OR CONCAT(first_name, ' ', last_name) LIKE ?)")
+		args = append(args, like, like, like)
+	}
+	if f.Role != "" {
+		where = append(where, "role = ?")
+		args = append(args, f.Role)
+	}
+	if f.Disabled != nil {
+		if *f.Disabled {
+			where = append(where, "disabled_at IS NOT NULL")
+		} else {
+			where = append(where, "disabled_at IS NULL")
+		}
+	}
+	cond := "WHERE " + strings.Join(where, " AND ")
+
+	var total int
+	if err := r.db.GetContext(ctx, &total, `SELECT COUNT(1) FROM users `+cond, args...); err != nil {
+		return nil, 0, fmt.Errorf("UserRepository.Search: %w", err)
+	}
+
+	query := `
+    SELECT id, username, email, password,
+           first_name, last_name, country, date_of_birth,
+           role, email_verified_at, disabled_at, deleted_at, created_at, updated_at
+      FROM users ` + cond + `
+     ORDER BY created_at DESC, id DESC`
+	if f.Limit > 0 {
+		query += ` LIMIT ? OFFSET ?`
+		args = append(args, f.Limit, f.Offset)
+	}
+	var users []*models.User
+	if err := r.db.SelectContext(ctx, &users, query, args...); err != nil {
+		return nil, 0, fmt.Errorf("UserRepository.Search: %w", err)
+	}
+	return users, total, nil
+}
+
+// escapeLike makes s match literally inside a LIKE pattern.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// Anonymise deletes an account without deleting its row: orders, invoices
+// and payments keep pointing at it for accounting, while everything the PII
+// registry lists is scrubbed.
+func (r *UserRepository) Anonymise(ctx context.Context, id int64) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := erasePII(ctx, tx, id); err != nil {
+		return fmt.Errorf("UserRepository.Anonymise: %w", err)
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,71 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"richisntreal-backend/internal/core/domain/models"
+)
+
+// UserTokenRepository implements persistence for mailed single-use tokens.
+type UserTokenRepository struct {
+	db *sqlx.DB
+}
+
+func NewUserTokenRepository(db *sqlx.DB) *UserTokenRepository {
+	return &UserTokenRepository{db: db}
+}
+
+func (r *UserTokenRepository) Create(ctx context.Context, t *models.UserToken) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `
+    INSERT INTO user_tokens (user_id, purpose, token_hash, payload, expires_at, created_at)
+    VALUES (?, ?, ?, ?, ?, NOW())`, t.UserID, t.Purpose, t.TokenHash, t.Payload, t.ExpiresAt)
+	if err != nil {
+		return 0, fmt.Errorf("UserTokenRepository.Create: %w", err)
+	}
+	return res.LastInsertId()
+}
+
+func (r *UserTokenRepository) FindByHash(ctx context.Context, purpose, tokenHash string) (*models.UserToken, error) {
+	var t models.UserToken
+	err := r.db.GetContext(ctx, &t, `
+    SELECT id, user_id, purpose, token_hash, payload, expires_at, used_at, created_at
+      FROM user_tokens
+     WHERE purpose = ? AND token_hash = ?`, purpose, tokenHash)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("UserTokenRepository.FindByHash: %w", err)
+	}
+	return &t, nil
+}
+
+// MarkUsed redeems a token. It reports false when it was already used.
+func (r *UserTokenRepository) MarkUsed(ctx context.Context, id int64) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `
+    UPDATE user_tokens
+       SET used_at = NOW()
+     WHERE id = ? AND used_at IS NULL`, id)
+	if err != nil {
+		return false, fmt.Errorf("UserTokenRepository.MarkUsed: %w", err)
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// InvalidateOutstanding burns every unused token of a user for a purpose,
+// so only the most recently mailed one works.
+func (r *UserTokenRepository) InvalidateOutstanding(ctx context.Context, userID int64, purpose string) error {
+	_, err := r.db.ExecContext(ctx, `
+    UPDATE user_tokens
+       SET used_at = NOW()
+     WHERE user_id = ? AND purpose = ? AND used_at IS NULL`, userID, purpose)
+	if err != nil {
+		return fmt.Errorf("UserTokenRepository.InvalidateOutstanding: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,259 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// mockIssuer is a minimal OpenID provider: discovery, a JWKS with one RSA
+// key, and a token endpoint that redeems codes handed out by authorize
+// only against the matching PKCE verifier.
+type mockIssuer struct {
+	t   *testing.T
+	srv *httptest.Server
+	key *rsa.PrivateKey
+
+	mu    sync.Mutex
+	codes map[string]issuedCode
+
+	// tweak lets a test change the ID token's claims before it is signed.
+	tweak func(claims jwt.MapClaims)
+}
+
+type issuedCode struct {
+	challenge string
+	nonce     string
+}
+
+func newMockIssuer(t *testing.T) *mockIssuer {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := &mockIssuer{t: t, key: key, codes: make(map[string]issuedCode)}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(discovery{
+			Issuer:                m.srv.URL,
+			AuthorizationEndpoint: m.srv.URL + "/authorize",
+			TokenEndpoint:         m.srv.URL + "/token",
+			JWKSURI:               m.srv.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		b64 := base64.RawURLEncoding
+		_ = json.NewEncoder(w).Encode(map[string]any{"keys": []jwk{{
+			Kty: "RSA",
+			Kid: "k1",
+			Use: "sig",
+			N:   b64.EncodeToString(key.N.Bytes()),
+			E:   b64.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", m.token)
+	m.srv = httptest.NewServer(mux)
+	t.Cleanup(m.srv.Close)
+	return m
+}
+
+// authorize plays the browser leg: it reads the parameters AuthCodeURL put
+// on the authorization URL and returns the code the provider would send
+// back to the redirect URL.
+func (m *mockIssuer) authorize(authURL string) string {
+	m.t.Helper()
+	u, err := url.Parse(authURL)
+	if err != nil {
+		m.t.Fatal(err)
+	}
+	q := u.Query()
+	if got := q.Get("code_challenge_method"); got != "S256" {
+		m.t.Fatalf("code_challenge_method = %q, want S256", got)
+	}
+	code := "code-" + q.Get("state")
+	m.mu.Lock()
+	m.codes[code] = issuedCode{challenge: q.Get("code_challenge"), nonce: q.Get("nonce")}
+	m.mu.Unlock()
+	return code
+}
+
+func (m *mockIssuer) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	m.mu.Lock()
+	issued, ok := m.codes[r.PostForm.Get("code")]
+	delete(m.codes, r.PostForm.Get("code"))
+	m.mu.Unlock()
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if !ok || base64.RawURLEncoding.EncodeToString(sum[:]) != issued.challenge {
+		http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":            m.srv.URL,
+		"aud":            "client-1",
+		"sub":            "subject-1",
+		"iat":            now.Unix(),
+		"exp":            now.Add(time.Minute).Unix(),
+		"nonce":          issued.nonce,
+		"email":          "ada@example.com",
+		"email_verified": true,
+		"given_name":     "Ada",
+	}
+	if m.tweak != nil {
+		m.tweak(claims)
+	}
+	tok := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	tok.Header["kid"] = "k1"
+	signed, err := tok.SignedString(m.key)
+	if err != nil {
+		m.t.Error(err)
+		return
+	}
+	_ = json.NewEncoder(w).Encode(map[string]string{"id_token": signed})
+}
+
+func (m *mockIssuer) provider(t *testing.T) *Provider {
+	t.Helper()
+	p, err := NewProvider(Config{
+		Name:        "mock",
+		Issuer:      m.srv.URL,
+		ClientID:    "client-1",
+		RedirectURL: "https://shop.example/auth/oidc/mock/callback",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func challengeFor(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func TestAuthCodeURLUsesDiscoveredEndpoint(t *testing.T) {
+	m := newMockIssuer(t)
+	p := m.provider(t)
+
+	raw, err := p.AuthCodeURL(context.Background(), "st", "nn", "ch")
+	if err != nil {
+		t.Fatalf("AuthCodeURL: %v", err)
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := u.Scheme + "://" + u.Host + u.Path; got != m.srv.URL+"/authorize" {
+		t.Errorf("authorization endpoint = %s, want %s/authorize", got, m.srv.URL)
+	}
+	q := u.Query()
+	want := map[string]string{
+		"response_type":         "code",
+		"client_id":             "client-1",
+		"redirect_uri":          "https://shop.example/auth/oidc/mock/callback",
+		"scope":                 "openid email profile",
+		"state":                 "st",
+		"nonce":                 "nn",
+		"code_challenge":        "ch",
+		"code_challenge_method": "S256",
+	}
+	for k, v := range want {
+		if got := q.Get(k); got != v {
+			t.Errorf("%s = %q, want %q", k, got, v)
+		}
+	}
+}
+
+func TestDiscoveryRejectsIssuerMismatch(t *testing.T) {
+	m := newMockIssuer(t)
+	// the document is found under this issuer but names it without the slash
+	p, err := NewProvider(Config{
+		Name:        "mock",
+		Issuer:      m.srv.URL + "/",
+		ClientID:    "client-1",
+		RedirectURL: "https://shop.example/cb",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.AuthCodeURL(context.Background(), "st", "nn", "ch"); err == nil || !strings.Contains(err.Error(), "does not match") {
+		t.Fatalf("AuthCodeURL error = %v, want issuer mismatch", err)
+	}
+}
+
+func TestExchange(t *testing.T) {
+	tests := []struct {
+		name     string
+		verifier string // sent to the token endpoint; the challenge is for "verifier"
+		nonce    string // expected by the relying party; the issuer echoes "nonce"
+		tweak    func(jwt.MapClaims)
+		wantErr  string
+	}{
+		{name: "valid", verifier: "verifier", nonce: "nonce"},
+		{name: "wrong PKCE verifier", verifier: "other", nonce: "nonce", wantErr: "token endpoint returned 400"},
+		{name: "nonce mismatch", verifier: "verifier", nonce: "replayed", wantErr: "nonce mismatch"},
+		{
+			name: "wrong audience", verifier: "verifier", nonce: "nonce",
+			tweak:   func(c jwt.MapClaims) { c["aud"] = "someone-else" },
+			wantErr: "audience mismatch",
+		},
+		{
+			name: "wrong issuer", verifier: "verifier", nonce: "nonce",
+			tweak:   func(c jwt.MapClaims) { c["iss"] = "https://evil.example" },
+			wantErr: "issuer mismatch",
+		},
+		{
+			name: "expired", verifier: "verifier", nonce: "nonce",
+			tweak:   func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Hour).Unix() },
+			wantErr: "expired",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newMockIssuer(t)
+			m.tweak = tt.tweak
+			p := m.provider(t)
+			ctx := context.Background()
+
+			authURL, err := p.AuthCodeURL(ctx, "state-1", "nonce", challengeFor("verifier"))
+			if err != nil {
+				t.Fatalf("AuthCodeURL: %v", err)
+			}
+			code := m.authorize(authURL)
+
+			ext, err := p.Exchange(ctx, code, tt.verifier, tt.nonce)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Exchange error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Exchange: %v", err)
+			}
+			if ext.Provider != "mock" || ext.Subject != "subject-1" || ext.Email != "ada@example.com" || !ext.EmailVerified || ext.GivenName != "Ada" {
+				t.Errorf("identity = %+v", ext)
+			}
+		})
+	}
+}
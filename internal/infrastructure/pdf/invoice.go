@@ -0,0 +1,137 @@
+package pdf
+
+import (
+	"fmt"
+	"strings"
+
+	"richisntreal-backend/internal/core/domain/models"
+)
+
+const (
+	marginLeft   = 50.0
+	marginRight  = PageWidth - 50.0
+	marginBottom = 90.0
+	lineHeight   = 16.0
+)
+
+// InvoiceRenderer renders invoice documents as PDF.
+type InvoiceRenderer struct {
+	seller string
+}
+
+// NewInvoiceRenderer constructs a renderer that prints seller in the header.
+func NewInvoiceRenderer(seller string) *InvoiceRenderer {
+	return &InvoiceRenderer{seller: seller}
+}
+
+// RenderInvoice lays out the invoice header, billing address, line items,
+// total and payment details, continuing on new pages as needed.
+func (r *InvoiceRenderer) RenderInvoice(doc *models.InvoiceDocument) ([]byte, error) {
+	pdf := New()
+	pdf.AddPage()
+	currency := "USD"
+	if doc.Payment != nil && doc.Payment.Currency != "" {
+		currency = strings.ToUpper(doc.Payment.Currency)
+	}
+
+	// 1) header
+	y := PageHeight - 60
+	pdf.Text(marginLeft, y, HelveticaBold, 22, "INVOICE")
+	pdf.Text(marginRight-150, y, HelveticaBold, 12, r.seller)
+	y -= 30
+	pdf.Text(marginLeft, y, Helvetica, 10, "Invoice number: "+doc.Invoice.Reference())
+	y -= lineHeight
+	pdf.Text(marginLeft, y, Helvetica, 10, "Invoice date: "+doc.Invoice.IssuedAt.Format("2006-01-02"))
+	y -= lineHeight
+	pdf.Text(marginLeft, y, Helvetica, 10, "Order: "+doc.Order.Reference)
+	y -= lineHeight
+	pdf.Text(marginLeft, y, Helvetica, 10, "Order date: "+doc.Order.CreatedAt.Format("2006-01-02"))
+
+	// 2) billing address
+	y -= 30
+	pdf.Text(marginLeft, y, HelveticaBold, 11, "Bill to")
+	for _, l := range addressLines(doc.BillingAddress) {
+		y -= lineHeight
+		pdf.Text(marginLeft, y, Helvetica, 10, l)
+	}
+
+	// 3) line items
+	y -= 30
+	header := func() {
+		pdf.Text(marginLeft, y, HelveticaBold, 10, "Item")
+		pdf.Text(300, y, HelveticaBold, 10, "SKU")
+		pdf.Text(380, y, HelveticaBold, 10, "Qty")
+		pdf.Text(430, y, HelveticaBold, 10, "Unit price")
+		pdf.Text(505, y, HelveticaBold, 10, "Amount")
+		y -= 6
+		pdf.Line(marginLeft, y, marginRight, y)
+	}
+	header()
+	for _, l := range doc.Lines {
+		y -= lineHeight
+		if y < marginBottom {
+			pdf.AddPage()
+			y = PageHeight - 60
+			header()
+			y -= lineHeight
+		}
+		name := l.ProductName
+		if name == "" {
+			name = "(unknown product)"
+		}
+		pdf.Text(marginLeft, y, Helvetica, 10, truncate(name, 45))
+		pdf.Text(300, y, Helvetica, 10, truncate(l.SKU, 14))
+		pdf.TextRight(400, y, 10, fmt.Sprintf("%d", l.Quantity))
+		pdf.TextRight(480, y, 10, money(l.UnitPrice))
+		pdf.TextRight(marginRight, y, 10, money(l.Total))
+	}
+	y -= 8
+	pdf.Line(marginLeft, y, marginRight, y)
+	y -= lineHeight
+	pdf.Text(430, y, HelveticaBold, 11, "Total "+currency)
+	pdf.TextRight(marginRight, y, 11, money(doc.Order.Total))
+
+	// 4) payment
+	if p := doc.Payment; p != nil {
+		if y-4*lineHeight < marginBottom {
+			pdf.AddPage()
+			y = PageHeight - 60
+		}
+		y -= 30
+		pdf.Text(marginLeft, y, HelveticaBold, 11, "Payment")
+		y -= lineHeight
+		pdf.Text(marginLeft, y, Helvetica, 10, fmt.Sprintf("%s %s via %s (%s)",
+			money(p.Amount), strings.ToUpper(p.Currency), p.Provider, p.Status))
+		if p.ProviderTxID != nil {
+			y -= lineHeight
+			pdf.Text(marginLeft, y, Helvetica, 10, "Transaction: "+*p.ProviderTxID)
+		}
+		y -= lineHeight
+		pdf.Text(marginLeft, y, Helvetica, 10, "Paid on: "+p.UpdatedAt.Format("2006-01-02"))
+	}
+
+	return pdf.Bytes(), nil
+}
+
+func addressLines(a *models.Address) []string {
+	if a == nil {
+		return []string{"(no billing address on file)"}
+	}
+	lines := []string{a.Name, a.Line1}
+	if a.Line2 != "" {
+		lines = append(lines, a.Line2)
+	}
+	return append(lines, strings.TrimSpace(a.PostalCode+" "+a.City), a.Country)
+}
+
+func money(v float64) string {
+	return fmt.Sprintf("%.2f", v)
+}
+
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-3]) + "..."
+}
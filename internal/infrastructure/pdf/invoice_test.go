@@ -0,0 +1,94 @@
+package pdf
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"richisntreal-backend/internal/core/domain/models"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+func TestRenderInvoiceGolden(t *testing.T) {
+	issued := time.Date(2025, 3, 14, 9, 30, 0, 0, time.UTC)
+	txID := "ch_3PqZ"
+
+	many := make([]models.InvoiceLine, 60)
+	for i := range many {
+		many[i] = models.InvoiceLine{
+			ProductName: fmt.Sprintf("Sticker #%d", i+1),
+			SKU:         fmt.Sprintf("STK-%03d", i+1),
+			Quantity:    1,
+			UnitPrice:   1.5,
+			Total:       1.5,
+		}
+	}
+
+	tests := []struct {
+		name string
+		doc  *models.InvoiceDocument
+	}{
+		{
+			name: "paid",
+			doc: &models.InvoiceDocument{
+				Invoice: models.Invoice{ID: 1, OrderID: 7, Number: 42, IssuedAt: issued},
+				Order:   models.Order{ID: 7, Reference: "RNR-7K3M-9QXA", Total: 59.97, CreatedAt: issued.Add(-time.Hour)},
+				Lines: []models.InvoiceLine{
+					{ProductName: "Hoodie (black)", SKU: "HD-BLK-M", Quantity: 1, UnitPrice: 49.99, Total: 49.99},
+					{ProductName: "", SKU: "", Quantity: 2, UnitPrice: 4.99, Total: 9.98},
+				},
+				BillingAddress: &models.Address{
+					Name:       "Ada Lovelace",
+					Line1:      "12 St. James's Square",
+					Line2:      "Flat (2)",
+					City:       "London",
+					PostalCode: "SW1Y 4JH",
+					Country:    "GB",
+				},
+				Payment: &models.PaymentTransaction{
+					Amount:       59.97,
+					Currency:     "eur",
+					Provider:     "stripe",
+					ProviderTxID: &txID,
+					Status:       models.PaymentStatusSucceeded,
+				},
+			},
+		},
+		{
+			name: "multi_page",
+			doc: &models.InvoiceDocument{
+				Invoice: models.Invoice{ID: 2, OrderID: 8, Number: 43, IssuedAt: issued},
+				Order:   models.Order{ID: 8, Reference: "RNR-2B4C-6D8E", Total: 90, CreatedAt: issued},
+				Lines:   many,
+			},
+		},
+	}
+
+	r := NewInvoiceRenderer("Richisntreal Ltd")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := r.RenderInvoice(tt.doc)
+			if err != nil {
+				t.Fatalf("RenderInvoice: %v", err)
+			}
+			golden := filepath.Join("testdata", tt.name+".pdf")
+			if *update {
+				if err := os.WriteFile(golden, got, 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("%v (run go test -update to create it)", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("%s differs from the rendered invoice; run go test -update and review the diff", golden)
+			}
+		})
+	}
+}
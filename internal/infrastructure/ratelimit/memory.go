@@ -0,0 +1,77 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// MemoryLimiter is a token bucket per key, held in process memory. Each
+// bucket holds up to limit tokens and refills at limit per period, so
+// bursts of limit requests are fine but the sustained rate is capped.
+// Limits are per instance; several replicas each allow the full rate.
+type MemoryLimiter struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	limit     float64
+	rate      float64 // tokens per second
+	period    time.Duration
+	lastSweep time.Time
+}
+
+type bucket struct {
+	tokens float64
+	seen   time.Time
+}
+
+// NewMemoryLimiter allows limit requests per period for every key.
+func NewMemoryLimiter(limit int, period time.Duration) *MemoryLimiter {
+	return &MemoryLimiter{
+		buckets:   make(map[string]*bucket),
+		limit:     float64(limit),
+		rate:      float64(limit) / period.Seconds(),
+		period:    period,
+		lastSweep: time.Now(),
+	}
+}
+
+// Allow takes a token from key's bucket. When the bucket is empty it
+// reports how long until the next token arrives.
+func (l *MemoryLimiter) Allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.limit, seen: now}
+		l.buckets[key] = b
+	}
+	b.tokens += now.Sub(b.seen).Seconds() * l.rate
+	if b.tokens > l.limit {
+		b.tokens = l.limit
+	}
+	b.seen = now
+
+	if b.tokens < 1 {
+		wait := time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+		return false, wait
+	}
+	b.tokens--
+	return true, 0
+}
+
+// sweep drops buckets that have refilled completely, since a fresh bucket
+// behaves the same. It runs at most once per period.
+func (l *MemoryLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < l.period {
+		return
+	}
+	for key, b := range l.buckets {
+		if now.Sub(b.seen) >= l.period {
+			delete(l.buckets, key)
+		}
+	}
+	l.lastSweep = now
+}
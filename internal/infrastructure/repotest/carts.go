@@ -0,0 +1,84 @@
+package repotest
+
+import (
+	"context"
+
+	"richisntreal-backend/internal/core/domain/models"
+)
+
+func checkCarts(ctx context.Context, c *checker, repos Repositories) error {
+	u, err := newUser(ctx, repos)
+	if err != nil {
+		return err
+	}
+	p, err := newProduct(ctx, repos, nil)
+	if err != nil {
+		return err
+	}
+
+	// 1) A user has no cart until one is created
+	if cart, err := repos.Carts.FindByUserID(ctx, u.ID); err != nil || cart != nil {
+		c.errorf("FindByUserID(new user) = %v, %v; want nil, nil", cart, err)
+	}
+	cartID, err := repos.Carts.CreateCart(ctx, &models.Cart{UserID: u.ID})
+	if err != nil {
+		return err
+	}
+	cart, err := repos.Carts.FindByUserID(ctx, u.ID)
+	if err != nil {
+		return err
+	}
+	if cart == nil || cart.ID != cartID || len(cart.Items) != 0 {
+		c.errorf("FindByUserID after CreateCart = %+v, want empty cart %d", cart, cartID)
+	}
+
+	// 2) Lines are found by id and by product, the way merging looks them up
+	itemID, err := repos.Carts.CreateItem(ctx, &models.CartItem{CartID: cartID, ProductID: p.ID, Quantity: 1, UnitPrice: p.Price})
+	if err != nil {
+		return err
+	}
+	it, err := repos.Carts.FindItemByCartAndProduct(ctx, cartID, p.ID)
+	if err != nil {
+		return err
+	}
+	if it == nil || it.ID != itemID {
+		c.errorf("FindItemByCartAndProduct did not find line %d", itemID)
+	}
+	if got, err := repos.Carts.FindItemByCartAndProduct(ctx, cartID, -1); err != nil || got != nil {
+		c.errorf("FindItemByCartAndProduct(missing) = %v, %v; want nil, nil", got, err)
+	}
+
+	// 3) Updates change the quantity only
+	if err := repos.Carts.UpdateItem(ctx, &models.CartItem{ID: itemID, Quantity: 4, UnitPrice: 999}); err != nil {
+		return err
+	}
+	it, err = repos.Carts.FindItem(ctx, itemID)
+	if err != nil {
+		return err
+	}
+	if it == nil || it.Quantity != 4 || it.UnitPrice != p.Price {
+		c.errorf("after UpdateItem line = %+v, want quantity 4 at %v", it, p.Price)
+	}
+
+	// 4) Lines are deleted one by one or all at once
+	if err := repos.Carts.DeleteItem(ctx, itemID); err != nil {
+		return err
+	}
+	if got, err := repos.Carts.FindItem(ctx, itemID); err != nil || got != nil {
+		c.errorf("FindItem after DeleteItem = %v, %v; want nil, nil", got, err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := repos.Carts.CreateItem(ctx, &models.CartItem{CartID: cartID, ProductID: p.ID, Quantity: 1}); err != nil {
+			return err
+		}
+	}
+	if err := repos.Carts.DeleteItemsByCartID(ctx, cartID); err != nil {
+		return err
+	}
+	if cart, err := repos.Carts.FindByUserID(ctx, u.ID); err != nil {
+		return err
+	} else if cart == nil || len(cart.Items) != 0 {
+		c.errorf("DeleteItemsByCartID left %+v", cart)
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package repotest
+
+import (
+	"context"
+	"strings"
+
+	"richisntreal-backend/internal/core/domain/models"
+)
+
+func checkOrders(ctx context.Context, c *checker, repos Repositories) error {
+	u, err := newUser(ctx, repos)
+	if err != nil {
+		return err
+	}
+	o, err := newOrder(ctx, repos, u)
+	if err != nil {
+		return err
+	}
+
+	// 1) Orders load with their lines and billing address
+	got, err := repos.Orders.FindOrderByID(ctx, o.ID)
+	if err != nil {
+		return err
+	}
+	if got == nil || got.Reference != o.Reference || len(got.Items) != 1 || got.BillingAddress == nil {
+		c.errorf("FindOrderByID = %+v, want order %s with one line and an address", got, o.Reference)
+	}
+	if got, err := repos.Orders.FindOrderByID(ctx, -1); err != nil || got != nil {
+		c.errorf("FindOrderByID(missing) = %v, %v; want nil, nil", got, err)
+	}
+	dup := *o
+	if _, err := repos.Orders.CreateOrder(ctx, &dup); err == nil {
+		c.errorf("CreateOrder with an existing reference succeeded")
+	}
+	if _, err := repos.Orders.CreateAddress(ctx, &models.Address{OrderID: o.ID, Kind: models.AddressKindBilling}); err == nil {
+		c.errorf("CreateAddress for a second billing address succeeded")
+	}
+
+	// 2) Guest lookups need the customer's email, in any case
+	if got, err := repos.Orders.FindOrderByReferenceAndEmail(ctx, o.Reference, strings.ToUpper(u.Email)); err != nil {
+		return err
+	} else if got == nil || got.ID != o.ID {
+		c.errorf("FindOrderByReferenceAndEmail did not find order %d", o.ID)
+	}
+	if got, err := repos.Orders.FindOrderByReferenceAndEmail(ctx, o.Reference, "someone@example.com"); err != nil || got != nil {
+		c.errorf("FindOrderByReferenceAndEmail(wrong email) = %v, %v; want nil, nil", got, err)
+	}
+
+	// 3) Listing and searching by customer
+	orders, err := repos.Orders.FindOrdersByUser(ctx, u.ID)
+	if err != nil {
+		return err
+	}
+	if len(orders) != 1 || orders[0].ID != o.ID {
+		c.errorf("FindOrdersByUser returned %d orders, want 1", len(orders))
+	}
+	_, total, err := repos.Orders.SearchOrders(ctx, models.OrderFilter{UserID: u.ID, Status: models.OrderStatusPending, Limit: 10})
+	if err != nil {
+		return err
+	}
+	if total != 1 {
+		c.errorf("SearchOrders matched %d orders, want 1", total)
+	}
+
+	// 4) A page loads every order's own lines and address, and need not be counted
+	second, err := newOrder(ctx, repos, u)
+	if err != nil {
+		return err
+	}
+	page, total, err := repos.Orders.SearchOrders(ctx, models.OrderFilter{UserID: u.ID, Limit: 10, SkipCount: true})
+	if err != nil {
+		return err
+	}
+	if total != 0 || len(page) != 2 {
+		c.errorf("SearchOrders(SkipCount) = %d orders, total %d; want 2 orders, total 0", len(page), total)
+	}
+	for _, got := range page {
+		if got.ID != o.ID && got.ID != second.ID {
+			continue
+		}
+		if len(got.Items) != 1 || got.Items[0].OrderID != got.ID ||
+			got.BillingAddress == nil || got.BillingAddress.OrderID != got.ID {
+			c.errorf("SearchOrders loaded order %d with %+v, address %+v", got.ID, got.Items, got.BillingAddress)
+		}
+	}
+
+	// 5) Status changes only happen from the expected status
+	if ok, err := repos.Orders.UpdateStatus(ctx, o.ID, models.OrderStatusPaid, models.OrderStatusShipped); err != nil {
+		return err
+	} else if ok {
+		c.errorf("UpdateStatus from the wrong status succeeded")
+	}
+	if ok, err := repos.Orders.UpdateStatus(ctx, o.ID, models.OrderStatusPending, models.OrderStatusPaid); err != nil {
+		return err
+	} else if !ok {
+		c.errorf("UpdateStatus(pending -> paid) = false, want true")
+	}
+
+	// 6) Cancelling works once, while unfulfilled
+	if ok, err := repos.Orders.CancelOrder(ctx, o.ID, "changed mind"); err != nil {
+		return err
+	} else if !ok {
+		c.errorf("CancelOrder on a paid order = false, want true")
+	}
+	if ok, err := repos.Orders.CancelOrder(ctx, o.ID, "again"); err != nil {
+		return err
+	} else if ok {
+		c.errorf("CancelOrder on a cancelled order = true, want false")
+	}
+	got, err = repos.Orders.FindOrderByID(ctx, o.ID)
+	if err != nil {
+		return err
+	}
+	if got.Status != models.OrderStatusCancelled || got.CancelledAt == nil ||
+		got.CancellationReason == nil || *got.CancellationReason != "changed mind" {
+		c.errorf("after CancelOrder order = %+v", got)
+	}
+
+	// 7) Notes come back oldest first, never nil
+	notes, err := repos.Orders.FindNotes(ctx, o.ID)
+	if err != nil {
+		return err
+	}
+	if notes == nil || len(notes) != 0 {
+		c.errorf("FindNotes on a new order = %v, want an empty list", notes)
+	}
+	for _, body := range []string{"first", "second"} {
+		if _, err := repos.Orders.CreateNote(ctx, &models.OrderNote{OrderID: o.ID, AuthorID: u.ID, Body: body}); err != nil {
+			return err
+		}
+	}
+	notes, err = repos.Orders.FindNotes(ctx, o.ID)
+	if err != nil {
+		return err
+	}
+	if len(notes) != 2 || notes[0].Body != "first" {
+		c.errorf("FindNotes returned %d notes, want first and second", len(notes))
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package repotest
+
+import (
+	"context"
+
+	"richisntreal-backend/internal/core/domain/models"
+)
+
+func checkPayments(ctx context.Context, c *checker, repos Repositories) error {
+	u, err := newUser(ctx, repos)
+	if err != nil {
+		return err
+	}
+	o, err := newOrder(ctx, repos, u)
+	if err != nil {
+		return err
+	}
+
+	// 1) An unpaid order has no transaction
+	if got, err := repos.Payments.FindByOrder(ctx, o.ID); err != nil || got != nil {
+		c.errorf("FindByOrder(unpaid) = %v, %v; want nil, nil", got, err)
+	}
+
+	// 2) The latest attempt is the one found
+	var last int64
+	for i := 0; i < 2; i++ {
+		tx := &models.PaymentTransaction{
+			OrderID:  o.ID,
+			Amount:   o.Total,
+			Currency: "EUR",
+			Provider: "stripe",
+			Token:    "tok_contract",
+			Status:   models.PaymentStatusPending,
+		}
+		if last, err = repos.Payments.Create(ctx, tx); err != nil {
+			return err
+		}
+	}
+	got, err := repos.Payments.FindByOrder(ctx, o.ID)
+	if err != nil {
+		return err
+	}
+	if got == nil || got.ID != last || got.ProviderTxID != nil {
+		c.errorf("FindByOrder = %+v, want fresh transaction %d", got, last)
+	}
+
+	// 3) Status and provider id updates stick
+	msg := "card declined"
+	if err := repos.Payments.UpdateStatus(ctx, last, models.PaymentStatusFailed, &msg); err != nil {
+		return err
+	}
+	if err := repos.Payments.UpdateProviderTxID(ctx, last, "pi_contract"); err != nil {
+		return err
+	}
+	got, err = repos.Payments.FindByOrder(ctx, o.ID)
+	if err != nil {
+		return err
+	}
+	if got.Status != models.PaymentStatusFailed || got.FailureMessage == nil || *got.FailureMessage != msg ||
+		got.ProviderTxID == nil || *got.ProviderTxID != "pi_contract" {
+		c.errorf("after updates transaction = %+v", got)
+	}
+
+	// 4) Refunds add up, and the charge is refunded once nothing is left
+	if err := repos.Payments.UpdateStatus(ctx, last, models.PaymentStatusSucceeded, nil); err != nil {
+		return err
+	}
+	for _, step := range []struct {
+		amount   float64
+		status   string
+		refunded float64
+	}{
+		{10.1, models.PaymentStatusPartiallyRefunded, 10.1},
+		{o.Total - 10.1, models.PaymentStatusRefunded, o.Total},
+	} {
+		if err := repos.Payments.AddRefund(ctx, last, step.amount); err != nil {
+			return err
+		}
+		got, err = repos.Payments.FindByOrder(ctx, o.ID)
+		if err != nil {
+			return err
+		}
+		if got.Status != step.status || got.RefundedAmount != step.refunded {
+			c.errorf("after refunding %v: %s with %v refunded, want %s with %v",
+				step.amount, got.Status, got.RefundedAmount, step.status, step.refunded)
+		}
+	}
+	return nil
+}
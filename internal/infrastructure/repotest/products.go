@@ -0,0 +1,80 @@
+package repotest
+
+import (
+	"context"
+)
+
+func checkProducts(ctx context.Context, c *checker, repos Repositories) error {
+	p, err := newProduct(ctx, repos, intPtr(5))
+	if err != nil {
+		return err
+	}
+
+	// 1) Reserving takes from stock only while there is enough
+	if ok, err := repos.Products.ReserveStock(ctx, p.ID, 3); err != nil {
+		return err
+	} else if !ok {
+		c.errorf("ReserveStock(3 of 5) = false, want true")
+	}
+	if ok, err := repos.Products.ReserveStock(ctx, p.ID, 3); err != nil {
+		return err
+	} else if ok {
+		c.errorf("ReserveStock(3 of 2) = true, want false")
+	}
+	if err := checkStock(ctx, c, repos, p.ID, 2); err != nil {
+		return err
+	}
+
+	// 2) Releasing puts units back
+	if err := repos.Products.ReleaseStock(ctx, p.ID, 3); err != nil {
+		return err
+	}
+	if err := checkStock(ctx, c, repos, p.ID, 5); err != nil {
+		return err
+	}
+
+	// 3) Untracked stock always reserves and stays untracked
+	untracked, err := newProduct(ctx, repos, nil)
+	if err != nil {
+		return err
+	}
+	if ok, err := repos.Products.ReserveStock(ctx, untracked.ID, 1000); err != nil {
+		return err
+	} else if !ok {
+		c.errorf("ReserveStock on untracked stock = false, want true")
+	}
+	if err := repos.Products.ReleaseStock(ctx, untracked.ID, 1); err != nil {
+		return err
+	}
+	if got, err := repos.Products.FindByID(ctx, untracked.ID); err != nil {
+		return err
+	} else if got.Stock != nil {
+		c.errorf("untracked stock became %d", *got.Stock)
+	}
+
+	// 4) Missing products reserve nothing and find nothing
+	if ok, err := repos.Products.ReserveStock(ctx, -1, 1); err != nil || ok {
+		c.errorf("ReserveStock(missing) = %v, %v; want false, nil", ok, err)
+	}
+	if got, err := repos.Products.FindByID(ctx, -1); err != nil || got != nil {
+		c.errorf("FindByID(missing) = %v, %v; want nil, nil", got, err)
+	}
+
+	// 5) SKUs are unique
+	dup := *p
+	if _, err := repos.Products.Create(ctx, &dup); err == nil {
+		c.errorf("Create with an existing SKU succeeded")
+	}
+	return nil
+}
+
+func checkStock(ctx context.Context, c *checker, repos Repositories, id int64, want int) error {
+	got, err := repos.Products.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if got == nil || got.Stock == nil || *got.Stock != want {
+		c.errorf("stock of product %d is not %d", id, want)
+	}
+	return nil
+}
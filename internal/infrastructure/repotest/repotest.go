@@ -0,0 +1,146 @@
+// Package repotest is the behaviour every implementation of the service
+// repositories must share, whether it is backed by MySQL or kept in
+// memory. Check runs the whole contract and returns what broke, so it can
+// be driven from a test or against a scratch database alike.
+//
+// The checks only add rows, with unique emails, SKUs and references, so
+// they can run against a database that already holds data.
+package repotest
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"richisntreal-backend/internal/core/domain/models"
+	"richisntreal-backend/internal/core/services"
+)
+
+// Repositories is one implementation of each repository under contract.
+// They must share a backing store: orders point at users and products,
+// and erasing a user reaches into carts, orders and payments.
+type Repositories struct {
+	Users    services.UserRepository
+	Carts    services.CartRepository
+	Products services.ProductRepository
+	Orders   services.OrderRepository
+	Payments services.PaymentRepository
+}
+
+// Check runs every contract check and joins the failures.
+func Check(ctx context.Context, repos Repositories) error {
+	checks := []struct {
+		name string
+		fn   func(context.Context, *checker, Repositories) error
+	}{
+		{"users", checkUsers},
+		{"products", checkProducts},
+		{"carts", checkCarts},
+		{"orders", checkOrders},
+		{"payments", checkPayments},
+		{"anonymise", checkAnonymise},
+	}
+	var errs []error
+	for _, c := range checks {
+		ch := &checker{name: c.name}
+		// an error from fn means the check could not go on; what it found
+		// until then is still reported
+		if err := c.fn(ctx, ch, repos); err != nil {
+			ch.errorf("aborted: %v", err)
+		}
+		errs = append(errs, ch.errs...)
+	}
+	return errors.Join(errs...)
+}
+
+// checker collects the failures of one check.
+type checker struct {
+	name string
+	errs []error
+}
+
+func (c *checker) errorf(format string, args ...interface{}) {
+	c.errs = append(c.errs, fmt.Errorf("%s: %s", c.name, fmt.Sprintf(format, args...)))
+}
+
+// unique returns a short random suffix for values under a UNIQUE index.
+func unique() string {
+	b := make([]byte, 6)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// newUser creates a customer with a fresh email address.
+func newUser(ctx context.Context, repos Repositories) (*models.User, error) {
+	sfx := unique()
+	u := &models.User{
+		Username:  "contract " + sfx,
+		Email:     "contract-" + sfx + "@example.com",
+		Password:  "hash",
+		FirstName: "Contract",
+		LastName:  "Check " + sfx,
+		Country:   "NL",
+		Role:      models.RoleCustomer,
+	}
+	id, err := repos.Users.Create(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+	u.ID = id
+	return u, nil
+}
+
+// newProduct creates a product with a fresh SKU; nil stock is untracked.
+func newProduct(ctx context.Context, repos Repositories, stock *int) (*models.Product, error) {
+	p := &models.Product{
+		Name:  "Contract product",
+		Price: 12.5,
+		SKU:   "CT-" + unique(),
+		Stock: stock,
+	}
+	id, err := repos.Products.Create(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	p.ID = id
+	return p, nil
+}
+
+// newOrder creates a pending order for the user with one line and a
+// billing address.
+func newOrder(ctx context.Context, repos Repositories, u *models.User) (*models.Order, error) {
+	p, err := newProduct(ctx, repos, nil)
+	if err != nil {
+		return nil, err
+	}
+	o := &models.Order{
+		Reference: "CT-" + unique(),
+		UserID:    u.ID,
+		Total:     25,
+		Status:    models.OrderStatusPending,
+	}
+	if o.ID, err = repos.Orders.CreateOrder(ctx, o); err != nil {
+		return nil, err
+	}
+	item := &models.OrderItem{OrderID: o.ID, ProductID: p.ID, Quantity: 2, UnitPrice: p.Price}
+	if _, err := repos.Orders.CreateOrderItem(ctx, item); err != nil {
+		return nil, err
+	}
+	addr := &models.Address{
+		OrderID:    o.ID,
+		Kind:       models.AddressKindBilling,
+		Name:       "Contract Check",
+		Line1:      "1 Main Street",
+		City:       "Amsterdam",
+		PostalCode: "1000 AA",
+		Country:    "NL",
+	}
+	if _, err := repos.Orders.CreateAddress(ctx, addr); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+func intPtr(n int) *int { return &n }
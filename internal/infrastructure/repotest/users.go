@@ -0,0 +1,181 @@
+package repotest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"richisntreal-backend/internal/core/domain/models"
+)
+
+func checkUsers(ctx context.Context, c *checker, repos Repositories) error {
+	u, err := newUser(ctx, repos)
+	if err != nil {
+		return err
+	}
+
+	// 1) Emails match whatever their case, and stay unique that way
+	shouted := strings.ToUpper(u.Email)
+	if ok, err := repos.Users.ExistsByEmail(ctx, shouted); err != nil {
+		return err
+	} else if !ok {
+		c.errorf("ExistsByEmail(%q) = false, want true", shouted)
+	}
+	found, err := repos.Users.FindByEmail(ctx, shouted)
+	if err != nil {
+		return err
+	}
+	if found == nil || found.ID != u.ID {
+		c.errorf("FindByEmail(%q) did not find user %d", shouted, u.ID)
+	}
+	dup := *u
+	dup.Email = shouted
+	if _, err := repos.Users.Create(ctx, &dup); err == nil {
+		c.errorf("Create with an existing email in another case succeeded")
+	}
+
+	// 2) Missing users are nil without an error
+	if got, err := repos.Users.FindByEmail(ctx, "missing-"+unique()+"@example.com"); err != nil || got != nil {
+		c.errorf("FindByEmail(missing) = %v, %v; want nil, nil", got, err)
+	}
+	if got, err := repos.Users.FindByID(ctx, -1); err != nil || got != nil {
+		c.errorf("FindByID(missing) = %v, %v; want nil, nil", got, err)
+	}
+
+	// 3) Verifying twice keeps the first timestamp
+	if err := repos.Users.MarkEmailVerified(ctx, u.ID); err != nil {
+		return err
+	}
+	first, err := repos.Users.FindByID(ctx, u.ID)
+	if err != nil {
+		return err
+	}
+	if first.EmailVerifiedAt == nil {
+		c.errorf("MarkEmailVerified left email_verified_at empty")
+	} else {
+		if err := repos.Users.MarkEmailVerified(ctx, u.ID); err != nil {
+			return err
+		}
+		again, err := repos.Users.FindByID(ctx, u.ID)
+		if err != nil {
+			return err
+		}
+		if again.EmailVerifiedAt == nil || !again.EmailVerifiedAt.Equal(*first.EmailVerifiedAt) {
+			c.errorf("MarkEmailVerified moved email_verified_at on a verified user")
+		}
+	}
+
+	// 4) Disabling toggles and shows up in searches
+	disabled := true
+	if err := repos.Users.SetDisabled(ctx, u.ID, true); err != nil {
+		return err
+	}
+	users, total, err := repos.Users.Search(ctx, models.UserFilter{Query: u.Email, Disabled: &disabled})
+	if err != nil {
+		return err
+	}
+	if total != 1 || len(users) != 1 || users[0].ID != u.ID {
+		c.errorf("Search for the disabled user returned %d of %d", len(users), total)
+	}
+	if err := repos.Users.SetDisabled(ctx, u.ID, false); err != nil {
+		return err
+	}
+	if got, err := repos.Users.FindByID(ctx, u.ID); err != nil {
+		return err
+	} else if got.IsDisabled() {
+		c.errorf("SetDisabled(false) left the user disabled")
+	}
+
+	// 5) Searches match names case-insensitively
+	_, total, err = repos.Users.Search(ctx, models.UserFilter{Query: strings.ToLower(u.FirstName + " " + u.LastName)})
+	if err != nil {
+		return err
+	}
+	if total != 1 {
+		c.errorf("Search by full name matched %d users, want 1", total)
+	}
+	return nil
+}
+
+func checkAnonymise(ctx context.Context, c *checker, repos Repositories) error {
+	u, err := newUser(ctx, repos)
+	if err != nil {
+		return err
+	}
+	o, err := newOrder(ctx, repos, u)
+	if err != nil {
+		return err
+	}
+	pay := &models.PaymentTransaction{
+		OrderID:  o.ID,
+		Amount:   o.Total,
+		Currency: "EUR",
+		Provider: "stripe",
+		Token:    "tok_contract_4242",
+		Status:   models.PaymentStatusPending,
+	}
+	if pay.ID, err = repos.Payments.Create(ctx, pay); err != nil {
+		return err
+	}
+	prod, err := newProduct(ctx, repos, nil)
+	if err != nil {
+		return err
+	}
+	cartID, err := repos.Carts.CreateCart(ctx, &models.Cart{UserID: u.ID})
+	if err != nil {
+		return err
+	}
+	if _, err := repos.Carts.CreateItem(ctx, &models.CartItem{CartID: cartID, ProductID: prod.ID, Quantity: 1, UnitPrice: prod.Price}); err != nil {
+		return err
+	}
+
+	if err := repos.Users.Anonymise(ctx, u.ID); err != nil {
+		return err
+	}
+
+	// 1) The row stays, without anything that identifies the person
+	got, err := repos.Users.FindByID(ctx, u.ID)
+	if err != nil {
+		return err
+	}
+	if got == nil {
+		return fmt.Errorf("user %d is gone after Anonymise", u.ID)
+	}
+	if want := fmt.Sprintf("deleted-%d@invalid", u.ID); got.Email != want {
+		c.errorf("email = %q, want %q", got.Email, want)
+	}
+	if got.FirstName != "" || got.LastName != "" || got.DeletedAt == nil {
+		c.errorf("profile not erased: %+v", got)
+	}
+	if found, err := repos.Users.FindByEmail(ctx, u.Email); err != nil || found != nil {
+		c.errorf("FindByEmail(old email) = %v, %v; want nil, nil", found, err)
+	}
+	if _, total, err := repos.Users.Search(ctx, models.UserFilter{Query: "deleted-" + fmt.Sprint(u.ID)}); err != nil {
+		return err
+	} else if total != 0 {
+		c.errorf("Search still lists the deleted user")
+	}
+
+	// 2) The cart goes; the order and its payment stay, scrubbed
+	if cart, err := repos.Carts.FindByUserID(ctx, u.ID); err != nil || cart != nil {
+		c.errorf("FindByUserID after Anonymise = %v, %v; want nil, nil", cart, err)
+	}
+	ord, err := repos.Orders.FindOrderByID(ctx, o.ID)
+	if err != nil {
+		return err
+	}
+	if ord == nil || ord.BillingAddress == nil {
+		return fmt.Errorf("order %d lost its billing address", o.ID)
+	}
+	if ord.BillingAddress.Line1 != "" || ord.BillingAddress.City == "" {
+		c.errorf("billing address = %+v, want street erased and city kept", ord.BillingAddress)
+	}
+	p, err := repos.Payments.FindByOrder(ctx, o.ID)
+	if err != nil {
+		return err
+	}
+	if p == nil || p.Token != "****4242" {
+		c.errorf("payment token = %v, want ****4242", p)
+	}
+	return nil
+}
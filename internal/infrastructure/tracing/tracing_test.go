@@ -0,0 +1,168 @@
+package tracing_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/XSAM/otelsql"
+	"github.com/go-chi/chi/v5"
+	"github.com/jmoiron/sqlx"
+	stripe "github.com/stripe/stripe-go/v74"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"richisntreal-backend/internal/api/middleware"
+	"richisntreal-backend/internal/core/services"
+	httpclient "richisntreal-backend/internal/infrastructure/http"
+	"richisntreal-backend/internal/infrastructure/mysql"
+)
+
+// TestPaymentRequestIsOneTrace pays for an order through the real
+// middleware, service, MySQL repository and Stripe client, and checks that
+// every hop ended up in the same trace under the right parent.
+func TestPaymentRequestIsOneTrace(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	// Stripe, answering every charge; it records the trace header it got
+	var stripeTraceparent string
+	var mu sync.Mutex
+	stripeSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		stripeTraceparent = r.Header.Get("traceparent")
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"id":"ch_trace","object":"charge","status":"succeeded"}`)
+	}))
+	defer stripeSrv.Close()
+	stripe.SetBackend(stripe.APIBackend, stripe.GetBackendWithConfig(stripe.APIBackend, &stripe.BackendConfig{
+		HTTPClient:        httpclient.NewHTTPClient(),
+		URL:               stripe.String(stripeSrv.URL),
+		MaxNetworkRetries: stripe.Int64(0),
+		LeveledLogger:     &stripe.LeveledLogger{Level: stripe.LevelNull},
+	}))
+
+	// the database, instrumented the way mysql.NewMySQL does it
+	db, err := otelsql.Open(fakeDriverName, "", otelsql.WithAttributes(semconv.DBSystemMySQL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	paySvc := services.NewPaymentService(mysql.NewPaymentRepository(sqlx.NewDb(db, "mysql")), "sk_test_trace", noMetrics{})
+
+	r := chi.NewRouter()
+	r.Use(middleware.Tracing)
+	r.Post("/orders/{orderID}/pay", func(w http.ResponseWriter, r *http.Request) {
+		if _, err := paySvc.ProcessPayment(r.Context(), 1, 12.5, "USD", "stripe", "tok_visa"); err != nil {
+			t.Errorf("ProcessPayment: %v", err)
+		}
+		w.WriteHeader(http.StatusCreated)
+	})
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	resp, err := http.Post(srv.URL+"/orders/1/pay", "application/json", strings.NewReader("{}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	spans := rec.Ended()
+	find := func(match func(sdktrace.ReadOnlySpan) bool) sdktrace.ReadOnlySpan {
+		t.Helper()
+		for _, s := range spans {
+			if match(s) {
+				return s
+			}
+		}
+		var names []string
+		for _, s := range spans {
+			names = append(names, s.Name())
+		}
+		t.Fatalf("no such span among %v", names)
+		return nil
+	}
+
+	server := find(func(s sdktrace.ReadOnlySpan) bool { return s.Name() == "POST /orders/{orderID}/pay" })
+	service := find(func(s sdktrace.ReadOnlySpan) bool { return s.Name() == "PaymentService.ProcessPayment" })
+	query := find(func(s sdktrace.ReadOnlySpan) bool { return strings.HasPrefix(s.Name(), "sql.") })
+	charge := find(func(s sdktrace.ReadOnlySpan) bool {
+		return s.SpanKind() == trace.SpanKindClient && !strings.HasPrefix(s.Name(), "sql.")
+	})
+
+	traceID := server.SpanContext().TraceID()
+	for _, s := range []sdktrace.ReadOnlySpan{service, query, charge} {
+		if s.SpanContext().TraceID() != traceID {
+			t.Errorf("span %q is in trace %s, want %s", s.Name(), s.SpanContext().TraceID(), traceID)
+		}
+	}
+	if service.Parent().SpanID() != server.SpanContext().SpanID() {
+		t.Errorf("service span's parent is %s, want the server span", service.Parent().SpanID())
+	}
+	for _, s := range []sdktrace.ReadOnlySpan{query, charge} {
+		if s.Parent().SpanID() != service.SpanContext().SpanID() {
+			t.Errorf("span %q's parent is %s, want the service span", s.Name(), s.Parent().SpanID())
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if !strings.Contains(stripeTraceparent, traceID.String()) {
+		t.Errorf("Stripe got traceparent %q, want one in trace %s", stripeTraceparent, traceID)
+	}
+}
+
+type noMetrics struct{}
+
+func (noMetrics) PaymentSucceeded(string) {}
+func (noMetrics) PaymentFailed(string)    {}
+
+// fakeDriver accepts every statement; each insert gets id 1.
+type fakeDriver struct{}
+
+const fakeDriverName = "tracing-test"
+
+func init() {
+	sql.Register(fakeDriverName, fakeDriver{})
+}
+
+func (fakeDriver) Open(string) (driver.Conn, error) { return fakeConn{}, nil }
+
+type fakeConn struct{}
+
+func (fakeConn) Prepare(string) (driver.Stmt, error) { return fakeStmt{}, nil }
+func (fakeConn) Close() error                        { return nil }
+func (fakeConn) Begin() (driver.Tx, error)           { return fakeTx{}, nil }
+
+func (fakeConn) ExecContext(context.Context, string, []driver.NamedValue) (driver.Result, error) {
+	return fakeResult{}, nil
+}
+
+type fakeStmt struct{}
+
+func (fakeStmt) Close() error                               { return nil }
+func (fakeStmt) NumInput() int                              { return -1 }
+func (fakeStmt) Exec([]driver.Value) (driver.Result, error) { return fakeResult{}, nil }
+func (fakeStmt) Query([]driver.Value) (driver.Rows, error)  { return nil, driver.ErrSkip }
+
+type fakeResult struct{}
+
+func (fakeResult) LastInsertId() (int64, error) { return 1, nil }
+func (fakeResult) RowsAffected() (int64, error) { return 1, nil }
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error   { return nil }
+func (fakeTx) Rollback() error { return nil }